@@ -1,22 +1,34 @@
 package main
 
 import (
+	"bufio"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
 	"syscall"
+	"text/tabwriter"
 	"time"
 
+	"casino-backend/internal/analytics"
+	"casino-backend/internal/auth"
+	"casino-backend/internal/betting"
 	"casino-backend/internal/database"
 	"casino-backend/internal/handlers"
+	"casino-backend/internal/models"
+	"casino-backend/internal/simulation"
+	"casino-backend/internal/webhooks"
 	"casino-backend/pkg/websocket"
 
 	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
+	"golang.org/x/term"
 )
 
 func main() {
@@ -34,6 +46,12 @@ func main() {
 		log.Println("No .env file found, using default values")
 	}
 
+	// Room and admin tokens are signed with this key: a known one lets anyone forge them
+	jwtSecret, err := loadJWTSecret()
+	if err != nil {
+		log.Fatalf("Refusing to start: %v", err)
+	}
+
 	var repo database.RouletteRepositoryInterface
 	db, err := database.Connect()
 	if err != nil {
@@ -99,19 +117,42 @@ func main() {
 		}
 	}()
 
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		jwtSecret = "your-default-super-secret-key-for-dev" // НЕ ИСПОЛЬЗОВАТЬ В ПРОДЕ
-		log.Println("⚠️ JWT_SECRET not set, using default insecure key")
-	}
+	// Create the admin named by ADMIN_USERNAME and ADMIN_PASSWORD if it is missing,
+	// so that in-memory deployments can log in too
+	ensureEnvAdmin(repo)
+
+	// Notify analytics of every history write, whichever path it comes from
+	observedRepo := database.NewObservedRepository(repo)
+	repo = observedRepo
+
+	signatures := analytics.NewSignatureRegistry(repo)
+	observedRepo.AddObserver(signatures)
+
+	alertEngine := analytics.NewAlertEngine(repo)
+	observedRepo.AddObserver(alertEngine)
+
+	// Room tokens are checked against the revocation list on every use
+	roomTokens := auth.NewRoomTokens(repo, []byte(jwtSecret))
 
 	// Create WebSocket hub
-	wsHub := websocket.NewHub(repo, []byte(jwtSecret))
+	wsHub := websocket.NewHub(repo, roomTokens)
 	go wsHub.Run()
+	alertEngine.AddListener(wsHub)
+	roomTokens.AddListener(wsHub)
+
+	// Outgoing webhooks see the same writes and alerts as the hub
+	dispatcher := webhooks.NewDispatcher(repo, webhooks.Config{})
+	observedRepo.AddObserver(dispatcher)
+	alertEngine.AddListener(dispatcher)
+	dispatcher.Start()
 
 	// Create handlers
-	rouletteHandler := handlers.NewRouletteHandler(repo, jwtSecret)
-	adminHandler := handlers.NewAdminHandler(repo, wsHub)
+	rouletteHandler := handlers.NewRouletteHandler(repo, wsHub, roomTokens)
+	adminHandler := handlers.NewAdminHandler(repo, wsHub, roomTokens)
+	signatureHandler := handlers.NewSignatureHandler(signatures, repo, roomTokens)
+	alertHandler := handlers.NewAlertHandler(alertEngine, repo, roomTokens)
+	webhookHandler := handlers.NewWebhookHandler(repo, dispatcher)
+	adminAuthHandler := handlers.NewAdminAuthHandler(repo, []byte(jwtSecret))
 
 	// Setup routes
 	router := mux.NewRouter()
@@ -121,9 +162,16 @@ func main() {
 
 	// Register roulette routes
 	rouletteHandler.RegisterRoutes(api)
+	signatureHandler.RegisterRoutes(api)
+	alertHandler.RegisterRoutes(api)
+
+	// Admin login is matched by the API router, ahead of the guarded admin router
+	adminAuthHandler.RegisterRoutes(api)
 
 	// Admin API routes
-	adminHandler.RegisterAdminRoutes(router)
+	adminRouter := adminHandler.RegisterAdminRoutes(router)
+	webhookHandler.RegisterRoutes(adminRouter)
+	adminRouter.Use(adminAuthHandler.RequireAdmin)
 
 	// WebSocket route
 	router.HandleFunc("/ws", wsHub.HandleWebSocket)
@@ -203,6 +251,7 @@ func main() {
 	// Wait for interrupt signal
 	<-c
 	log.Println("Shutting down server...")
+	dispatcher.Stop()
 }
 
 // handleCLICommands processes command line arguments
@@ -223,6 +272,12 @@ func handleCLICommands() {
 		handleMigrationStatusCommand()
 	case "reset-migrations":
 		handleResetMigrationsCommand()
+	case "simulate":
+		handleSimulateCommand()
+	case "montecarlo":
+		handleMonteCarloCommand()
+	case "create-admin":
+		handleCreateAdminCommand()
 	case "help", "--help", "-h":
 		printHelp()
 	default:
@@ -384,6 +439,275 @@ func handleMigrationStatusCommand() {
 	}
 }
 
+// handleSimulateCommand replays a stored room history through betting strategies
+func handleSimulateCommand() {
+	if len(os.Args) < 3 || strings.HasPrefix(os.Args[2], "-") {
+		fmt.Println("Usage: casino-backend simulate <key> [-strategy name] [-bankroll N] [-unit N] [-min N] [-max N] [-bet type] [-target N] [-window N] [-json]")
+		os.Exit(1)
+	}
+	key := os.Args[2]
+
+	flags := flag.NewFlagSet("simulate", flag.ExitOnError)
+	strategy := flags.String("strategy", "", "Strategy to play (default: every strategy)")
+	table := addTableFlags(flags)
+	window := flags.Int("window", 0, "Replay only the last N spins (0 for the whole history)")
+	asJSON := flags.Bool("json", false, "Print the full results, equity curves included, as JSON")
+	flags.Parse(os.Args[3:])
+
+	session := loadCLISession(key)
+
+	names := simulation.Names()
+	if *strategy != "" {
+		names = []string{*strategy}
+	}
+	specs := make([]simulation.Spec, 0, len(names))
+	for _, name := range names {
+		specs = append(specs, simulation.Spec{Name: name, Params: table.params()})
+	}
+
+	results, err := simulation.Backtest(specs, table.config(session.WheelType), analytics.LastN(session.History, *window))
+	if err != nil {
+		log.Fatalf("Simulation failed: %v", err)
+	}
+
+	if *asJSON {
+		printJSON(results)
+		return
+	}
+
+	fmt.Printf("Session %s: %d spins, %s wheel\n\n", session.Key, len(session.History), session.WheelType.OrDefault())
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(writer, "Strategy\tSpins\tBets\tWins\tFinal\tNet\tPeak\tMax DD\tROI\tBust\t")
+	for _, result := range results {
+		bust := "-"
+		if result.Busted {
+			bust = strconv.Itoa(result.BustSpin)
+		}
+		summary := result.Summary
+		fmt.Fprintf(writer, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%.2f%%\t%s\t\n",
+			result.Strategy, summary.Spins, summary.Bets, summary.Wins, summary.FinalBalance,
+			summary.Net, summary.Peak, summary.MaxDrawdown, summary.ROI*100, bust)
+	}
+	writer.Flush()
+}
+
+// handleMonteCarloCommand plays a strategy through simulated sessions on a fair wheel
+func handleMonteCarloCommand() {
+	flags := flag.NewFlagSet("montecarlo", flag.ExitOnError)
+	strategy := flags.String("strategy", simulation.StrategyFlat, "Strategy to play")
+	wheelName := flags.String("wheel", string(models.DefaultWheel), "Wheel type: european, american or triple_zero")
+	iterations := flags.Int("iterations", 1000, "Number of simulated sessions")
+	spins := flags.Int("spins", 100, "Spins per session")
+	seed := flags.Int64("seed", 0, "Random seed (0 picks one from the clock)")
+	table := addTableFlags(flags)
+	key := flags.String("key", "", "Compare with the strategy played over this room's history")
+	asJSON := flags.Bool("json", false, "Print the full result as JSON")
+	flags.Parse(os.Args[2:])
+
+	wheelType, err := models.ParseWheelType(*wheelName)
+	if err != nil {
+		log.Fatalf("Invalid wheel: %v", err)
+	}
+
+	var session *models.RouletteSession
+	if *key != "" {
+		session = loadCLISession(*key)
+		wheelType = session.WheelType
+	}
+
+	spec := simulation.Spec{Name: *strategy, Params: table.params()}
+	result, err := simulation.MonteCarlo(spec, simulation.MonteCarloConfig{
+		Config:     table.config(wheelType),
+		Iterations: *iterations,
+		Spins:      *spins,
+		Seed:       *seed,
+	})
+	if err != nil {
+		log.Fatalf("Simulation failed: %v", err)
+	}
+
+	if *asJSON {
+		printJSON(result)
+		return
+	}
+
+	distribution := result.FinalBankroll
+	fmt.Printf("%s on a fair %s wheel: %d sessions of %d spins, seed %d\n\n",
+		result.Strategy, result.Config.Wheel, result.Config.Iterations, result.Config.Spins, result.Config.Seed)
+	fmt.Printf("Starting bankroll:  %d\n", result.Config.Bankroll)
+	fmt.Printf("Final bankroll:     mean %.1f, median %d, 5%%-95%% %d..%d, range %d..%d\n",
+		distribution.Mean, distribution.Median, distribution.P5, distribution.P95, distribution.Min, distribution.Max)
+	fmt.Printf("Ruin probability:   %.2f%% (%d sessions)\n", result.RuinProbability*100, result.Ruined)
+	fmt.Printf("Expected loss:      %.2f per session\n", result.ExpectedLoss)
+	fmt.Printf("Edge:               %.2f%% observed, %.2f%% theoretical\n", result.ObservedEdge*100, result.HouseEdge*100)
+
+	if session != nil {
+		actual, err := simulation.Backtest([]simulation.Spec{spec}, result.Config.Config, session.History)
+		if err != nil {
+			log.Fatalf("Backtest failed: %v", err)
+		}
+		final := actual[0].Summary.FinalBalance
+		fmt.Printf("\nRoom %s: final bankroll %d after %d spins, better than %.1f%% of fair sessions\n",
+			session.Key, final, actual[0].Summary.Spins, (1-result.PercentileOf(final))*100)
+	}
+}
+
+// handleCreateAdminCommand adds an admin account to the database. The password
+// is read from ADMIN_PASSWORD or from standard input, never from the arguments.
+func handleCreateAdminCommand() {
+	if len(os.Args) < 3 {
+		fmt.Println("Usage: casino-backend create-admin <username>")
+		os.Exit(1)
+	}
+	username := os.Args[2]
+
+	password := os.Getenv("ADMIN_PASSWORD")
+	if password == "" {
+		var err error
+		if password, err = readPassword(fmt.Sprintf("Password for %s: ", username)); err != nil {
+			log.Fatalf("Failed to read password: %v", err)
+		}
+	}
+
+	admin, err := auth.NewAdmin(username, password)
+	if err != nil {
+		log.Fatalf("Failed to create admin: %v", err)
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	// The admins table may not exist before the server has run once
+	if err := db.RunMigrations(); err != nil {
+		log.Fatalf("Failed to run migrations: %v", err)
+	}
+
+	created, err := database.NewRouletteRepository(db).CreateAdmin(admin)
+	if err != nil {
+		log.Fatalf("Failed to create admin: %v", err)
+	}
+	fmt.Printf("✅ Admin %s created (id %d)\n", created.Username, created.ID)
+}
+
+// readPassword reads a password from a terminal without echoing it, or the
+// first line of stdin when it is piped
+func readPassword(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", err
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	fmt.Print(prompt)
+	password, err := term.ReadPassword(fd)
+	fmt.Println()
+	return string(password), err
+}
+
+// publicJWTSecrets are example values from the docs that must not sign real tokens
+var publicJWTSecrets = []string{
+	"your-default-super-secret-key-for-dev",
+	"your_jwt_secret_key_here",
+	"change-me",
+}
+
+// loadJWTSecret reads JWT_SECRET, refusing missing and well-known values
+func loadJWTSecret() (string, error) {
+	secret := os.Getenv("JWT_SECRET")
+	if secret == "" {
+		return "", fmt.Errorf("JWT_SECRET is not set")
+	}
+	for _, public := range publicJWTSecrets {
+		if secret == public {
+			return "", fmt.Errorf("JWT_SECRET is the example value %q, set a random one", public)
+		}
+	}
+	return secret, nil
+}
+
+// ensureEnvAdmin creates the admin account given by ADMIN_USERNAME and ADMIN_PASSWORD
+func ensureEnvAdmin(repo database.RouletteRepositoryInterface) {
+	username, password := os.Getenv("ADMIN_USERNAME"), os.Getenv("ADMIN_PASSWORD")
+	if username == "" || password == "" {
+		return
+	}
+
+	admin, err := auth.NewAdmin(username, password)
+	if err != nil {
+		log.Printf("⚠️ Admin from ADMIN_USERNAME not created: %v", err)
+		return
+	}
+	if _, err := repo.CreateAdmin(admin); err != nil {
+		if !errors.Is(err, database.ErrAdminExists) {
+			log.Printf("⚠️ Admin from ADMIN_USERNAME not created: %v", err)
+		}
+		return
+	}
+	log.Printf("Admin %s created from ADMIN_USERNAME", username)
+}
+
+// tableFlags are the bankroll, limit and bet flags shared by the simulation commands
+type tableFlags struct {
+	bankroll *int64
+	unit     *int64
+	minBet   *int64
+	maxBet   *int64
+	betType  *string
+	target   *int
+}
+
+func addTableFlags(flags *flag.FlagSet) *tableFlags {
+	return &tableFlags{
+		bankroll: flags.Int64("bankroll", 1000, "Starting bankroll in chips"),
+		unit:     flags.Int64("unit", 1, "Base stake in chips"),
+		minBet:   flags.Int64("min", 1, "Table minimum"),
+		maxBet:   flags.Int64("max", 0, "Table maximum (0 for no limit)"),
+		betType:  flags.String("bet", "", "Bet the progressions play (default: red)"),
+		target:   flags.Int("target", 0, "Dozen or column of the bet"),
+	}
+}
+
+func (f *tableFlags) config(wheelType models.WheelType) simulation.Config {
+	return simulation.Config{Wheel: wheelType, Bankroll: *f.bankroll, MinBet: *f.minBet, MaxBet: *f.maxBet}
+}
+
+func (f *tableFlags) params() simulation.Params {
+	return simulation.Params{Unit: *f.unit, Bet: betting.Bet{Type: betting.BetType(*f.betType), Target: *f.target}}
+}
+
+// loadCLISession reads a room from the database or exits
+func loadCLISession(key string) *models.RouletteSession {
+	db, err := database.Connect()
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	session, err := database.NewRouletteRepository(db).GetSession(key)
+	if err != nil {
+		log.Fatalf("Failed to load session: %v", err)
+	}
+	if session == nil {
+		log.Fatalf("Session with key '%s' not found", key)
+	}
+	return session
+}
+
+// printJSON writes indented JSON to stdout or exits
+func printJSON(value interface{}) {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		log.Fatalf("Failed to encode results: %v", err)
+	}
+}
+
 // printHelp displays usage information
 func printHelp() {
 	fmt.Printf("Casino Backend Server\n")
@@ -394,6 +718,9 @@ func printHelp() {
 	fmt.Printf("  casino-backend rollback <steps>   Rollback N migrations\n")
 	fmt.Printf("  casino-backend migration-status   Show migration status\n")
 	fmt.Printf("  casino-backend reset-migrations   Reset all migrations (DANGER!)\n")
+	fmt.Printf("  casino-backend simulate <key>     Backtest betting strategies on a room history\n")
+	fmt.Printf("  casino-backend montecarlo         Simulate a strategy on a fair wheel\n")
+	fmt.Printf("  casino-backend create-admin <username>  Create an admin account\n")
 	fmt.Printf("  casino-backend help               Show this help\n\n")
 	fmt.Printf("Environment Variables:\n")
 	fmt.Printf("  DB_HOST        Database host (default: localhost)\n")
@@ -402,7 +729,10 @@ func printHelp() {
 	fmt.Printf("  DB_PASSWORD    Database password (default: casino_password)\n")
 	fmt.Printf("  DB_NAME        Database name (default: casino_db)\n")
 	fmt.Printf("  DB_SSL_MODE    SSL mode (default: disable)\n")
-	fmt.Printf("  PORT           Server port (default: 8080)\n\n")
+	fmt.Printf("  PORT           Server port (default: 8080)\n")
+	fmt.Printf("  JWT_SECRET     Key signing room and admin tokens\n")
+	fmt.Printf("  ADMIN_USERNAME Admin created at startup if missing, with ADMIN_PASSWORD\n")
+	fmt.Printf("  ADMIN_PASSWORD Password of that admin, also read by create-admin\n\n")
 	fmt.Printf("API Endpoints:\n")
 	fmt.Printf("  GET  /health                      Health check\n")
 	fmt.Printf("  GET  /api/migrations/status       Migration status\n")
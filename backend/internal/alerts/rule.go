@@ -0,0 +1,111 @@
+package alerts
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"casino-backend/internal/models"
+	"casino-backend/internal/wheel"
+)
+
+// ErrInvalidRule is returned for rules that cannot be evaluated
+var ErrInvalidRule = errors.New("invalid alert rule")
+
+// Kind is the pattern a rule watches for
+type Kind string
+
+const (
+	KindStreak Kind = "streak" // The target came up Threshold times in a row
+	KindAbsent Kind = "absent" // The target has not come up for Threshold spins
+)
+
+// Limits of a rule
+const (
+	MaxThreshold    = 10000
+	MaxNameLength   = 100
+	MaxRulesPerRoom = 50
+)
+
+// Rule is a pattern of a room's history that fires an alert when it starts to hold.
+// The target is either a single pocket or a table group named as in the stats report.
+type Rule struct {
+	ID         int64                  `json:"id"`
+	SessionKey string                 `json:"sessionKey"`
+	Kind       Kind                   `json:"kind"`
+	Number     *models.RouletteNumber `json:"number,omitempty"`
+	Category   string                 `json:"category,omitempty"` // Group category: dozen, column, color, parity, half
+	Group      string                 `json:"group,omitempty"`    // Group name, e.g. "red" or "13-24"
+	Threshold  int                    `json:"threshold"`
+	Name       string                 `json:"name,omitempty"` // Shown with the alert instead of the generated description
+	CreatedAt  time.Time              `json:"createdAt"`
+}
+
+// Alert is sent when a rule starts to hold after a spin
+type Alert struct {
+	Rule       *Rule                 `json:"rule"`
+	SessionKey string                `json:"sessionKey"`
+	Value      int                   `json:"value"`     // Current streak or absence
+	SpinIndex  int                   `json:"spinIndex"` // Index of the spin that fired the rule
+	Number     models.RouletteNumber `json:"number"`
+	Message    string                `json:"message"`
+	FiredAt    time.Time             `json:"firedAt"`
+}
+
+// Validate checks a rule against the wheel of its room
+func (r *Rule) Validate(wheelType models.WheelType) error {
+	switch r.Kind {
+	case KindStreak, KindAbsent:
+	default:
+		return fmt.Errorf("%w: unknown kind %q", ErrInvalidRule, r.Kind)
+	}
+	if r.Threshold < 1 || r.Threshold > MaxThreshold {
+		return fmt.Errorf("%w: threshold must be between 1 and %d", ErrInvalidRule, MaxThreshold)
+	}
+	if len(r.Name) > MaxNameLength {
+		return fmt.Errorf("%w: name is longer than %d characters", ErrInvalidRule, MaxNameLength)
+	}
+	if _, err := r.Members(wheelType); err != nil {
+		return err
+	}
+	return nil
+}
+
+// target names the rule's pocket or group
+func (r *Rule) target() string {
+	if r.Number != nil {
+		return r.Number.String()
+	}
+	if r.Category == wheel.CategoryDozen {
+		return "dozen " + r.Group
+	}
+	return r.Group
+}
+
+// Describe says what the rule saw, for alerts of unnamed rules
+func (r *Rule) Describe(value int) string {
+	if r.Kind == KindStreak {
+		return fmt.Sprintf("%s came up %d times in a row", r.target(), value)
+	}
+	return fmt.Sprintf("%s has not come up for %d spins", r.target(), value)
+}
+
+// Members returns the pockets of the rule's target
+func (r *Rule) Members(wheelType models.WheelType) ([]models.RouletteNumber, error) {
+	if r.Number != nil {
+		if r.Category != "" || r.Group != "" {
+			return nil, fmt.Errorf("%w: set either a number or a group", ErrInvalidRule)
+		}
+		if err := models.ValidateNumber(wheelType, *r.Number); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRule, err)
+		}
+		return []models.RouletteNumber{*r.Number}, nil
+	}
+
+	for _, group := range wheel.GroupsByCategory(r.Category) {
+		if group.Name == r.Group {
+			return group.Numbers, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: unknown group %q of category %q", ErrInvalidRule, r.Group, r.Category)
+}
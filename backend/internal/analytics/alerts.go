@@ -0,0 +1,191 @@
+package analytics
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"casino-backend/internal/alerts"
+	"casino-backend/internal/database"
+	"casino-backend/internal/models"
+)
+
+// AlertListener receives every alert fired by an AlertEngine
+type AlertListener interface {
+	AlertFired(alert *alerts.Alert)
+}
+
+// RuleValue returns the current streak or absence of a rule's target
+func RuleValue(rule *alerts.Rule, wheelType models.WheelType, history []models.RouletteNumber) (int, error) {
+	members, err := rule.Members(wheelType)
+	if err != nil {
+		return 0, err
+	}
+	if rule.Kind == alerts.KindStreak {
+		return GroupStreak(history, members), nil
+	}
+	return GroupAge(history, members), nil
+}
+
+// EvaluateRule returns an alert if the rule holds after the last spin of the
+// history but did not hold before it, so a pattern fires once per occurrence
+func EvaluateRule(rule *alerts.Rule, wheelType models.WheelType, history []models.RouletteNumber) (*alerts.Alert, error) {
+	if len(history) == 0 {
+		return nil, nil
+	}
+	value, err := RuleValue(rule, wheelType, history)
+	if err != nil || value < rule.Threshold {
+		return nil, err
+	}
+	previous, err := RuleValue(rule, wheelType, history[:len(history)-1])
+	if err != nil || previous >= rule.Threshold {
+		return nil, err
+	}
+
+	message := rule.Name
+	if message == "" {
+		message = rule.Describe(value)
+	}
+	return &alerts.Alert{
+		Rule:       rule,
+		SessionKey: rule.SessionKey,
+		Value:      value,
+		SpinIndex:  len(history) - 1,
+		Number:     history[len(history)-1],
+		Message:    message,
+		FiredAt:    time.Now(),
+	}, nil
+}
+
+// AlertEngine evaluates the rules of a room after every added number. Rules
+// are cached per room and reloaded after they change.
+type AlertEngine struct {
+	repo database.RouletteRepositoryInterface
+
+	mu        sync.Mutex
+	rules     map[string][]*alerts.Rule
+	listeners []AlertListener
+}
+
+// NewAlertEngine creates an engine reading rules and sessions from repo
+func NewAlertEngine(repo database.RouletteRepositoryInterface) *AlertEngine {
+	return &AlertEngine{
+		repo:  repo,
+		rules: make(map[string][]*alerts.Rule),
+	}
+}
+
+// AddListener registers a listener for all subsequent alerts
+func (e *AlertEngine) AddListener(listener AlertListener) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.listeners = append(e.listeners, listener)
+}
+
+// CreateRule validates and stores a rule. It returns nil if the room does not exist.
+func (e *AlertEngine) CreateRule(rule *alerts.Rule) (*alerts.Rule, error) {
+	session, err := e.repo.GetSession(rule.SessionKey)
+	if err != nil || session == nil {
+		return nil, err
+	}
+	if err := rule.Validate(session.WheelType); err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	rules, err := e.roomRules(rule.SessionKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) >= alerts.MaxRulesPerRoom {
+		return nil, fmt.Errorf("%w: a room can have at most %d rules", alerts.ErrInvalidRule, alerts.MaxRulesPerRoom)
+	}
+
+	created, err := e.repo.CreateAlertRule(rule)
+	if err != nil {
+		return nil, err
+	}
+	delete(e.rules, rule.SessionKey)
+	return created, nil
+}
+
+// Rules returns the rules of a room. It returns nil if the room does not
+// exist, so that unknown keys are never cached.
+func (e *AlertEngine) Rules(key string) ([]*alerts.Rule, error) {
+	session, err := e.repo.GetSession(key)
+	if err != nil || session == nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.roomRules(key)
+}
+
+// DeleteRule removes a rule of a room, reporting whether it existed
+func (e *AlertEngine) DeleteRule(key string, id int64) (bool, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	deleted, err := e.repo.DeleteAlertRule(key, id)
+	if err != nil {
+		return false, err
+	}
+	delete(e.rules, key)
+	return deleted, nil
+}
+
+// NumberAdded implements database.HistoryObserver
+func (e *AlertEngine) NumberAdded(session *models.RouletteSession, number models.RouletteNumber) {
+	e.mu.Lock()
+	rules, err := e.roomRules(session.Key)
+	listeners := append([]AlertListener(nil), e.listeners...)
+	e.mu.Unlock()
+	if err != nil {
+		log.Printf("[ALERTS] Failed to load rules of session %s: %v", session.Key, err)
+		return
+	}
+
+	for _, rule := range rules {
+		alert, err := EvaluateRule(rule, session.WheelType, session.History)
+		if err != nil {
+			log.Printf("[ALERTS] Failed to evaluate rule %d of session %s: %v", rule.ID, session.Key, err)
+			continue
+		}
+		if alert == nil {
+			continue
+		}
+		for _, listener := range listeners {
+			listener.AlertFired(alert)
+		}
+	}
+}
+
+// NumberRemoved implements database.HistoryObserver; edits never fire alerts
+func (e *AlertEngine) NumberRemoved(session *models.RouletteSession, index int) {}
+
+// HistoryReplaced implements database.HistoryObserver; edits never fire alerts
+func (e *AlertEngine) HistoryReplaced(session *models.RouletteSession) {}
+
+// SessionDeleted implements database.SessionDeleteObserver
+func (e *AlertEngine) SessionDeleted(key string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.rules, key)
+}
+
+// roomRules returns the cached rules of a room; the caller must hold e.mu
+func (e *AlertEngine) roomRules(key string) ([]*alerts.Rule, error) {
+	if rules, ok := e.rules[key]; ok {
+		return rules, nil
+	}
+	rules, err := e.repo.GetAlertRules(key)
+	if err != nil {
+		return nil, err
+	}
+	e.rules[key] = rules
+	return rules, nil
+}
@@ -0,0 +1,137 @@
+package analytics
+
+import (
+	"errors"
+	"testing"
+
+	"casino-backend/internal/alerts"
+	"casino-backend/internal/database"
+	"casino-backend/internal/models"
+)
+
+func TestEvaluateRule(t *testing.T) {
+	seventeen := models.RouletteNumber(17)
+	doubleZero := models.DoubleZero
+	redStreak := &alerts.Rule{Kind: alerts.KindStreak, Category: "color", Group: "red", Threshold: 3}
+	absent17 := &alerts.Rule{Kind: alerts.KindAbsent, Number: &seventeen, Threshold: 4}
+	absentDozen := &alerts.Rule{Kind: alerts.KindAbsent, Category: "dozen", Group: "13-24", Threshold: 2}
+
+	tests := []struct {
+		name    string
+		rule    *alerts.Rule
+		history []models.RouletteNumber
+		want    int // Value of the fired alert, 0 when nothing fires
+		message string
+	}{
+		{name: "streak reached", rule: redStreak, history: []models.RouletteNumber{2, 1, 3, 5}, want: 3, message: "red came up 3 times in a row"},
+		{name: "streak already held", rule: redStreak, history: []models.RouletteNumber{1, 3, 5, 7}},
+		{name: "streak broken", rule: redStreak, history: []models.RouletteNumber{1, 3, 2}},
+		{name: "number absent", rule: absent17, history: []models.RouletteNumber{17, 1, 2, 3, 4}, want: 4, message: "17 has not come up for 4 spins"},
+		{name: "number never seen", rule: absent17, history: []models.RouletteNumber{1, 2, 3, 4}, want: 4},
+		{name: "number seen", rule: absent17, history: []models.RouletteNumber{1, 2, 3, 17}},
+		{name: "dozen absent", rule: absentDozen, history: []models.RouletteNumber{14, 0, 36}, want: 2, message: "dozen 13-24 has not come up for 2 spins"},
+		{name: "empty history", rule: absentDozen},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alert, err := EvaluateRule(tt.rule, models.WheelEuropean, tt.history)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.want == 0 {
+				if alert != nil {
+					t.Errorf("unexpected alert %+v", alert)
+				}
+				return
+			}
+			if alert == nil {
+				t.Fatal("expected an alert")
+			}
+			if alert.Value != tt.want || alert.SpinIndex != len(tt.history)-1 || (tt.message != "" && alert.Message != tt.message) {
+				t.Errorf("got %+v, want value %d and message %q", alert, tt.want, tt.message)
+			}
+		})
+	}
+
+	invalid := &alerts.Rule{Kind: alerts.KindAbsent, Number: &doubleZero, Threshold: 5}
+	if err := invalid.Validate(models.WheelEuropean); !errors.Is(err, alerts.ErrInvalidRule) {
+		t.Errorf("expected ErrInvalidRule for 00 on a European wheel, got %v", err)
+	}
+}
+
+type recordedAlerts []*alerts.Alert
+
+func (r *recordedAlerts) AlertFired(alert *alerts.Alert) {
+	*r = append(*r, alert)
+}
+
+func TestAlertEngineFiresOnAddedNumbers(t *testing.T) {
+	repo := database.NewObservedRepository(database.NewMemoryRepository())
+	engine := NewAlertEngine(repo)
+	repo.AddObserver(engine)
+	var fired recordedAlerts
+	engine.AddListener(&fired)
+
+	if _, err := repo.CreateSession("room"); err != nil {
+		t.Fatal(err)
+	}
+	if rule, err := engine.CreateRule(&alerts.Rule{SessionKey: "missing", Kind: alerts.KindStreak, Category: "color", Group: "red", Threshold: 2}); rule != nil || err != nil {
+		t.Errorf("expected no rule for a missing room, got %+v, %v", rule, err)
+	}
+	rule, err := engine.CreateRule(&alerts.Rule{SessionKey: "room", Kind: alerts.KindStreak, Category: "color", Group: "red", Threshold: 2, Name: "two reds"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, n := range []models.RouletteNumber{1, 3, 5, 2, 7, 9} {
+		if _, err := repo.AddNumberToSession("room", n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(fired) != 2 || fired[0].SpinIndex != 1 || fired[1].SpinIndex != 5 || fired[0].Message != "two reds" {
+		t.Fatalf("unexpected alerts %+v", fired)
+	}
+
+	if deleted, err := engine.DeleteRule("room", rule.ID); !deleted || err != nil {
+		t.Fatalf("failed to delete rule: %v", err)
+	}
+	if _, err := repo.AddNumberToSession("room", 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.AddNumberToSession("room", 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.AddNumberToSession("room", 3); err != nil {
+		t.Fatal(err)
+	}
+	if len(fired) != 2 {
+		t.Errorf("deleted rule still fires: %+v", fired[2:])
+	}
+}
+
+func TestAlertEngineCachesExistingRoomsOnly(t *testing.T) {
+	repo := database.NewObservedRepository(database.NewMemoryRepository())
+	engine := NewAlertEngine(repo)
+	repo.AddObserver(engine)
+
+	if rules, err := engine.Rules("missing"); rules != nil || err != nil {
+		t.Errorf("expected no rules for a missing room, got %+v, %v", rules, err)
+	}
+	if _, err := repo.CreateSession("room"); err != nil {
+		t.Fatal(err)
+	}
+	if rules, err := engine.Rules("room"); rules == nil || len(rules) != 0 || err != nil {
+		t.Errorf("expected an empty rule list, got %+v, %v", rules, err)
+	}
+	if len(engine.rules) != 1 {
+		t.Errorf("expected only the existing room to be cached, got %d entries", len(engine.rules))
+	}
+
+	if err := repo.DeleteSession("room"); err != nil {
+		t.Fatal(err)
+	}
+	if len(engine.rules) != 0 {
+		t.Errorf("rules of a deleted room are still cached")
+	}
+}
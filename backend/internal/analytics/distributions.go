@@ -0,0 +1,74 @@
+package analytics
+
+import "math"
+
+// chiSquarePValue returns P(X >= x) for a chi-square distribution with df degrees of freedom
+func chiSquarePValue(x float64, df int) float64 {
+	if df <= 0 || x <= 0 {
+		return 1
+	}
+	return upperIncompleteGamma(float64(df)/2, x/2)
+}
+
+// normalPValue returns the two-sided p-value of a standard normal z-score
+func normalPValue(z float64) float64 {
+	return math.Erfc(math.Abs(z) / math.Sqrt2)
+}
+
+// upperIncompleteGamma returns the regularized upper incomplete gamma function Q(a, x)
+func upperIncompleteGamma(a, x float64) float64 {
+	if x < a+1 {
+		return 1 - lowerGammaSeries(a, x)
+	}
+	return upperGammaContinuedFraction(a, x)
+}
+
+const (
+	gammaMaxIterations = 500
+	gammaEpsilon       = 1e-14
+)
+
+// lowerGammaSeries evaluates P(a, x) by its series expansion, accurate for x < a+1
+func lowerGammaSeries(a, x float64) float64 {
+	lgamma, _ := math.Lgamma(a)
+	sum := 1 / a
+	term := sum
+	for n := 1; n < gammaMaxIterations; n++ {
+		term *= x / (a + float64(n))
+		sum += term
+		if math.Abs(term) < math.Abs(sum)*gammaEpsilon {
+			break
+		}
+	}
+	return sum * math.Exp(-x+a*math.Log(x)-lgamma)
+}
+
+// upperGammaContinuedFraction evaluates Q(a, x) by Lentz's continued fraction, accurate for x >= a+1
+func upperGammaContinuedFraction(a, x float64) float64 {
+	const tiny = 1e-300
+	lgamma, _ := math.Lgamma(a)
+
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for i := 1; i < gammaMaxIterations; i++ {
+		an := -float64(i) * (float64(i) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < gammaEpsilon {
+			break
+		}
+	}
+	return math.Exp(-x+a*math.Log(x)-lgamma) * h
+}
@@ -0,0 +1,268 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+
+	"casino-backend/internal/models"
+	"casino-backend/internal/wheel"
+)
+
+// Verdict summarises the fairness tests of a wheel
+type Verdict string
+
+const (
+	VerdictInsufficientData Verdict = "insufficient_data"
+	VerdictFair             Verdict = "fair"
+	VerdictSuspicious       Verdict = "suspicious"
+	VerdictBiased           Verdict = "biased"
+)
+
+// Significance levels applied to the Bonferroni-adjusted minimum p-value
+const (
+	suspiciousLevel = 0.05
+	biasedLevel     = 0.001
+)
+
+// Minimum sample sizes below which a test is skipped
+const (
+	minExpectedPerCell = 5
+	minSequenceLength  = 20
+	maxWheelSectors    = 12
+)
+
+// TestResult is the outcome of a single statistical test
+type TestResult struct {
+	Name             string  `json:"name"`
+	Statistic        float64 `json:"statistic"`
+	DegreesOfFreedom int     `json:"degreesOfFreedom,omitempty"`
+	PValue           float64 `json:"pValue"`
+	SampleSize       int     `json:"sampleSize"`
+	Skipped          bool    `json:"skipped,omitempty"`
+	Note             string  `json:"note,omitempty"`
+}
+
+// FairnessReport is the result of the randomness test suite for a room
+type FairnessReport struct {
+	WheelType         models.WheelType `json:"wheelType"`
+	TotalSpins        int              `json:"totalSpins"`
+	Tests             []TestResult     `json:"tests"`
+	MinAdjustedPValue float64          `json:"minAdjustedPValue"` // Bonferroni-adjusted over the tests that ran
+	Verdict           Verdict          `json:"verdict"`
+}
+
+// AnalyzeFairness runs chi-square goodness-of-fit over pockets and wheel
+// sectors, runs tests over colour and parity sequences and a lag-1 serial
+// correlation test, and combines them into a verdict.
+func AnalyzeFairness(wheelType models.WheelType, history []models.RouletteNumber) (*FairnessReport, error) {
+	wheelType = wheelType.OrDefault()
+	layout, err := wheel.ForType(wheelType)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &FairnessReport{
+		WheelType:  wheelType,
+		TotalSpins: len(history),
+		Tests: []TestResult{
+			pocketChiSquare(wheelType, history),
+			sectorChiSquare(layout, history),
+			runsTest("Colour runs", history, func(n models.RouletteNumber) (bool, bool) {
+				color := wheel.ColorOf(n)
+				return color == wheel.Red, color != wheel.Green
+			}),
+			runsTest("Parity runs", history, func(n models.RouletteNumber) (bool, bool) {
+				return n%2 == 0, !n.IsZero()
+			}),
+			serialCorrelation(history),
+		},
+		MinAdjustedPValue: 1,
+	}
+
+	ran := 0
+	minPValue := 1.0
+	for _, test := range report.Tests {
+		if test.Skipped {
+			continue
+		}
+		ran++
+		minPValue = math.Min(minPValue, test.PValue)
+	}
+
+	if ran == 0 {
+		report.Verdict = VerdictInsufficientData
+		return report, nil
+	}
+
+	report.MinAdjustedPValue = math.Min(1, minPValue*float64(ran))
+	switch {
+	case report.MinAdjustedPValue < biasedLevel:
+		report.Verdict = VerdictBiased
+	case report.MinAdjustedPValue < suspiciousLevel:
+		report.Verdict = VerdictSuspicious
+	default:
+		report.Verdict = VerdictFair
+	}
+
+	return report, nil
+}
+
+// pocketChiSquare tests that every pocket comes up with equal probability
+func pocketChiSquare(wheelType models.WheelType, history []models.RouletteNumber) TestResult {
+	pockets := wheelType.Pockets()
+	result := TestResult{Name: "Pocket chi-square", SampleSize: len(history), DegreesOfFreedom: len(pockets) - 1}
+
+	required := minExpectedPerCell * len(pockets)
+	if len(history) < required {
+		return skipped(result, fmt.Sprintf("needs at least %d spins", required))
+	}
+
+	counts := Counts(history)
+	observed := make([]float64, len(pockets))
+	expected := make([]float64, len(pockets))
+	for i, pocket := range pockets {
+		observed[i] = float64(counts[pocket])
+		expected[i] = float64(len(history)) / float64(len(pockets))
+	}
+
+	result.Statistic = chiSquareStatistic(observed, expected)
+	result.PValue = chiSquarePValue(result.Statistic, result.DegreesOfFreedom)
+	return result
+}
+
+// sectorChiSquare tests that contiguous arcs of the wheel come up in proportion
+// to their size, which catches tilted wheels and dealer signature that spread
+// over neighbouring pockets.
+func sectorChiSquare(layout *wheel.Layout, history []models.RouletteNumber) TestResult {
+	result := TestResult{Name: "Wheel sector chi-square", SampleSize: len(history)}
+
+	sectorCount := len(history) / minExpectedPerCell
+	if sectorCount > maxWheelSectors {
+		sectorCount = maxWheelSectors
+	}
+	if sectorCount < 2 {
+		return skipped(result, fmt.Sprintf("needs at least %d spins", 2*minExpectedPerCell))
+	}
+
+	sectors, err := layout.Arcs(sectorCount)
+	if err != nil {
+		return skipped(result, err.Error())
+	}
+
+	counts := Counts(history)
+	observed := make([]float64, len(sectors))
+	expected := make([]float64, len(sectors))
+	for i, sector := range sectors {
+		for _, n := range sector.Numbers {
+			observed[i] += float64(counts[n])
+		}
+		expected[i] = float64(len(history)) * float64(len(sector.Numbers)) / float64(layout.Size())
+	}
+
+	result.DegreesOfFreedom = len(sectors) - 1
+	result.Statistic = chiSquareStatistic(observed, expected)
+	result.PValue = chiSquarePValue(result.Statistic, result.DegreesOfFreedom)
+	result.Note = fmt.Sprintf("%d sectors in wheel order", len(sectors))
+	return result
+}
+
+// runsTest is the Wald-Wolfowitz runs test over a binary sequence. classify
+// returns the side of a number and whether it takes part in the sequence
+// (zeros are excluded from colour and parity sequences).
+func runsTest(name string, history []models.RouletteNumber, classify func(models.RouletteNumber) (bool, bool)) TestResult {
+	result := TestResult{Name: name}
+
+	var sequence []bool
+	for _, n := range history {
+		if side, ok := classify(n); ok {
+			sequence = append(sequence, side)
+		}
+	}
+	result.SampleSize = len(sequence)
+
+	if len(sequence) < minSequenceLength {
+		return skipped(result, fmt.Sprintf("needs at least %d non-zero spins", minSequenceLength))
+	}
+
+	n1, n2 := 0.0, 0.0
+	runs := 1.0
+	for i, side := range sequence {
+		if side {
+			n1++
+		} else {
+			n2++
+		}
+		if i > 0 && side != sequence[i-1] {
+			runs++
+		}
+	}
+	if n1 == 0 || n2 == 0 {
+		return skipped(result, "sequence has a single category")
+	}
+
+	n := n1 + n2
+	mean := 2*n1*n2/n + 1
+	variance := 2 * n1 * n2 * (2*n1*n2 - n) / (n * n * (n - 1))
+	if variance <= 0 {
+		return skipped(result, "zero variance")
+	}
+
+	result.Statistic = (runs - mean) / math.Sqrt(variance)
+	result.PValue = normalPValue(result.Statistic)
+	result.Note = fmt.Sprintf("%.0f runs, %.1f expected", runs, mean)
+	return result
+}
+
+// serialCorrelation tests the lag-1 autocorrelation of pocket values; zeros count as 0
+func serialCorrelation(history []models.RouletteNumber) TestResult {
+	result := TestResult{Name: "Serial correlation", SampleSize: len(history)}
+	if len(history) < minSequenceLength {
+		return skipped(result, fmt.Sprintf("needs at least %d spins", minSequenceLength))
+	}
+
+	values := make([]float64, len(history))
+	mean := 0.0
+	for i, n := range history {
+		if !n.IsZero() {
+			values[i] = float64(n)
+		}
+		mean += values[i]
+	}
+	mean /= float64(len(values))
+
+	numerator, denominator := 0.0, 0.0
+	for i, value := range values {
+		denominator += (value - mean) * (value - mean)
+		if i > 0 {
+			numerator += (values[i-1] - mean) * (value - mean)
+		}
+	}
+	if denominator == 0 {
+		return skipped(result, "zero variance")
+	}
+
+	n := float64(len(values))
+	r := numerator / denominator
+	result.Statistic = r
+	// Under independence r is approximately normal with mean -1/n and variance 1/n
+	result.PValue = normalPValue((r + 1/n) * math.Sqrt(n))
+	return result
+}
+
+func chiSquareStatistic(observed, expected []float64) float64 {
+	statistic := 0.0
+	for i := range observed {
+		if expected[i] > 0 {
+			diff := observed[i] - expected[i]
+			statistic += diff * diff / expected[i]
+		}
+	}
+	return statistic
+}
+
+func skipped(result TestResult, note string) TestResult {
+	result.Skipped = true
+	result.PValue = 1
+	result.Note = note
+	return result
+}
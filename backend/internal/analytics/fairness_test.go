@@ -0,0 +1,69 @@
+package analytics
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"casino-backend/internal/models"
+)
+
+func TestChiSquarePValue(t *testing.T) {
+	tests := []struct {
+		x    float64
+		df   int
+		want float64
+	}{
+		{x: 3.841, df: 1, want: 0.05},
+		{x: 18.307, df: 10, want: 0.05},
+		{x: 50.998, df: 36, want: 0.05},
+		{x: 36, df: 36, want: 0.4686},
+	}
+	for _, tt := range tests {
+		if got := chiSquarePValue(tt.x, tt.df); math.Abs(got-tt.want) > 0.001 {
+			t.Errorf("chiSquarePValue(%v, %d) = %.4f, want %.4f", tt.x, tt.df, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyzeFairness(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	pockets := models.WheelEuropean.Pockets()
+
+	fair := make([]models.RouletteNumber, 3700)
+	for i := range fair {
+		fair[i] = pockets[rng.Intn(len(pockets))]
+	}
+	report, err := AnalyzeFairness(models.WheelEuropean, fair)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Verdict != VerdictFair {
+		t.Errorf("fair wheel got verdict %s: %+v", report.Verdict, report.Tests)
+	}
+
+	// A wheel that lands on 17 a third of the time
+	biased := make([]models.RouletteNumber, 3700)
+	for i := range biased {
+		if i%3 == 0 {
+			biased[i] = 17
+		} else {
+			biased[i] = pockets[rng.Intn(len(pockets))]
+		}
+	}
+	report, err = AnalyzeFairness(models.WheelEuropean, biased)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Verdict != VerdictBiased {
+		t.Errorf("biased wheel got verdict %s", report.Verdict)
+	}
+
+	report, err = AnalyzeFairness(models.WheelEuropean, fair[:5])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Verdict != VerdictInsufficientData {
+		t.Errorf("short history got verdict %s", report.Verdict)
+	}
+}
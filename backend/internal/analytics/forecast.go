@@ -0,0 +1,152 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"casino-backend/internal/models"
+	"casino-backend/internal/wheel"
+)
+
+// ForecastConfig tunes the combined forecast model
+type ForecastConfig struct {
+	Decay           float64 `json:"decay"`           // Weight multiplier per spin of age, in (0, 1]
+	SectorWeight    float64 `json:"sectorWeight"`    // Weight of the group correction
+	LongTermPenalty float64 `json:"longTermPenalty"` // Score multiplier for numbers above their fair share
+}
+
+// DefaultForecastConfig matches the defaults of the frontend model
+var DefaultForecastConfig = ForecastConfig{
+	Decay:           0.97,
+	SectorWeight:    0.5,
+	LongTermPenalty: 0.5,
+}
+
+// Validate checks the configuration ranges
+func (c ForecastConfig) Validate() error {
+	if c.Decay <= 0 || c.Decay > 1 {
+		return fmt.Errorf("decay must be in (0, 1], got %v", c.Decay)
+	}
+	if c.SectorWeight < 0 {
+		return fmt.Errorf("sectorWeight must be non-negative, got %v", c.SectorWeight)
+	}
+	if c.LongTermPenalty < 0 {
+		return fmt.Errorf("longTermPenalty must be non-negative, got %v", c.LongTermPenalty)
+	}
+	return nil
+}
+
+// ForecastEntry is the relative score of one pocket
+type ForecastEntry struct {
+	Label       string                `json:"label"`
+	Number      models.RouletteNumber `json:"number"`
+	Probability float64               `json:"probability"`
+}
+
+// BuildCombinedForecast scores every pocket of the wheel from an exponentially
+// decayed history: under-represented numbers get a bonus, numbers above their
+// fair share are penalised, and numbers from cold colors, columns and dozens
+// get a group correction. Scores are normalised to sum to 1 and sorted in
+// descending order.
+func BuildCombinedForecast(wheelType models.WheelType, history []models.RouletteNumber, config ForecastConfig) []ForecastEntry {
+	wheelType = wheelType.OrDefault()
+	total := len(history)
+
+	// Sum of all decay weights, i.e. the weighted count of a number that hit every spin
+	maxDecay := float64(total)
+	if config.Decay < 1 {
+		maxDecay = (1 - math.Pow(config.Decay, float64(total))) / (1 - config.Decay)
+	}
+
+	counts := make(map[models.RouletteNumber]float64)
+	sectors := make(map[string]float64)
+	for i, n := range history {
+		weight := math.Pow(config.Decay, float64(total-i-1))
+		counts[n] += weight
+		for _, group := range forecastGroups(n) {
+			sectors[group] += weight
+		}
+	}
+
+	maxSector := 0.0
+	for _, value := range sectors {
+		maxSector = math.Max(maxSector, value)
+	}
+
+	fairShare := 1 / float64(wheelType.PocketCount())
+	pockets := wheelType.Pockets()
+	entries := make([]ForecastEntry, 0, len(pockets))
+	sum := 0.0
+
+	for _, n := range pockets {
+		shortTermFreq := normalize(counts[n], maxDecay)
+		score := 1 - shortTermFreq // Under-represented numbers get a bonus
+
+		// Penalise numbers that are overheated on the long run
+		if shortTermFreq > fairShare {
+			score *= config.LongTermPenalty
+		}
+
+		groupCorrection := 0.0
+		for _, group := range forecastGroups(n) {
+			groupCorrection += 1 - normalize(sectorScore(sectors, group), maxSector)
+		}
+		score += groupCorrection * config.SectorWeight
+
+		score = round4(score)
+		sum += score
+		entries = append(entries, ForecastEntry{
+			Label:       n.String(),
+			Number:      n,
+			Probability: score,
+		})
+	}
+
+	for i := range entries {
+		if sum > 0 {
+			entries[i].Probability = round4(entries[i].Probability / sum)
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Probability > entries[j].Probability
+	})
+	return entries
+}
+
+// forecastGroups returns the color, column and dozen keys of a number.
+// Zeros share the "other" key for columns and dozens.
+func forecastGroups(n models.RouletteNumber) [3]string {
+	color := "zero"
+	if c := wheel.ColorOf(n); c != wheel.Green {
+		color = string(c)
+	}
+	column, dozen := "other", "other"
+	if c := wheel.ColumnOf(n); c > 0 {
+		column = fmt.Sprintf("column%d", c)
+	}
+	if d := wheel.DozenOf(n); d > 0 {
+		dozen = fmt.Sprintf("dozen%d", d)
+	}
+	return [3]string{color, column, dozen}
+}
+
+// sectorScore returns the weighted count of a group; zeros get no column or dozen score
+func sectorScore(sectors map[string]float64, group string) float64 {
+	if group == "other" {
+		return 0
+	}
+	return sectors[group]
+}
+
+func normalize(value, max float64) float64 {
+	if max == 0 {
+		return 0
+	}
+	return value / max
+}
+
+func round4(value float64) float64 {
+	return math.Round(value*1e4) / 1e4
+}
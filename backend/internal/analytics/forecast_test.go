@@ -0,0 +1,47 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+
+	"casino-backend/internal/models"
+)
+
+func TestBuildCombinedForecast(t *testing.T) {
+	history := []models.RouletteNumber{1, 1, 1, 3, 5, 7, 1}
+	forecast := BuildCombinedForecast(models.WheelEuropean, history, DefaultForecastConfig)
+
+	if len(forecast) != 37 {
+		t.Fatalf("got %d entries, want 37", len(forecast))
+	}
+
+	sum := 0.0
+	scores := make(map[models.RouletteNumber]float64)
+	for i, entry := range forecast {
+		sum += entry.Probability
+		scores[entry.Number] = entry.Probability
+		if i > 0 && entry.Probability > forecast[i-1].Probability {
+			t.Errorf("entries are not sorted at %d", i)
+		}
+	}
+	if math.Abs(sum-1) > 0.01 {
+		t.Errorf("probabilities sum to %.4f, want 1", sum)
+	}
+
+	// 1 is red, first column, first dozen and overheated; 2 is black and never came up
+	if scores[1] >= scores[2] {
+		t.Errorf("hot number scored %.4f, cold number %.4f", scores[1], scores[2])
+	}
+}
+
+func TestBuildCombinedForecastEmptyHistory(t *testing.T) {
+	forecast := BuildCombinedForecast(models.WheelAmerican, nil, DefaultForecastConfig)
+	if len(forecast) != 38 {
+		t.Fatalf("got %d entries, want 38", len(forecast))
+	}
+	for _, entry := range forecast {
+		if math.IsNaN(entry.Probability) {
+			t.Fatalf("NaN probability for %s", entry.Label)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package analytics
+
+import (
+	"fmt"
+
+	"casino-backend/internal/models"
+	"casino-backend/internal/wheel"
+)
+
+// SignatureLags are the spin gaps measured by the dealer signature analysis:
+// consecutive results, and the result before the previous one and the next.
+var SignatureLags = []int{1, 2}
+
+// DistanceDistribution is the histogram of clockwise wheel distances between
+// spins that are Lag results apart
+type DistanceDistribution struct {
+	Lag        int        `json:"lag"`
+	SampleSize int        `json:"sampleSize"`
+	Counts     []int      `json:"counts"` // Indexed by clockwise distance in pockets
+	Peak       int        `json:"peak"`   // Most frequent distance
+	PeakCount  int        `json:"peakCount"`
+	Uniformity TestResult `json:"uniformity"` // Chi-square against equally likely distances
+}
+
+// SignatureSegment is the part of a room history spun by one dealer
+type SignatureSegment struct {
+	Dealer    string                 `json:"dealer"`
+	Start     int                    `json:"start"` // Index in history of the first spin of the segment
+	Spins     int                    `json:"spins"`
+	Distances []DistanceDistribution `json:"distances"`
+}
+
+// SignatureReport is the dealer signature analysis of a room
+type SignatureReport struct {
+	WheelType  models.WheelType       `json:"wheelType"`
+	TotalSpins int                    `json:"totalSpins"`
+	Distances  []DistanceDistribution `json:"distances"`
+	Segments   []SignatureSegment     `json:"segments"`
+}
+
+// SignatureTracker accumulates distance histograms one spin at a time, so the
+// report never has to walk the whole history again
+type SignatureTracker struct {
+	layout   *wheel.Layout
+	total    int
+	recent   []int // Wheel positions of the last spins, most recent last
+	overall  [][]int
+	segments []*trackedSegment
+}
+
+type trackedSegment struct {
+	dealer string
+	start  int
+	spins  int
+	counts [][]int
+}
+
+// NewSignatureTracker creates an empty tracker for a wheel
+func NewSignatureTracker(wheelType models.WheelType) (*SignatureTracker, error) {
+	layout, err := wheel.ForType(wheelType.OrDefault())
+	if err != nil {
+		return nil, err
+	}
+	return &SignatureTracker{
+		layout:  layout,
+		overall: newLagCounts(layout.Size()),
+	}, nil
+}
+
+// Total returns the number of spins seen by the tracker
+func (t *SignatureTracker) Total() int {
+	return t.total
+}
+
+// StartSegment attributes every following spin to a new dealer
+func (t *SignatureTracker) StartSegment(dealer string) {
+	t.segments = append(t.segments, &trackedSegment{
+		dealer: dealer,
+		start:  t.total,
+		counts: newLagCounts(t.layout.Size()),
+	})
+}
+
+// Add records the next spin. A distance pair belongs to the segment of its later spin.
+func (t *SignatureTracker) Add(n models.RouletteNumber) error {
+	position, err := t.layout.Position(n)
+	if err != nil {
+		return fmt.Errorf("%w: %s", models.ErrInvalidNumber, n)
+	}
+
+	var segment *trackedSegment
+	if len(t.segments) > 0 {
+		segment = t.segments[len(t.segments)-1]
+		segment.spins++
+	}
+
+	size := t.layout.Size()
+	for i, lag := range SignatureLags {
+		if lag > len(t.recent) {
+			continue
+		}
+		from := t.recent[len(t.recent)-lag]
+		distance := ((position-from)%size + size) % size
+		t.overall[i][distance]++
+		if segment != nil {
+			segment.counts[i][distance]++
+		}
+	}
+
+	t.recent = append(t.recent, position)
+	if maxLag := SignatureLags[len(SignatureLags)-1]; len(t.recent) > maxLag {
+		t.recent = t.recent[len(t.recent)-maxLag:]
+	}
+	t.total++
+	return nil
+}
+
+// Report summarises the accumulated histograms
+func (t *SignatureTracker) Report() *SignatureReport {
+	report := &SignatureReport{
+		WheelType:  t.layout.Type,
+		TotalSpins: t.total,
+		Distances:  distanceDistributions(t.overall),
+		Segments:   make([]SignatureSegment, 0, len(t.segments)),
+	}
+	for _, segment := range t.segments {
+		report.Segments = append(report.Segments, SignatureSegment{
+			Dealer:    segment.dealer,
+			Start:     segment.start,
+			Spins:     segment.spins,
+			Distances: distanceDistributions(segment.counts),
+		})
+	}
+	return report
+}
+
+func newLagCounts(size int) [][]int {
+	counts := make([][]int, len(SignatureLags))
+	for i := range counts {
+		counts[i] = make([]int, size)
+	}
+	return counts
+}
+
+func distanceDistributions(counts [][]int) []DistanceDistribution {
+	distributions := make([]DistanceDistribution, 0, len(counts))
+	for i, lagCounts := range counts {
+		distribution := DistanceDistribution{
+			Lag:    SignatureLags[i],
+			Counts: append([]int(nil), lagCounts...),
+		}
+		for distance, count := range lagCounts {
+			distribution.SampleSize += count
+			if count > distribution.PeakCount {
+				distribution.Peak = distance
+				distribution.PeakCount = count
+			}
+		}
+		distribution.Uniformity = distanceChiSquare(lagCounts, distribution.SampleSize)
+		distributions = append(distributions, distribution)
+	}
+	return distributions
+}
+
+// distanceChiSquare tests that every distance is equally likely, which holds
+// for independent spins on a fair wheel
+func distanceChiSquare(counts []int, sampleSize int) TestResult {
+	result := TestResult{Name: "Distance chi-square", SampleSize: sampleSize, DegreesOfFreedom: len(counts) - 1}
+
+	required := minExpectedPerCell * len(counts)
+	if sampleSize < required {
+		return skipped(result, fmt.Sprintf("needs at least %d pairs", required))
+	}
+
+	observed := make([]float64, len(counts))
+	expected := make([]float64, len(counts))
+	for i, count := range counts {
+		observed[i] = float64(count)
+		expected[i] = float64(sampleSize) / float64(len(counts))
+	}
+
+	result.Statistic = chiSquareStatistic(observed, expected)
+	result.PValue = chiSquarePValue(result.Statistic, result.DegreesOfFreedom)
+	return result
+}
@@ -0,0 +1,161 @@
+package analytics
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"unicode/utf8"
+
+	"casino-backend/internal/database"
+	"casino-backend/internal/models"
+)
+
+// MaxDealerNameLength is the longest dealer name, in characters
+const MaxDealerNameLength = 64
+
+// MaxDealerMarkersPerRoom caps the dealer segments a room can have
+const MaxDealerMarkersPerRoom = 200
+
+// ErrInvalidDealer is returned for a dealer name or segment the registry refuses
+var ErrInvalidDealer = errors.New("invalid dealer")
+
+// SignatureRegistry keeps a SignatureTracker per room, updated incrementally
+// as numbers are added. Removals and history replacements drop the tracker,
+// which is rebuilt from the stored history and dealer markers on the next report.
+type SignatureRegistry struct {
+	repo database.RouletteRepositoryInterface
+
+	mu       sync.Mutex
+	trackers map[string]*SignatureTracker
+}
+
+// NewSignatureRegistry creates a registry reading histories from repo
+func NewSignatureRegistry(repo database.RouletteRepositoryInterface) *SignatureRegistry {
+	return &SignatureRegistry{
+		repo:     repo,
+		trackers: make(map[string]*SignatureTracker),
+	}
+}
+
+// NumberAdded implements database.HistoryObserver
+func (r *SignatureRegistry) NumberAdded(session *models.RouletteSession, number models.RouletteNumber) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tracker, ok := r.trackers[session.Key]
+	if !ok {
+		return
+	}
+	// Another write slipped in between: rebuild on the next report
+	if tracker.Total() != len(session.History)-1 || tracker.Add(number) != nil {
+		delete(r.trackers, session.Key)
+	}
+}
+
+// NumberRemoved implements database.HistoryObserver
+func (r *SignatureRegistry) NumberRemoved(session *models.RouletteSession, index int) {
+	r.invalidate(session)
+}
+
+// HistoryReplaced implements database.HistoryObserver
+func (r *SignatureRegistry) HistoryReplaced(session *models.RouletteSession) {
+	r.invalidate(session)
+}
+
+// Report returns the signature report of a room, or nil if the room does not exist
+func (r *SignatureRegistry) Report(key string) (*SignatureReport, error) {
+	session, err := r.repo.GetSession(key)
+	if err != nil || session == nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tracker, err := r.tracker(session)
+	if err != nil {
+		return nil, err
+	}
+	return tracker.Report(), nil
+}
+
+// StartDealer marks that a dealer spins every following result of a room.
+// It returns nil if the room does not exist.
+func (r *SignatureRegistry) StartDealer(key, dealer string) (*models.DealerMarker, error) {
+	if dealer == "" || utf8.RuneCountInString(dealer) > MaxDealerNameLength {
+		return nil, fmt.Errorf("%w: name must be 1-%d characters", ErrInvalidDealer, MaxDealerNameLength)
+	}
+
+	session, err := r.repo.GetSession(key)
+	if err != nil || session == nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	markers, err := r.repo.GetDealerMarkers(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(markers) >= MaxDealerMarkersPerRoom {
+		return nil, fmt.Errorf("%w: a room keeps at most %d dealer segments", ErrInvalidDealer, MaxDealerMarkersPerRoom)
+	}
+
+	tracker, err := r.tracker(session)
+	if err != nil {
+		return nil, err
+	}
+
+	marker := models.DealerMarker{Dealer: dealer, Start: len(session.History)}
+	if err := r.repo.AddDealerMarker(key, marker); err != nil {
+		return nil, err
+	}
+	tracker.StartSegment(dealer)
+	return &marker, nil
+}
+
+// tracker returns an up-to-date tracker for the session, replaying the
+// stored history when needed. The caller must hold r.mu.
+func (r *SignatureRegistry) tracker(session *models.RouletteSession) (*SignatureTracker, error) {
+	if tracker, ok := r.trackers[session.Key]; ok && tracker.Total() == len(session.History) {
+		return tracker, nil
+	}
+
+	tracker, err := NewSignatureTracker(session.WheelType)
+	if err != nil {
+		return nil, err
+	}
+
+	markers, err := r.repo.GetDealerMarkers(session.Key)
+	if err != nil {
+		return nil, err
+	}
+	for i, n := range session.History {
+		for len(markers) > 0 && markers[0].Start <= i {
+			tracker.StartSegment(markers[0].Dealer)
+			markers = markers[1:]
+		}
+		if err := tracker.Add(n); err != nil {
+			return nil, err
+		}
+	}
+	for _, marker := range markers {
+		tracker.StartSegment(marker.Dealer)
+	}
+
+	r.trackers[session.Key] = tracker
+	return tracker, nil
+}
+
+// invalidate drops the tracker of a room and clamps dealer markers to its new length
+func (r *SignatureRegistry) invalidate(session *models.RouletteSession) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.trackers, session.Key)
+	if err := r.repo.ClampDealerMarkers(session.Key, len(session.History)); err != nil {
+		log.Printf("[SIGNATURE] Failed to clamp dealer markers of session %s: %v", session.Key, err)
+	}
+}
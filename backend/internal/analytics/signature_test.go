@@ -0,0 +1,130 @@
+package analytics
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"casino-backend/internal/database"
+	"casino-backend/internal/models"
+)
+
+func TestSignatureTrackerDistances(t *testing.T) {
+	tracker, err := NewSignatureTracker(models.WheelEuropean)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// European wheel order starts 0, 32, 15, 19, 4
+	for _, n := range []models.RouletteNumber{0, 15, 32} {
+		if err := tracker.Add(n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tracker.StartSegment("Anna")
+	if err := tracker.Add(4); err != nil {
+		t.Fatal(err)
+	}
+
+	report := tracker.Report()
+	lag1, lag2 := report.Distances[0], report.Distances[1]
+	if lag1.SampleSize != 3 || lag1.Counts[2] != 1 || lag1.Counts[36] != 1 || lag1.Counts[3] != 1 {
+		t.Errorf("unexpected lag-1 counts %v", lag1.Counts)
+	}
+	if lag2.SampleSize != 2 || lag2.Counts[1] != 1 || lag2.Counts[2] != 1 {
+		t.Errorf("unexpected lag-2 counts %v", lag2.Counts)
+	}
+
+	if len(report.Segments) != 1 {
+		t.Fatalf("got %d segments, want 1", len(report.Segments))
+	}
+	segment := report.Segments[0]
+	if segment.Dealer != "Anna" || segment.Start != 3 || segment.Spins != 1 {
+		t.Errorf("unexpected segment %+v", segment)
+	}
+	if segment.Distances[0].SampleSize != 1 || segment.Distances[0].Counts[3] != 1 {
+		t.Errorf("unexpected segment lag-1 counts %v", segment.Distances[0].Counts)
+	}
+}
+
+func TestSignatureRegistryIncrementalMatchesRebuild(t *testing.T) {
+	repo := database.NewObservedRepository(database.NewMemoryRepository())
+	registry := NewSignatureRegistry(repo)
+	repo.AddObserver(registry)
+
+	if _, err := repo.CreateSession("room"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := registry.StartDealer("room", "Anna"); err != nil {
+		t.Fatal(err)
+	}
+	for i, n := range []models.RouletteNumber{7, 7, 12, 0, 35, 3, 26} {
+		if i == 4 {
+			if _, err := registry.StartDealer("room", "Boris"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := repo.AddNumberToSession("room", n); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	incremental, err := registry.Report("room")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registry.HistoryReplaced(&models.RouletteSession{Key: "room", History: make([]models.RouletteNumber, 7)})
+	rebuilt, err := registry.Report("room")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(incremental, rebuilt) {
+		t.Errorf("incremental report %+v differs from rebuilt %+v", incremental, rebuilt)
+	}
+	if len(rebuilt.Segments) != 2 || rebuilt.Segments[1].Start != 4 || rebuilt.Segments[1].Spins != 3 {
+		t.Errorf("unexpected segments %+v", rebuilt.Segments)
+	}
+}
+
+func TestSignatureRegistryMissingSession(t *testing.T) {
+	registry := NewSignatureRegistry(database.NewMemoryRepository())
+	report, err := registry.Report("missing")
+	if err != nil || report != nil {
+		t.Errorf("got %v, %v for a missing session", report, err)
+	}
+}
+
+func TestSignatureRegistryStoresDealers(t *testing.T) {
+	repo := database.NewMemoryRepository()
+	if _, err := repo.CreateSession("room"); err != nil {
+		t.Fatal(err)
+	}
+	registry := NewSignatureRegistry(repo)
+
+	if _, err := registry.StartDealer("room", strings.Repeat("ж", MaxDealerNameLength)); err != nil {
+		t.Errorf("name of %d characters refused: %v", MaxDealerNameLength, err)
+	}
+	if _, err := registry.StartDealer("room", strings.Repeat("a", MaxDealerNameLength+1)); !errors.Is(err, ErrInvalidDealer) {
+		t.Errorf("got %v for an overlong name, want ErrInvalidDealer", err)
+	}
+	for i := 1; i < MaxDealerMarkersPerRoom; i++ {
+		if _, err := registry.StartDealer("room", "Anna"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := registry.StartDealer("room", "Boris"); !errors.Is(err, ErrInvalidDealer) {
+		t.Errorf("got %v over the marker cap, want ErrInvalidDealer", err)
+	}
+
+	// A new registry, as after a restart, reads the markers back from the repository
+	report, err := NewSignatureRegistry(repo).Report("room")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Segments) != MaxDealerMarkersPerRoom {
+		t.Errorf("got %d segments after reload, want %d", len(report.Segments), MaxDealerMarkersPerRoom)
+	}
+}
@@ -0,0 +1,193 @@
+package analytics
+
+import (
+	"casino-backend/internal/models"
+	"casino-backend/internal/wheel"
+)
+
+// NumberStats describes how often a single pocket came up
+type NumberStats struct {
+	Number             models.RouletteNumber `json:"number"`
+	Color              wheel.Color           `json:"color"`
+	Count              int                   `json:"count"`
+	Percentage         float64               `json:"percentage"`
+	ExpectedPercentage float64               `json:"expectedPercentage"`
+	Deviation          float64               `json:"deviation"` // Percentage minus expected, in percentage points
+	Age                int                   `json:"age"`       // Spins since last seen, or total spins if never seen
+	LastIndex          int                   `json:"lastIndex"` // Index in history of the last hit, -1 if never seen
+}
+
+// GroupStats describes how often a table group (dozen, column, color...) came up
+type GroupStats struct {
+	Category           string  `json:"category"`
+	Name               string  `json:"name"`
+	Count              int     `json:"count"`
+	Percentage         float64 `json:"percentage"`
+	ExpectedPercentage float64 `json:"expectedPercentage"`
+	Deviation          float64 `json:"deviation"`
+	Age                int     `json:"age"`
+}
+
+// RepeatSeries is a run of the same number on consecutive spins
+type RepeatSeries struct {
+	Value  models.RouletteNumber `json:"value"`
+	Start  int                   `json:"start"` // Index in history of the first spin of the run
+	Length int                   `json:"length"`
+}
+
+// Stats is the full statistics report of a room
+type Stats struct {
+	WheelType  models.WheelType `json:"wheelType"`
+	TotalSpins int              `json:"totalSpins"`
+	Numbers    []NumberStats    `json:"numbers"`
+	Groups     []GroupStats     `json:"groups"`
+	Repeats    []RepeatSeries   `json:"repeats"`
+}
+
+// LastN returns the last n spins of a history; n <= 0 returns the whole history
+func LastN(history []models.RouletteNumber, n int) []models.RouletteNumber {
+	if n <= 0 || n >= len(history) {
+		return history
+	}
+	return history[len(history)-n:]
+}
+
+// ComputeStats builds the statistics report of a history recorded on the given wheel
+func ComputeStats(wheelType models.WheelType, history []models.RouletteNumber) *Stats {
+	wheelType = wheelType.OrDefault()
+	total := len(history)
+	pockets := wheelType.PocketCount()
+
+	stats := &Stats{
+		WheelType:  wheelType,
+		TotalSpins: total,
+		Numbers:    make([]NumberStats, 0, pockets),
+		Groups:     make([]GroupStats, 0, len(wheel.TableGroups())),
+		Repeats:    FindRepeats(history),
+	}
+
+	counts := Counts(history)
+	lastIndex := LastIndexes(history)
+
+	for _, pocket := range wheelType.Pockets() {
+		last, seen := lastIndex[pocket]
+		if !seen {
+			last = -1
+		}
+		expected := 100 / float64(pockets)
+		percentage := percentageOf(counts[pocket], total)
+		stats.Numbers = append(stats.Numbers, NumberStats{
+			Number:             pocket,
+			Color:              wheel.ColorOf(pocket),
+			Count:              counts[pocket],
+			Percentage:         percentage,
+			ExpectedPercentage: expected,
+			Deviation:          percentage - expected,
+			Age:                ageOf(last, total),
+			LastIndex:          last,
+		})
+	}
+
+	for _, group := range wheel.TableGroups() {
+		count := 0
+		for _, n := range group.Numbers {
+			count += counts[n]
+		}
+		expected := 100 * float64(len(group.Numbers)) / float64(pockets)
+		percentage := percentageOf(count, total)
+		stats.Groups = append(stats.Groups, GroupStats{
+			Category:           group.Category,
+			Name:               group.Name,
+			Count:              count,
+			Percentage:         percentage,
+			ExpectedPercentage: expected,
+			Deviation:          percentage - expected,
+			Age:                GroupAge(history, group.Numbers),
+		})
+	}
+
+	return stats
+}
+
+// Counts returns how many times each number appears in the history
+func Counts(history []models.RouletteNumber) map[models.RouletteNumber]int {
+	counts := make(map[models.RouletteNumber]int)
+	for _, n := range history {
+		counts[n]++
+	}
+	return counts
+}
+
+// LastIndexes returns the index of the last occurrence of each number
+func LastIndexes(history []models.RouletteNumber) map[models.RouletteNumber]int {
+	last := make(map[models.RouletteNumber]int)
+	for i, n := range history {
+		last[n] = i
+	}
+	return last
+}
+
+// NumberAge returns the number of spins since n last came up, or len(history) if it never did
+func NumberAge(history []models.RouletteNumber, n models.RouletteNumber) int {
+	return GroupAge(history, []models.RouletteNumber{n})
+}
+
+// GroupAge returns the number of spins since any number of the group came up,
+// or len(history) if none did
+func GroupAge(history []models.RouletteNumber, group []models.RouletteNumber) int {
+	members := make(map[models.RouletteNumber]bool, len(group))
+	for _, n := range group {
+		members[n] = true
+	}
+	for i := len(history) - 1; i >= 0; i-- {
+		if members[history[i]] {
+			return len(history) - 1 - i
+		}
+	}
+	return len(history)
+}
+
+// GroupStreak returns how many of the latest spins in a row belong to the group
+func GroupStreak(history []models.RouletteNumber, group []models.RouletteNumber) int {
+	members := make(map[models.RouletteNumber]bool, len(group))
+	for _, n := range group {
+		members[n] = true
+	}
+	streak := 0
+	for i := len(history) - 1; i >= 0 && members[history[i]]; i-- {
+		streak++
+	}
+	return streak
+}
+
+// FindRepeats returns every run of two or more identical consecutive numbers
+func FindRepeats(history []models.RouletteNumber) []RepeatSeries {
+	repeats := []RepeatSeries{}
+	for i := 0; i < len(history)-1; {
+		j := i
+		for j+1 < len(history) && history[j] == history[j+1] {
+			j++
+		}
+		if j > i {
+			repeats = append(repeats, RepeatSeries{Value: history[i], Start: i, Length: j - i + 1})
+			i = j + 1
+		} else {
+			i++
+		}
+	}
+	return repeats
+}
+
+func ageOf(lastIndex, total int) int {
+	if lastIndex < 0 {
+		return total
+	}
+	return total - 1 - lastIndex
+}
+
+func percentageOf(count, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(count) / float64(total)
+}
@@ -0,0 +1,67 @@
+package analytics
+
+import (
+	"testing"
+
+	"casino-backend/internal/models"
+	"casino-backend/internal/wheel"
+)
+
+func TestComputeStats(t *testing.T) {
+	history := []models.RouletteNumber{5, 5, 5, 0, 17, 32, 32}
+	stats := ComputeStats(models.WheelEuropean, history)
+
+	if stats.TotalSpins != len(history) {
+		t.Errorf("TotalSpins = %d, want %d", stats.TotalSpins, len(history))
+	}
+	if len(stats.Numbers) != 37 {
+		t.Fatalf("got %d numbers, want 37", len(stats.Numbers))
+	}
+
+	byNumber := make(map[models.RouletteNumber]NumberStats)
+	for _, n := range stats.Numbers {
+		byNumber[n.Number] = n
+	}
+
+	if five := byNumber[5]; five.Count != 3 || five.Age != 4 || five.LastIndex != 2 {
+		t.Errorf("unexpected stats for 5: %+v", five)
+	}
+	if never := byNumber[36]; never.Count != 0 || never.Age != len(history) || never.LastIndex != -1 {
+		t.Errorf("unexpected stats for 36: %+v", never)
+	}
+
+	for _, group := range stats.Groups {
+		if group.Category == wheel.CategoryDozen && group.Name == "1-12" {
+			if group.Count != 3 || group.Age != 4 {
+				t.Errorf("unexpected stats for first dozen: %+v", group)
+			}
+			if expected := 100 * 12.0 / 37.0; group.ExpectedPercentage != expected {
+				t.Errorf("first dozen expected %.3f%%, want %.3f%%", group.ExpectedPercentage, expected)
+			}
+		}
+	}
+
+	if len(stats.Repeats) != 2 || stats.Repeats[0].Length != 3 || stats.Repeats[1].Start != 5 {
+		t.Errorf("unexpected repeats: %+v", stats.Repeats)
+	}
+}
+
+func TestComputeStatsUsesWheelDenominator(t *testing.T) {
+	stats := ComputeStats(models.WheelAmerican, []models.RouletteNumber{models.DoubleZero})
+	if len(stats.Numbers) != 38 {
+		t.Fatalf("got %d numbers, want 38", len(stats.Numbers))
+	}
+	if expected := 100 / 38.0; stats.Numbers[0].ExpectedPercentage != expected {
+		t.Errorf("expected percentage %.3f, want %.3f", stats.Numbers[0].ExpectedPercentage, expected)
+	}
+}
+
+func TestLastN(t *testing.T) {
+	history := []models.RouletteNumber{1, 2, 3, 4}
+	if got := LastN(history, 2); len(got) != 2 || got[0] != 3 {
+		t.Errorf("LastN(2) = %v", got)
+	}
+	if got := LastN(history, 0); len(got) != 4 {
+		t.Errorf("LastN(0) = %v", got)
+	}
+}
@@ -0,0 +1,234 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"casino-backend/internal/models"
+	"casino-backend/internal/wheel"
+)
+
+// Transition matrix categories besides the table group categories
+const (
+	CategoryPocket = "pocket"
+	zeroState      = "zero"
+)
+
+// maxNotableTransitions caps the list of significant transitions per matrix
+const maxNotableTransitions = 10
+
+// TransitionCell compares one "after X comes Y" transition with a fair wheel
+type TransitionCell struct {
+	From                string  `json:"from"`
+	To                  string  `json:"to"`
+	Count               int     `json:"count"`
+	Probability         float64 `json:"probability"`         // Observed P(To | From)
+	ExpectedProbability float64 `json:"expectedProbability"` // P(To) on a fair wheel
+	ZScore              float64 `json:"zScore"`
+	AdjustedPValue      float64 `json:"adjustedPValue"` // Bonferroni-adjusted over the tested cells
+}
+
+// TransitionMatrix holds first-order transition counts between the states of one category
+type TransitionMatrix struct {
+	Category      string           `json:"category"`
+	States        []string         `json:"states"`
+	Counts        [][]int          `json:"counts"`        // Counts[from][to]
+	Probabilities [][]float64      `json:"probabilities"` // Row-normalised counts
+	Expected      []float64        `json:"expected"`      // Probability of each state on a fair wheel
+	Independence  TestResult       `json:"independence"`  // Chi-square of all rows against Expected
+	Notable       []TransitionCell `json:"notable"`       // Significant cells, most significant first
+}
+
+// TransitionsReport holds the transition matrices of a room
+type TransitionsReport struct {
+	WheelType   models.WheelType   `json:"wheelType"`
+	TotalSpins  int                `json:"totalSpins"`
+	Transitions int                `json:"transitions"`
+	Matrices    []TransitionMatrix `json:"matrices"`
+}
+
+// transitionStates maps pockets to the states of a category
+type transitionStates struct {
+	category string
+	names    []string
+	sizes    []int
+	index    map[models.RouletteNumber]int
+}
+
+// AnalyzeTransitions builds first-order transition matrices between pockets,
+// colours, dozens and columns and tests them against independent spins
+func AnalyzeTransitions(wheelType models.WheelType, history []models.RouletteNumber) *TransitionsReport {
+	wheelType = wheelType.OrDefault()
+	report := &TransitionsReport{
+		WheelType:  wheelType,
+		TotalSpins: len(history),
+		Matrices:   []TransitionMatrix{},
+	}
+	if len(history) > 1 {
+		report.Transitions = len(history) - 1
+	}
+
+	states := []transitionStates{
+		pocketStates(wheelType),
+		groupStates(wheelType, wheel.CategoryColor),
+		groupStates(wheelType, wheel.CategoryDozen),
+		groupStates(wheelType, wheel.CategoryColumn),
+	}
+	for _, s := range states {
+		report.Matrices = append(report.Matrices, buildTransitionMatrix(s, wheelType.PocketCount(), history))
+	}
+	return report
+}
+
+func pocketStates(wheelType models.WheelType) transitionStates {
+	s := transitionStates{category: CategoryPocket, index: make(map[models.RouletteNumber]int)}
+	for i, n := range wheelType.Pockets() {
+		s.names = append(s.names, n.String())
+		s.sizes = append(s.sizes, 1)
+		s.index[n] = i
+	}
+	return s
+}
+
+// groupStates returns the groups of a table category plus a state for the zeros
+func groupStates(wheelType models.WheelType, category string) transitionStates {
+	s := transitionStates{category: category, index: make(map[models.RouletteNumber]int)}
+	for _, group := range wheel.GroupsByCategory(category) {
+		for _, n := range group.Numbers {
+			s.index[n] = len(s.names)
+		}
+		s.names = append(s.names, group.Name)
+		s.sizes = append(s.sizes, len(group.Numbers))
+	}
+	for _, n := range wheelType.Zeros() {
+		s.index[n] = len(s.names)
+	}
+	s.names = append(s.names, zeroState)
+	s.sizes = append(s.sizes, len(wheelType.Zeros()))
+	return s
+}
+
+func buildTransitionMatrix(s transitionStates, pocketCount int, history []models.RouletteNumber) TransitionMatrix {
+	k := len(s.names)
+	matrix := TransitionMatrix{
+		Category:      s.category,
+		States:        s.names,
+		Counts:        make([][]int, k),
+		Probabilities: make([][]float64, k),
+		Expected:      make([]float64, k),
+		Notable:       []TransitionCell{},
+	}
+	for i := range matrix.Counts {
+		matrix.Counts[i] = make([]int, k)
+		matrix.Probabilities[i] = make([]float64, k)
+		matrix.Expected[i] = float64(s.sizes[i]) / float64(pocketCount)
+	}
+
+	for i := 1; i < len(history); i++ {
+		from, okFrom := s.index[history[i-1]]
+		to, okTo := s.index[history[i]]
+		if okFrom && okTo {
+			matrix.Counts[from][to]++
+		}
+	}
+
+	rowTotals := make([]int, k)
+	for from, row := range matrix.Counts {
+		for _, count := range row {
+			rowTotals[from] += count
+		}
+		if rowTotals[from] > 0 {
+			for to, count := range row {
+				matrix.Probabilities[from][to] = float64(count) / float64(rowTotals[from])
+			}
+		}
+	}
+
+	matrix.Independence = transitionChiSquare(matrix, rowTotals)
+	matrix.Notable = notableTransitions(matrix, rowTotals)
+	return matrix
+}
+
+// transitionChiSquare tests every row with enough data against the fair wheel
+// distribution; degrees of freedom are (k-1) per tested row
+func transitionChiSquare(matrix TransitionMatrix, rowTotals []int) TestResult {
+	k := len(matrix.States)
+	result := TestResult{Name: "Transition chi-square"}
+
+	var observed, expected []float64
+	rows := 0
+	for from, row := range matrix.Counts {
+		if rowTotals[from] == 0 || !rowTestable(matrix.Expected, rowTotals[from]) {
+			continue
+		}
+		rows++
+		result.SampleSize += rowTotals[from]
+		for to, count := range row {
+			observed = append(observed, float64(count))
+			expected = append(expected, float64(rowTotals[from])*matrix.Expected[to])
+		}
+	}
+
+	if rows == 0 {
+		return skipped(result, fmt.Sprintf("needs at least %d expected transitions per cell", minExpectedPerCell))
+	}
+
+	result.DegreesOfFreedom = rows * (k - 1)
+	result.Statistic = chiSquareStatistic(observed, expected)
+	result.PValue = chiSquarePValue(result.Statistic, result.DegreesOfFreedom)
+	result.Note = fmt.Sprintf("%d of %d rows tested", rows, k)
+	return result
+}
+
+// notableTransitions returns the cells whose binomial z-score stays
+// significant after a Bonferroni correction over all tested cells
+func notableTransitions(matrix TransitionMatrix, rowTotals []int) []TransitionCell {
+	var cells []TransitionCell
+	tested := 0
+	for from, row := range matrix.Counts {
+		n := float64(rowTotals[from])
+		for to, count := range row {
+			p := matrix.Expected[to]
+			if n*p < minExpectedPerCell || p >= 1 {
+				continue
+			}
+			tested++
+			z := (float64(count) - n*p) / math.Sqrt(n*p*(1-p))
+			cells = append(cells, TransitionCell{
+				From:                matrix.States[from],
+				To:                  matrix.States[to],
+				Count:               count,
+				Probability:         matrix.Probabilities[from][to],
+				ExpectedProbability: p,
+				ZScore:              z,
+				AdjustedPValue:      normalPValue(z),
+			})
+		}
+	}
+
+	notable := []TransitionCell{}
+	for _, cell := range cells {
+		cell.AdjustedPValue = math.Min(1, cell.AdjustedPValue*float64(tested))
+		if cell.AdjustedPValue < suspiciousLevel {
+			notable = append(notable, cell)
+		}
+	}
+	sort.SliceStable(notable, func(i, j int) bool {
+		return notable[i].AdjustedPValue < notable[j].AdjustedPValue
+	})
+	if len(notable) > maxNotableTransitions {
+		notable = notable[:maxNotableTransitions]
+	}
+	return notable
+}
+
+// rowTestable reports whether every cell of a row has enough expected transitions
+func rowTestable(expected []float64, rowTotal int) bool {
+	for _, p := range expected {
+		if float64(rowTotal)*p < minExpectedPerCell {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,65 @@
+package analytics
+
+import (
+	"math/rand"
+	"testing"
+
+	"casino-backend/internal/models"
+	"casino-backend/internal/wheel"
+)
+
+func TestAnalyzeTransitionsCounts(t *testing.T) {
+	report := AnalyzeTransitions(models.WheelEuropean, []models.RouletteNumber{1, 2, 1, 0})
+	if report.Transitions != 3 || len(report.Matrices) != 4 {
+		t.Fatalf("got %d transitions and %d matrices", report.Transitions, len(report.Matrices))
+	}
+
+	pockets := report.Matrices[0]
+	if pockets.Counts[1][2] != 1 || pockets.Counts[2][1] != 1 || pockets.Counts[1][0] != 1 {
+		t.Errorf("unexpected pocket counts from 1: %v", pockets.Counts[1])
+	}
+	if pockets.Probabilities[1][2] != 0.5 {
+		t.Errorf("P(2 | 1) = %v, want 0.5", pockets.Probabilities[1][2])
+	}
+
+	colors := report.Matrices[1]
+	if colors.Category != "color" || len(colors.States) != 3 {
+		t.Fatalf("unexpected colour states %v", colors.States)
+	}
+	// red -> black, black -> red, red -> zero
+	if colors.Counts[0][1] != 1 || colors.Counts[1][0] != 1 || colors.Counts[0][2] != 1 {
+		t.Errorf("unexpected colour counts %v", colors.Counts)
+	}
+	if !colors.Independence.Skipped {
+		t.Error("independence test ran on 3 transitions")
+	}
+}
+
+func TestAnalyzeTransitionsDetectsPattern(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	pockets := models.WheelEuropean.Pockets()
+
+	fair := make([]models.RouletteNumber, 5000)
+	for i := range fair {
+		fair[i] = pockets[rng.Intn(len(pockets))]
+	}
+	if notable := AnalyzeTransitions(models.WheelEuropean, fair).Matrices[1].Notable; len(notable) != 0 {
+		t.Errorf("fair history has notable colour transitions %+v", notable)
+	}
+
+	// Red is always followed by black
+	rigged := make([]models.RouletteNumber, 5000)
+	for i := range rigged {
+		rigged[i] = pockets[rng.Intn(len(pockets))]
+		if i > 0 && wheel.ColorOf(rigged[i-1]) == wheel.Red {
+			rigged[i] = 2
+		}
+	}
+	colors := AnalyzeTransitions(models.WheelEuropean, rigged).Matrices[1]
+	if colors.Independence.PValue > 0.001 {
+		t.Errorf("rigged history p-value %.4f", colors.Independence.PValue)
+	}
+	if len(colors.Notable) == 0 || colors.Notable[0].From != "red" {
+		t.Errorf("unexpected notable transitions %+v", colors.Notable)
+	}
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// ErrInvalidAdmin is returned for admin accounts that cannot be created
+var ErrInvalidAdmin = errors.New("invalid admin")
+
+// MinAdminPasswordLength is the shortest admin password accepted
+const MinAdminPasswordLength = 8
+
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]{3,64}$`)
+
+// Admin is an account of the admin API
+type Admin struct {
+	ID           int64     `json:"id"`
+	Username     string    `json:"username"`
+	PasswordHash string    `json:"-"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// NewAdmin validates the credentials of a new account and hashes its password
+func NewAdmin(username, password string) (*Admin, error) {
+	if !usernamePattern.MatchString(username) {
+		return nil, fmt.Errorf("%w: username must be 3 to 64 letters, digits, '_', '.' or '-'", ErrInvalidAdmin)
+	}
+	if len(password) < MinAdminPasswordLength {
+		return nil, fmt.Errorf("%w: password must be at least %d characters", ErrInvalidAdmin, MinAdminPasswordLength)
+	}
+	hash, err := HashPassword(password)
+	if err != nil {
+		if errors.Is(err, ErrPasswordTooLong) {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidAdmin, err)
+		}
+		return nil, err
+	}
+	return &Admin{Username: username, PasswordHash: hash, CreatedAt: time.Now()}, nil
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     string
+)
+
+// CheckAdminPassword verifies the password of an account. A missing account
+// costs the same bcrypt comparison, so logins do not reveal which usernames exist.
+func CheckAdminPassword(admin *Admin, password string) bool {
+	if admin == nil {
+		dummyHashOnce.Do(func() {
+			dummyHash, _ = HashPassword("not an admin password")
+		})
+		CheckPassword(dummyHash, password)
+		return false
+	}
+	return IsHashed(admin.PasswordHash) && CheckPassword(admin.PasswordHash, password)
+}
@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidInvite is returned for invites that cannot be created
+var ErrInvalidInvite = errors.New("invalid invite")
+
+// Limits of an invite
+const (
+	MaxInviteUses     = 10000
+	MaxInvitesPerRoom = 100
+)
+
+// Invite lets its holders into a room with a role instead of the password.
+// Only the SHA-256 hash of the code is stored.
+type Invite struct {
+	ID         int64      `json:"id"`
+	SessionKey string     `json:"sessionKey"`
+	Code       string     `json:"code,omitempty"` // Only returned when the invite is created
+	CodeHash   string     `json:"-"`
+	Role       RoomRole   `json:"role"`
+	MaxUses    int        `json:"maxUses"` // 0 for unlimited
+	Uses       int        `json:"uses"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"` // Never expires when nil
+	CreatedAt  time.Time  `json:"createdAt"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
+
+// NewInvite validates an invite and draws its code
+func NewInvite(key string, role RoomRole, maxUses int, expiresAt *time.Time, now time.Time) (*Invite, error) {
+	if !role.IsValid() {
+		return nil, fmt.Errorf("%w: role must be owner, editor or viewer", ErrInvalidInvite)
+	}
+	if maxUses < 0 || maxUses > MaxInviteUses {
+		return nil, fmt.Errorf("%w: maxUses must be between 0 (unlimited) and %d", ErrInvalidInvite, MaxInviteUses)
+	}
+	if expiresAt != nil && !expiresAt.After(now) {
+		return nil, fmt.Errorf("%w: expiresAt must be in the future", ErrInvalidInvite)
+	}
+
+	code, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Invite{
+		SessionKey: key,
+		Code:       code,
+		CodeHash:   HashInviteCode(code),
+		Role:       role,
+		MaxUses:    maxUses,
+		ExpiresAt:  expiresAt,
+		CreatedAt:  now,
+	}, nil
+}
+
+// HashInviteCode returns the stored form of an invite code
+func HashInviteCode(code string) string {
+	return hashSecret(code)
+}
+
+// Usable reports whether the invite still lets someone in
+func (i *Invite) Usable(now time.Time) bool {
+	if i.RevokedAt != nil {
+		return false
+	}
+	if i.ExpiresAt != nil && !now.Before(*i.ExpiresAt) {
+		return false
+	}
+	return i.MaxUses == 0 || i.Uses < i.MaxUses
+}
@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrPasswordTooLong is returned for passwords bcrypt would silently truncate
+var ErrPasswordTooLong = errors.New("password is longer than 72 bytes")
+
+// MaxPasswordLength is the number of bytes bcrypt reads
+const MaxPasswordLength = 72
+
+// HashPassword returns the bcrypt hash of a password
+func HashPassword(password string) (string, error) {
+	if len(password) > MaxPasswordLength {
+		return "", ErrPasswordTooLong
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// HashStoredPassword hashes a password that was stored in plaintext before
+// passwords were hashed. Such a password may be longer than bcrypt reads, so
+// a long one is hashed through its SHA-256 digest, as CheckPassword expects.
+func HashStoredPassword(password string) (string, error) {
+	if len(password) > MaxPasswordLength {
+		return HashPassword(preDigest(password))
+	}
+	return HashPassword(password)
+}
+
+// CheckPassword reports whether a password matches a stored hash. Values
+// stored before passwords were hashed are compared in constant time.
+func CheckPassword(stored, password string) bool {
+	if !IsHashed(stored) {
+		return subtle.ConstantTimeCompare([]byte(stored), []byte(password)) == 1
+	}
+	// HashPassword refuses long passwords, so only HashStoredPassword made their hashes
+	if len(password) > MaxPasswordLength {
+		password = preDigest(password)
+	}
+	return bcrypt.CompareHashAndPassword([]byte(stored), []byte(password)) == nil
+}
+
+// preDigest shortens a password to a SHA-256 digest bcrypt reads whole
+func preDigest(password string) string {
+	digest := sha256.Sum256([]byte(password))
+	return hex.EncodeToString(digest[:])
+}
+
+// IsHashed reports whether a stored password is a bcrypt hash
+func IsHashed(stored string) bool {
+	for _, prefix := range []string{"$2a$", "$2b$", "$2y$"} {
+		if strings.HasPrefix(stored, prefix) {
+			return true
+		}
+	}
+	return false
+}
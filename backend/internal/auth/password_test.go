@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestHashPassword(t *testing.T) {
+	hash, err := HashPassword("secret")
+	if err != nil {
+		t.Fatalf("HashPassword: %v", err)
+	}
+	if !IsHashed(hash) {
+		t.Fatalf("hash %q is not a bcrypt hash", hash)
+	}
+	if strings.Contains(hash, "secret") {
+		t.Fatalf("hash %q contains the password", hash)
+	}
+
+	tests := []struct {
+		name     string
+		stored   string
+		password string
+		want     bool
+	}{
+		{"hash match", hash, "secret", true},
+		{"hash mismatch", hash, "Secret", false},
+		{"hash empty password", hash, "", false},
+		{"hash long password", hash, "secret" + strings.Repeat("x", MaxPasswordLength), false},
+		{"plaintext match", "secret", "secret", true},
+		{"plaintext mismatch", "secret", "secret2", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CheckPassword(tt.stored, tt.password); got != tt.want {
+				t.Errorf("CheckPassword(%q, %q) = %v, want %v", tt.stored, tt.password, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHashStoredPassword(t *testing.T) {
+	long := strings.Repeat("x", MaxPasswordLength) + "secret"
+	hash, err := HashStoredPassword(long)
+	if err != nil {
+		t.Fatalf("HashStoredPassword of %d bytes: %v", len(long), err)
+	}
+	if !CheckPassword(hash, long) {
+		t.Error("long password does not match its hash")
+	}
+	if CheckPassword(hash, strings.Repeat("x", MaxPasswordLength)+"secreT") {
+		t.Error("password differing after 72 bytes matches")
+	}
+
+	short, err := HashStoredPassword("secret")
+	if err != nil || !CheckPassword(short, "secret") {
+		t.Errorf("short password: hash %q, error %v", short, err)
+	}
+}
+
+func TestHashPasswordTooLong(t *testing.T) {
+	if _, err := HashPassword(strings.Repeat("x", MaxPasswordLength)); err != nil {
+		t.Fatalf("HashPassword of %d bytes: %v", MaxPasswordLength, err)
+	}
+	if _, err := HashPassword(strings.Repeat("x", MaxPasswordLength+1)); !errors.Is(err, ErrPasswordTooLong) {
+		t.Fatalf("HashPassword of %d bytes: got %v, want ErrPasswordTooLong", MaxPasswordLength+1, err)
+	}
+}
@@ -0,0 +1,26 @@
+package auth
+
+import "time"
+
+// Revocation invalidates access tokens of a room before they expire: those of
+// one grant, or with All those of every grant up to GrantID, legacy tokens
+// without a grant included. It is kept until the last token it matches expires.
+type Revocation struct {
+	ID         int64     `json:"id"`
+	SessionKey string    `json:"sessionKey"`
+	GrantID    int64     `json:"grantId"`
+	All        bool      `json:"all"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// Matches reports whether the revocation invalidates a token
+func (r *Revocation) Matches(claims *RoomClaims) bool {
+	if claims == nil || r.SessionKey != claims.Key {
+		return false
+	}
+	if r.All {
+		return claims.GrantID <= r.GrantID
+	}
+	return claims.GrantID == r.GrantID
+}
@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// RoomRole is the access a room token grants
+type RoomRole string
+
+const (
+	RoomOwner  RoomRole = "owner"  // Records spins and issues tokens for the room
+	RoomEditor RoomRole = "editor" // Records spins
+	RoomViewer RoomRole = "viewer" // Receives broadcasts only
+)
+
+// Lifetimes of room tokens. Access tokens are short-lived and renewed with
+// the refresh token stored server-side.
+const (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// legacyTokenTTL is the lifetime of the tokens issued before refresh tokens existed
+const legacyTokenTTL = 24 * time.Hour
+
+// IsValid reports whether the role is known
+func (r RoomRole) IsValid() bool {
+	return r == RoomOwner || r == RoomEditor || r == RoomViewer
+}
+
+// CanEdit reports whether the role may change the room
+func (r RoomRole) CanEdit() bool {
+	return r == RoomOwner || r == RoomEditor
+}
+
+// RoomClaims are the claims of a room access token
+type RoomClaims struct {
+	Key      string   `json:"key"`
+	Role     RoomRole `json:"role,omitempty"`
+	GrantID  int64    `json:"gid,omitempty"` // ID of the refresh token the access token belongs to, 0 for legacy tokens
+	PlayerID string   `json:"pid,omitempty"` // Player ID of the grant, empty for legacy tokens
+	jwt.RegisteredClaims
+}
+
+// IssueRoomToken signs an access token of a grant
+func IssueRoomToken(secret []byte, grant *RefreshToken, now time.Time) (string, time.Time, error) {
+	if !grant.Role.IsValid() {
+		return "", time.Time{}, fmt.Errorf("unknown room role %q", grant.Role)
+	}
+	id, err := randomHex(16)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	expiresAt := now.Add(AccessTokenTTL)
+	claims := RoomClaims{
+		Key:      grant.SessionKey,
+		Role:     grant.Role,
+		GrantID:  grant.ID,
+		PlayerID: grant.PlayerID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        id,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign token: %w", err)
+	}
+	return token, expiresAt, nil
+}
+
+// ParseRoomToken verifies the signature of a token issued for a room, without
+// looking at revocations; see RoomTokens.Parse. Tokens issued before rooms had
+// roles carry none and are editor tokens.
+func ParseRoomToken(secret []byte, tokenString, key string) (*RoomClaims, error) {
+	if tokenString == "" {
+		return nil, fmt.Errorf("%w: token is required", ErrInvalidToken)
+	}
+
+	claims := &RoomClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if claims.Key == "" || claims.Key != key {
+		return nil, fmt.Errorf("%w: token was not issued for session %s", ErrInvalidToken, key)
+	}
+	if claims.Role == "" {
+		claims.Role = RoomEditor
+	}
+	if !claims.Role.IsValid() {
+		return nil, fmt.Errorf("%w: unknown room role %q", ErrInvalidToken, claims.Role)
+	}
+	return claims, nil
+}
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrInvalidToken is returned for tokens that do not grant access
+var ErrInvalidToken = errors.New("invalid token")
+
+// RoleAdmin is the role claim of admin tokens
+const RoleAdmin = "admin"
+
+// AdminTokenTTL is the lifetime of an admin token
+const AdminTokenTTL = 15 * time.Minute
+
+// AdminClaims are the claims of an admin token; the subject is the username
+type AdminClaims struct {
+	Role string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+// IssueAdminToken signs a short-lived admin token
+func IssueAdminToken(secret []byte, admin *Admin, now time.Time) (string, time.Time, error) {
+	expiresAt := now.Add(AdminTokenTTL)
+	claims := AdminClaims{
+		Role: RoleAdmin,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   admin.Username,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign token: %w", err)
+	}
+	return token, expiresAt, nil
+}
+
+// ParseAdminToken verifies an admin token and its role claim
+func ParseAdminToken(secret []byte, tokenString string) (*AdminClaims, error) {
+	claims := &AdminClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if claims.Role != RoleAdmin || claims.Subject == "" {
+		return nil, fmt.Errorf("%w: not an admin token", ErrInvalidToken)
+	}
+	return claims, nil
+}
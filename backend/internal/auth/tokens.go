@@ -0,0 +1,261 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// revocationsReload is how long revocations are cached, so that revocations
+// made by other instances apply within this delay
+const revocationsReload = time.Minute
+
+// RefreshToken is a grant of access to a room. Its ID and player ID are carried
+// by the access tokens issued with it; only the SHA-256 hash of the token is stored.
+type RefreshToken struct {
+	ID         int64      `json:"id"`
+	SessionKey string     `json:"sessionKey"`
+	TokenHash  string     `json:"-"`
+	Role       RoomRole   `json:"role"`
+	PlayerID   string     `json:"playerId"` // Drawn by the server, names the holder in bets and bankrolls
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
+
+// Usable reports whether the refresh token can still be exchanged
+func (t *RefreshToken) Usable(now time.Time) bool {
+	return t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
+
+// TokenPair is handed to a client when it enters a room or refreshes its access token
+type TokenPair struct {
+	Token            string    `json:"token"`
+	RefreshToken     string    `json:"refreshToken"`
+	Role             RoomRole  `json:"role"`
+	PlayerID         string    `json:"playerId"`
+	ExpiresAt        time.Time `json:"expiresAt"`
+	RefreshExpiresAt time.Time `json:"refreshExpiresAt"`
+}
+
+// TokenStore persists refresh tokens and revocations
+type TokenStore interface {
+	CreateRefreshToken(token *RefreshToken) (*RefreshToken, error)
+	// RotateRefreshToken replaces the hash of a usable refresh token, returning nil when there is none
+	RotateRefreshToken(key, tokenHash, newHash string, now time.Time) (*RefreshToken, error)
+	GetRefreshTokens(key string) ([]*RefreshToken, error)
+	RevokeRefreshToken(key string, id int64, now time.Time) (bool, error)
+	// RevokeRefreshTokens revokes every refresh token of a room, returning the highest ID
+	RevokeRefreshTokens(key string, now time.Time) (int64, error)
+	// SaveTokenRevocation stores a revocation and drops the expired ones
+	SaveTokenRevocation(revocation *Revocation) (*Revocation, error)
+	GetTokenRevocations(now time.Time) ([]*Revocation, error)
+}
+
+// RevocationListener is told about every revocation, e.g. to disconnect the
+// clients holding revoked tokens
+type RevocationListener interface {
+	TokensRevoked(revocation *Revocation)
+}
+
+// RoomTokens issues, refreshes and revokes room tokens
+type RoomTokens struct {
+	store  TokenStore
+	secret []byte
+
+	mu          sync.Mutex
+	revocations []*Revocation // Cached, nil until loaded
+	loadedAt    time.Time
+	listeners   []RevocationListener
+}
+
+// NewRoomTokens creates a token service signing with secret
+func NewRoomTokens(store TokenStore, secret []byte) *RoomTokens {
+	return &RoomTokens{store: store, secret: secret}
+}
+
+// AddListener registers a listener for all subsequent revocations
+func (t *RoomTokens) AddListener(listener RevocationListener) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.listeners = append(t.listeners, listener)
+}
+
+// Issue grants a role in a room
+func (t *RoomTokens) Issue(key string, role RoomRole) (*TokenPair, error) {
+	if !role.IsValid() {
+		return nil, fmt.Errorf("unknown room role %q", role)
+	}
+	refreshToken, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+	playerID, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	grant, err := t.store.CreateRefreshToken(&RefreshToken{
+		SessionKey: key,
+		TokenHash:  hashSecret(refreshToken),
+		Role:       role,
+		PlayerID:   playerID,
+		ExpiresAt:  now.Add(RefreshTokenTTL),
+		CreatedAt:  now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return t.pair(grant, refreshToken, now)
+}
+
+// Refresh exchanges a refresh token for a new access token. The refresh token
+// is rotated: the one passed in stops working.
+func (t *RoomTokens) Refresh(key, refreshToken string) (*TokenPair, error) {
+	if refreshToken == "" {
+		return nil, fmt.Errorf("%w: refresh token is required", ErrInvalidToken)
+	}
+	next, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	grant, err := t.store.RotateRefreshToken(key, hashSecret(refreshToken), hashSecret(next), now)
+	if err != nil {
+		return nil, err
+	}
+	if grant == nil {
+		return nil, fmt.Errorf("%w: refresh token is invalid, expired or revoked", ErrInvalidToken)
+	}
+	return t.pair(grant, next, now)
+}
+
+// Parse verifies an access token of a room and checks that it was not revoked
+func (t *RoomTokens) Parse(token, key string) (*RoomClaims, error) {
+	claims, err := ParseRoomToken(t.secret, token, key)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	revocations, err := t.currentRevocations(time.Now())
+	t.mu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load token revocations: %w", err)
+	}
+	for _, revocation := range revocations {
+		if revocation.Matches(claims) {
+			return nil, fmt.Errorf("%w: token was revoked", ErrInvalidToken)
+		}
+	}
+	return claims, nil
+}
+
+// Grants returns the refresh tokens of a room, revoked ones included
+func (t *RoomTokens) Grants(key string) ([]*RefreshToken, error) {
+	return t.store.GetRefreshTokens(key)
+}
+
+// RevokeGrant revokes a refresh token and the access tokens issued with it,
+// reporting whether it existed
+func (t *RoomTokens) RevokeGrant(key string, id int64) (bool, error) {
+	now := time.Now()
+	revoked, err := t.store.RevokeRefreshToken(key, id, now)
+	if err != nil || !revoked {
+		return false, err
+	}
+	return true, t.revoke(&Revocation{
+		SessionKey: key,
+		GrantID:    id,
+		ExpiresAt:  now.Add(AccessTokenTTL),
+		CreatedAt:  now,
+	})
+}
+
+// RevokeRoom revokes every token of a room issued so far, e.g. after its password changed
+func (t *RoomTokens) RevokeRoom(key string) error {
+	now := time.Now()
+	lastID, err := t.store.RevokeRefreshTokens(key, now)
+	if err != nil {
+		return err
+	}
+	return t.revoke(&Revocation{
+		SessionKey: key,
+		GrantID:    lastID,
+		All:        true,
+		ExpiresAt:  now.Add(legacyTokenTTL),
+		CreatedAt:  now,
+	})
+}
+
+// pair issues an access token of a grant
+func (t *RoomTokens) pair(grant *RefreshToken, refreshToken string, now time.Time) (*TokenPair, error) {
+	token, expiresAt, err := IssueRoomToken(t.secret, grant, now)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenPair{
+		Token:            token,
+		RefreshToken:     refreshToken,
+		Role:             grant.Role,
+		PlayerID:         grant.PlayerID,
+		ExpiresAt:        expiresAt,
+		RefreshExpiresAt: grant.ExpiresAt,
+	}, nil
+}
+
+// revoke stores a revocation and tells the listeners
+func (t *RoomTokens) revoke(revocation *Revocation) error {
+	saved, err := t.store.SaveTokenRevocation(revocation)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	if t.revocations != nil {
+		t.revocations = append(t.revocations, saved)
+	}
+	listeners := append([]RevocationListener(nil), t.listeners...)
+	t.mu.Unlock()
+
+	log.Printf("[AUTH] Revoked tokens of session %s (grant %d, all: %t)", saved.SessionKey, saved.GrantID, saved.All)
+	for _, listener := range listeners {
+		listener.TokensRevoked(saved)
+	}
+	return nil
+}
+
+// currentRevocations returns the cached revocations; the caller must hold t.mu
+func (t *RoomTokens) currentRevocations(now time.Time) ([]*Revocation, error) {
+	if t.revocations != nil && now.Sub(t.loadedAt) < revocationsReload {
+		return t.revocations, nil
+	}
+	revocations, err := t.store.GetTokenRevocations(now)
+	if err != nil {
+		return nil, err
+	}
+	if revocations == nil {
+		revocations = []*Revocation{}
+	}
+	t.revocations = revocations
+	t.loadedAt = now
+	return revocations, nil
+}
+
+// hashSecret returns the SHA-256 hex digest under which random secrets are stored
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,90 @@
+package betting
+
+import "time"
+
+// Bankroll event kinds
+const (
+	EventOpen   = "open"
+	EventSettle = "settle"
+)
+
+// Bankroll tracks the balance of one player in a room
+type Bankroll struct {
+	SessionKey      string    `json:"sessionKey"`
+	PlayerID        string    `json:"playerId"`
+	StartingBalance int64     `json:"startingBalance"`
+	Balance         int64     `json:"balance"`
+	Peak            int64     `json:"peak"`        // Highest balance so far
+	MaxDrawdown     int64     `json:"maxDrawdown"` // Largest drop from a previous peak, in chips
+	Staked          int64     `json:"staked"`
+	Returned        int64     `json:"returned"`
+	ROI             float64   `json:"roi"` // Net result divided by the total stake
+	Spins           int       `json:"spins"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+}
+
+// BankrollEvent is one change of a bankroll balance
+type BankrollEvent struct {
+	ID         int64     `json:"id"`
+	SessionKey string    `json:"sessionKey"`
+	PlayerID   string    `json:"playerId"`
+	Kind       string    `json:"kind"`
+	WagerID    int64     `json:"wagerId,omitempty"`
+	Delta      int64     `json:"delta"`
+	Balance    int64     `json:"balance"` // Balance after the event
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// NewBankroll opens a bankroll with a starting balance
+func NewBankroll(sessionKey, playerID string, startingBalance int64) (*Bankroll, *BankrollEvent) {
+	now := time.Now()
+	bankroll := &Bankroll{
+		SessionKey:      sessionKey,
+		PlayerID:        playerID,
+		StartingBalance: startingBalance,
+		Balance:         startingBalance,
+		Peak:            startingBalance,
+		UpdatedAt:       now,
+	}
+	return bankroll, bankroll.event(EventOpen, 0, startingBalance, now)
+}
+
+// Net returns the result of the player since the bankroll was opened
+func (b *Bankroll) Net() int64 {
+	return b.Balance - b.StartingBalance
+}
+
+// Settle applies a settled wager to the balance and updates the drawdown and ROI
+func (b *Bankroll) Settle(wager *Wager) *BankrollEvent {
+	now := time.Now()
+	result := wager.Result
+
+	b.Balance += result.Net
+	b.Staked += result.Staked
+	b.Returned += result.Returned
+	b.Spins++
+	if b.Balance > b.Peak {
+		b.Peak = b.Balance
+	}
+	if drawdown := b.Peak - b.Balance; drawdown > b.MaxDrawdown {
+		b.MaxDrawdown = drawdown
+	}
+	if b.Staked > 0 {
+		b.ROI = float64(b.Returned-b.Staked) / float64(b.Staked)
+	}
+	b.UpdatedAt = now
+
+	return b.event(EventSettle, wager.ID, result.Net, now)
+}
+
+func (b *Bankroll) event(kind string, wagerID, delta int64, at time.Time) *BankrollEvent {
+	return &BankrollEvent{
+		SessionKey: b.SessionKey,
+		PlayerID:   b.PlayerID,
+		Kind:       kind,
+		WagerID:    wagerID,
+		Delta:      delta,
+		Balance:    b.Balance,
+		CreatedAt:  at,
+	}
+}
@@ -0,0 +1,234 @@
+package betting
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"casino-backend/internal/models"
+	"casino-backend/internal/wheel"
+)
+
+// ErrInvalidBet is returned for bets that cannot be placed on the table
+var ErrInvalidBet = errors.New("invalid bet")
+
+// BetType identifies a kind of bet
+type BetType string
+
+// Inside bets, placed on the numbers of the table
+const (
+	BetStraight BetType = "straight"
+	BetSplit    BetType = "split"
+	BetStreet   BetType = "street" // Also the zero trios, e.g. 0-1-2
+	BetCorner   BetType = "corner"
+	BetSixLine  BetType = "six_line"
+	BetBasket   BetType = "basket" // Every zero together with 1, 2 and 3
+)
+
+// Outside bets
+const (
+	BetDozen  BetType = "dozen"
+	BetColumn BetType = "column"
+	BetRed    BetType = "red"
+	BetBlack  BetType = "black"
+	BetEven   BetType = "even"
+	BetOdd    BetType = "odd"
+	BetLow    BetType = "low"
+	BetHigh   BetType = "high"
+)
+
+// Racetrack bets, which place several chips at once
+const (
+	BetVoisinsDuZero   BetType = "voisins_du_zero"
+	BetTiersDuCylindre BetType = "tiers_du_cylindre"
+	BetOrphelins       BetType = "orphelins"
+	BetJeuZero         BetType = "jeu_zero"
+	BetNeighbours      BetType = "neighbours"
+)
+
+// DefaultNeighbours is the neighbour count of a neighbours bet without Target
+const DefaultNeighbours = 2
+
+// Bet is a single wager. Amounts are in chips, the smallest unit of the table.
+type Bet struct {
+	Type    BetType                 `json:"type"`
+	Numbers []models.RouletteNumber `json:"numbers,omitempty"` // Covered numbers of inside bets, the centre of a neighbours bet
+	Target  int                     `json:"target,omitempty"`  // Dozen or column 1-3, neighbour count
+	Amount  int64                   `json:"amount"`
+}
+
+// IsRacetrack reports whether the bet is settled as a set of component bets
+func (t BetType) IsRacetrack() bool {
+	switch t {
+	case BetVoisinsDuZero, BetTiersDuCylindre, BetOrphelins, BetJeuZero, BetNeighbours:
+		return true
+	default:
+		return false
+	}
+}
+
+// Validate checks that the bet can be placed on a table of the given wheel
+func (b Bet) Validate(wheelType models.WheelType) error {
+	if b.Amount <= 0 {
+		return fmt.Errorf("%w: amount must be positive, got %d", ErrInvalidBet, b.Amount)
+	}
+	if b.Type.IsRacetrack() {
+		_, err := Components(wheelType, b)
+		return err
+	}
+	_, err := Coverage(wheelType, b)
+	return err
+}
+
+// Coverage returns the numbers a non-racetrack bet wins on
+func Coverage(wheelType models.WheelType, b Bet) ([]models.RouletteNumber, error) {
+	wheelType = wheelType.OrDefault()
+	if !wheelType.IsValid() {
+		return nil, fmt.Errorf("%w: unknown wheel type %q", ErrInvalidBet, wheelType)
+	}
+	switch b.Type {
+	case BetStraight:
+		if len(b.Numbers) != 1 || !wheelType.Contains(b.Numbers[0]) {
+			return nil, fmt.Errorf("%w: straight bet needs one number of the %s wheel", ErrInvalidBet, wheelType)
+		}
+		return b.Numbers, nil
+	case BetSplit, BetStreet, BetCorner, BetSixLine:
+		numbers := sortedNumbers(b.Numbers)
+		for _, allowed := range insideCombinations(wheelType, b.Type) {
+			if equalNumbers(numbers, allowed) {
+				return numbers, nil
+			}
+		}
+		return nil, fmt.Errorf("%w: %v is not a %s on the %s table", ErrInvalidBet, b.Numbers, b.Type, wheelType)
+	case BetBasket:
+		return basketNumbers(wheelType), nil
+	case BetDozen, BetColumn:
+		if b.Target < 1 || b.Target > 3 {
+			return nil, fmt.Errorf("%w: %s must be 1, 2 or 3, got %d", ErrInvalidBet, b.Type, b.Target)
+		}
+		of := wheel.DozenOf
+		if b.Type == BetColumn {
+			of = wheel.ColumnOf
+		}
+		return numbersWhere(func(n models.RouletteNumber) bool { return of(n) == b.Target }), nil
+	case BetRed:
+		return numbersWhere(func(n models.RouletteNumber) bool { return wheel.ColorOf(n) == wheel.Red }), nil
+	case BetBlack:
+		return numbersWhere(func(n models.RouletteNumber) bool { return wheel.ColorOf(n) == wheel.Black }), nil
+	case BetEven:
+		return numbersWhere(func(n models.RouletteNumber) bool { return n%2 == 0 }), nil
+	case BetOdd:
+		return numbersWhere(func(n models.RouletteNumber) bool { return n%2 == 1 }), nil
+	case BetLow:
+		return numbersWhere(func(n models.RouletteNumber) bool { return n <= 18 }), nil
+	case BetHigh:
+		return numbersWhere(func(n models.RouletteNumber) bool { return n >= 19 }), nil
+	case BetVoisinsDuZero, BetTiersDuCylindre, BetOrphelins, BetJeuZero, BetNeighbours:
+		return nil, fmt.Errorf("%w: %s is a racetrack bet, use Components", ErrInvalidBet, b.Type)
+	default:
+		return nil, fmt.Errorf("%w: unknown bet type %q", ErrInvalidBet, b.Type)
+	}
+}
+
+// Payout returns the winnings-to-stake ratio of a non-racetrack bet, e.g. 35 for a straight.
+// Inside bets pay 36/n - 1 for n covered numbers on every wheel, so the extra
+// zeros of American and triple-zero wheels only show up as a bigger house edge
+// and as a wider basket.
+func Payout(wheelType models.WheelType, b Bet) (int64, error) {
+	numbers, err := Coverage(wheelType, b)
+	if err != nil {
+		return 0, err
+	}
+	return int64(int(models.MaxNumber)/len(numbers) - 1), nil
+}
+
+// basketNumbers returns the zeros of the wheel together with 1, 2 and 3:
+// the European first four (8:1), the American top line (6:1) and the
+// triple-zero basket (5:1)
+func basketNumbers(wheelType models.WheelType) []models.RouletteNumber {
+	return sortedNumbers(append(wheelType.Zeros(), 1, 2, 3))
+}
+
+// insideCombinations lists every legal chip placement of an inside bet type
+func insideCombinations(wheelType models.WheelType, betType BetType) [][]models.RouletteNumber {
+	var combinations [][]models.RouletteNumber
+	add := func(numbers ...models.RouletteNumber) {
+		combinations = append(combinations, sortedNumbers(numbers))
+	}
+
+	switch betType {
+	case BetSplit:
+		for n := models.RouletteNumber(1); n <= models.MaxNumber; n++ {
+			if n%3 != 0 {
+				add(n, n+1)
+			}
+			if n+3 <= models.MaxNumber {
+				add(n, n+3)
+			}
+		}
+		for _, pair := range zeroSplits[wheelType] {
+			add(pair[:]...)
+		}
+	case BetStreet:
+		for n := models.RouletteNumber(1); n <= models.MaxNumber; n += 3 {
+			add(n, n+1, n+2)
+		}
+		for _, trio := range zeroTrios[wheelType] {
+			add(trio[:]...)
+		}
+	case BetCorner:
+		for n := models.RouletteNumber(1); n+4 <= models.MaxNumber; n++ {
+			if n%3 != 0 {
+				add(n, n+1, n+3, n+4)
+			}
+		}
+	case BetSixLine:
+		for n := models.RouletteNumber(1); n+5 <= models.MaxNumber; n += 3 {
+			add(n, n+1, n+2, n+3, n+4, n+5)
+		}
+	}
+	return combinations
+}
+
+// Splits and trios involving the zeros, following each table's layout
+var (
+	zeroSplits = map[models.WheelType][][2]models.RouletteNumber{
+		models.WheelEuropean:   {{0, 1}, {0, 2}, {0, 3}},
+		models.WheelAmerican:   {{0, 1}, {0, 2}, {0, models.DoubleZero}, {models.DoubleZero, 2}, {models.DoubleZero, 3}},
+		models.WheelTripleZero: {{0, 1}, {0, models.DoubleZero}, {models.DoubleZero, 2}, {models.DoubleZero, models.TripleZero}, {models.TripleZero, 3}},
+	}
+	zeroTrios = map[models.WheelType][][3]models.RouletteNumber{
+		models.WheelEuropean:   {{0, 1, 2}, {0, 2, 3}},
+		models.WheelAmerican:   {{0, 1, 2}, {0, models.DoubleZero, 2}, {models.DoubleZero, 2, 3}},
+		models.WheelTripleZero: {{0, models.DoubleZero, models.TripleZero}},
+	}
+)
+
+// numbersWhere returns the numbers 1-36 matching a predicate; outside bets never cover zeros
+func numbersWhere(match func(models.RouletteNumber) bool) []models.RouletteNumber {
+	var numbers []models.RouletteNumber
+	for n := models.RouletteNumber(1); n <= models.MaxNumber; n++ {
+		if match(n) {
+			numbers = append(numbers, n)
+		}
+	}
+	return numbers
+}
+
+func sortedNumbers(numbers []models.RouletteNumber) []models.RouletteNumber {
+	sorted := append([]models.RouletteNumber(nil), numbers...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted
+}
+
+func equalNumbers(a, b []models.RouletteNumber) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,242 @@
+package betting
+
+import (
+	"errors"
+	"testing"
+
+	"casino-backend/internal/models"
+)
+
+func numbers(n ...models.RouletteNumber) []models.RouletteNumber {
+	return n
+}
+
+func TestPayout(t *testing.T) {
+	tests := []struct {
+		wheel models.WheelType
+		bet   Bet
+		want  int64
+	}{
+		{models.WheelEuropean, Bet{Type: BetStraight, Numbers: numbers(17)}, 35},
+		{models.WheelAmerican, Bet{Type: BetStraight, Numbers: numbers(models.DoubleZero)}, 35},
+		{models.WheelEuropean, Bet{Type: BetSplit, Numbers: numbers(17, 20)}, 17},
+		{models.WheelEuropean, Bet{Type: BetSplit, Numbers: numbers(0, 3)}, 17},
+		{models.WheelAmerican, Bet{Type: BetSplit, Numbers: numbers(models.DoubleZero, 3)}, 17},
+		{models.WheelEuropean, Bet{Type: BetStreet, Numbers: numbers(34, 35, 36)}, 11},
+		{models.WheelEuropean, Bet{Type: BetStreet, Numbers: numbers(2, 0, 3)}, 11},
+		{models.WheelTripleZero, Bet{Type: BetStreet, Numbers: numbers(0, models.DoubleZero, models.TripleZero)}, 11},
+		{models.WheelEuropean, Bet{Type: BetCorner, Numbers: numbers(1, 2, 4, 5)}, 8},
+		{models.WheelEuropean, Bet{Type: BetSixLine, Numbers: numbers(31, 32, 33, 34, 35, 36)}, 5},
+		{models.WheelEuropean, Bet{Type: BetBasket}, 8},
+		{models.WheelAmerican, Bet{Type: BetBasket}, 6},
+		{models.WheelTripleZero, Bet{Type: BetBasket}, 5},
+		{models.WheelEuropean, Bet{Type: BetDozen, Target: 2}, 2},
+		{models.WheelEuropean, Bet{Type: BetColumn, Target: 3}, 2},
+		{models.WheelAmerican, Bet{Type: BetRed}, 1},
+		{models.WheelAmerican, Bet{Type: BetBlack}, 1},
+		{models.WheelEuropean, Bet{Type: BetEven}, 1},
+		{models.WheelEuropean, Bet{Type: BetOdd}, 1},
+		{models.WheelEuropean, Bet{Type: BetLow}, 1},
+		{models.WheelEuropean, Bet{Type: BetHigh}, 1},
+	}
+	for _, tt := range tests {
+		got, err := Payout(tt.wheel, tt.bet)
+		if err != nil {
+			t.Errorf("Payout(%s, %+v) error: %v", tt.wheel, tt.bet, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Payout(%s, %+v) = %d, want %d", tt.wheel, tt.bet, got, tt.want)
+		}
+	}
+}
+
+func TestValidateRejectsInvalidBets(t *testing.T) {
+	tests := []struct {
+		name  string
+		wheel models.WheelType
+		bet   Bet
+	}{
+		{"zero amount", models.WheelEuropean, Bet{Type: BetRed}},
+		{"negative amount", models.WheelEuropean, Bet{Type: BetRed, Amount: -5}},
+		{"unknown type", models.WheelEuropean, Bet{Type: "lucky", Amount: 1}},
+		{"double zero on european", models.WheelEuropean, Bet{Type: BetStraight, Numbers: numbers(models.DoubleZero), Amount: 1}},
+		{"straight without number", models.WheelEuropean, Bet{Type: BetStraight, Amount: 1}},
+		{"split across rows", models.WheelEuropean, Bet{Type: BetSplit, Numbers: numbers(3, 4), Amount: 1}},
+		{"zero split on american", models.WheelAmerican, Bet{Type: BetSplit, Numbers: numbers(0, 3), Amount: 1}},
+		{"street not on a row", models.WheelEuropean, Bet{Type: BetStreet, Numbers: numbers(2, 3, 4), Amount: 1}},
+		{"corner across columns", models.WheelEuropean, Bet{Type: BetCorner, Numbers: numbers(3, 4, 6, 7), Amount: 1}},
+		{"six line overlapping rows", models.WheelEuropean, Bet{Type: BetSixLine, Numbers: numbers(2, 3, 4, 5, 6, 7), Amount: 1}},
+		{"dozen out of range", models.WheelEuropean, Bet{Type: BetDozen, Target: 4, Amount: 1}},
+		{"column missing", models.WheelEuropean, Bet{Type: BetColumn, Amount: 1}},
+		{"voisins not a multiple of 9", models.WheelEuropean, Bet{Type: BetVoisinsDuZero, Amount: 10}},
+		{"neighbours without centre", models.WheelEuropean, Bet{Type: BetNeighbours, Amount: 5}},
+		{"too many neighbours", models.WheelEuropean, Bet{Type: BetNeighbours, Numbers: numbers(5), Target: 19, Amount: 39}},
+		{"unknown wheel", "double_wheel", Bet{Type: BetRed, Amount: 1}},
+	}
+	for _, tt := range tests {
+		if err := tt.bet.Validate(tt.wheel); !errors.Is(err, ErrInvalidBet) {
+			t.Errorf("%s: got %v, want ErrInvalidBet", tt.name, err)
+		}
+	}
+}
+
+func TestComponentsCoverSectors(t *testing.T) {
+	tests := []struct {
+		bet   BetType
+		units int64
+		count int
+	}{
+		{BetVoisinsDuZero, 9, 17},
+		{BetTiersDuCylindre, 6, 12},
+		{BetOrphelins, 5, 8},
+		{BetJeuZero, 4, 7},
+	}
+	for _, tt := range tests {
+		bet := Bet{Type: tt.bet, Amount: tt.units * 10}
+		components, err := Components(models.WheelEuropean, bet)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.bet, err)
+		}
+
+		covered := make(map[models.RouletteNumber]bool)
+		var total int64
+		for _, c := range components {
+			total += c.Amount
+			for _, n := range c.Numbers {
+				covered[n] = true
+			}
+		}
+		if total != bet.Amount {
+			t.Errorf("%s: components stake %d, want %d", tt.bet, total, bet.Amount)
+		}
+		if len(covered) != tt.count {
+			t.Errorf("%s: covers %d numbers, want %d", tt.bet, len(covered), tt.count)
+		}
+	}
+}
+
+func TestSettle(t *testing.T) {
+	slip := Slip{Bets: []Bet{
+		{Type: BetStraight, Numbers: numbers(17), Amount: 10},
+		{Type: BetRed, Amount: 20},
+		{Type: BetOrphelins, Amount: 5},
+		{Type: BetNeighbours, Numbers: numbers(0), Target: 1, Amount: 3},
+	}}
+
+	result, err := Settle(models.WheelEuropean, slip, 17)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Straight 10*36, red loses (17 is black), orphelins 14/17 and 17/20 splits both win 18 each
+	want := []int64{360, 0, 36, 0}
+	for i, bet := range result.Bets {
+		if bet.Returned != want[i] {
+			t.Errorf("bet %d returned %d, want %d", i, bet.Returned, want[i])
+		}
+	}
+	if result.Staked != 38 || result.Returned != 396 || result.Net != 358 {
+		t.Errorf("got staked %d, returned %d, net %d", result.Staked, result.Returned, result.Net)
+	}
+
+	// 0 and one neighbour on each side: 26, 0, 32
+	result, err = Settle(models.WheelEuropean, slip, 26)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Returned != 36 || !result.Bets[3].Won {
+		t.Errorf("neighbours: got returned %d, bets %+v", result.Returned, result.Bets)
+	}
+}
+
+func TestSettleZeroLosesOutsideBets(t *testing.T) {
+	for _, wheel := range []models.WheelType{models.WheelEuropean, models.WheelAmerican, models.WheelTripleZero} {
+		for _, zero := range wheel.Zeros() {
+			for _, betType := range []BetType{BetRed, BetBlack, BetEven, BetOdd, BetLow, BetHigh} {
+				result, err := SettleBet(wheel, Bet{Type: betType, Amount: 1}, zero)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if result.Won {
+					t.Errorf("%s won on %s of the %s wheel", betType, zero, wheel)
+				}
+			}
+		}
+	}
+}
+
+func TestSettleRejectsForeignNumber(t *testing.T) {
+	slip := Slip{Bets: []Bet{{Type: BetRed, Amount: 1}}}
+	if _, err := Settle(models.WheelEuropean, slip, models.DoubleZero); !errors.Is(err, models.ErrInvalidNumber) {
+		t.Errorf("got %v, want ErrInvalidNumber", err)
+	}
+	if _, err := Settle(models.WheelEuropean, Slip{}, 5); !errors.Is(err, ErrInvalidBet) {
+		t.Errorf("got %v for an empty slip, want ErrInvalidBet", err)
+	}
+}
+
+// Every bet returns 36 units per unit staked over a full turn of the wheel,
+// which is what makes the house edge 1/37, 2/38 and 3/39
+func TestExpectedReturn(t *testing.T) {
+	bets := []Bet{
+		{Type: BetStraight, Numbers: numbers(0)},
+		{Type: BetSplit, Numbers: numbers(8, 11)},
+		{Type: BetStreet, Numbers: numbers(7, 8, 9)},
+		{Type: BetCorner, Numbers: numbers(17, 18, 20, 21)},
+		{Type: BetSixLine, Numbers: numbers(1, 2, 3, 4, 5, 6)},
+		{Type: BetDozen, Target: 1},
+		{Type: BetColumn, Target: 2},
+		{Type: BetRed},
+		{Type: BetBlack},
+		{Type: BetEven},
+		{Type: BetOdd},
+		{Type: BetLow},
+		{Type: BetHigh},
+		{Type: BetVoisinsDuZero},
+		{Type: BetTiersDuCylindre},
+		{Type: BetOrphelins},
+		{Type: BetJeuZero},
+		{Type: BetNeighbours, Numbers: numbers(5)},
+	}
+	for _, wheel := range []models.WheelType{models.WheelEuropean, models.WheelAmerican, models.WheelTripleZero} {
+		for _, bet := range bets {
+			units, err := Units(bet)
+			if err != nil {
+				units = 1
+			}
+			bet.Amount = units
+
+			var returned int64
+			for _, pocket := range wheel.Pockets() {
+				result, err := SettleBet(wheel, bet, pocket)
+				if err != nil {
+					t.Fatalf("%s on %s: %v", bet.Type, wheel, err)
+				}
+				returned += result.Returned
+			}
+			if returned != 36*bet.Amount {
+				t.Errorf("%s on %s returns %d over a turn, want %d", bet.Type, wheel, returned, 36*bet.Amount)
+			}
+		}
+	}
+}
+
+func TestBankrollSettle(t *testing.T) {
+	bankroll, opened := NewBankroll("room", "player", 100)
+	if opened.Kind != EventOpen || opened.Balance != 100 {
+		t.Errorf("unexpected open event %+v", opened)
+	}
+
+	for _, net := range []int64{50, -30, -60, 20} {
+		staked := int64(10)
+		bankroll.Settle(&Wager{ID: 1, Result: &SlipResult{Staked: staked, Returned: staked + net, Net: net}})
+	}
+
+	// 100 -> 150 (peak) -> 120 -> 60 -> 80
+	if bankroll.Balance != 80 || bankroll.Peak != 150 || bankroll.MaxDrawdown != 90 {
+		t.Errorf("got balance %d, peak %d, drawdown %d", bankroll.Balance, bankroll.Peak, bankroll.MaxDrawdown)
+	}
+	if bankroll.Net() != -20 || bankroll.ROI != -0.5 || bankroll.Spins != 4 {
+		t.Errorf("got net %d, ROI %v, spins %d", bankroll.Net(), bankroll.ROI, bankroll.Spins)
+	}
+}
@@ -0,0 +1,138 @@
+package betting
+
+import (
+	"fmt"
+
+	"casino-backend/internal/models"
+	"casino-backend/internal/wheel"
+)
+
+// placement is one chip position of a racetrack bet
+type placement struct {
+	numbers []models.RouletteNumber
+	chips   int64
+}
+
+// Classic chip placements of the French racetrack bets. They are defined on
+// the single-zero table; on other wheels the same numbers are covered and
+// each placement pays by the count of numbers it covers.
+var racetrackPlacements = map[BetType][]placement{
+	BetVoisinsDuZero: {
+		{numbers: []models.RouletteNumber{0, 2, 3}, chips: 2},
+		{numbers: []models.RouletteNumber{4, 7}, chips: 1},
+		{numbers: []models.RouletteNumber{12, 15}, chips: 1},
+		{numbers: []models.RouletteNumber{18, 21}, chips: 1},
+		{numbers: []models.RouletteNumber{19, 22}, chips: 1},
+		{numbers: []models.RouletteNumber{25, 26, 28, 29}, chips: 2},
+		{numbers: []models.RouletteNumber{32, 35}, chips: 1},
+	},
+	BetTiersDuCylindre: {
+		{numbers: []models.RouletteNumber{5, 8}, chips: 1},
+		{numbers: []models.RouletteNumber{10, 11}, chips: 1},
+		{numbers: []models.RouletteNumber{13, 16}, chips: 1},
+		{numbers: []models.RouletteNumber{23, 24}, chips: 1},
+		{numbers: []models.RouletteNumber{27, 30}, chips: 1},
+		{numbers: []models.RouletteNumber{33, 36}, chips: 1},
+	},
+	BetOrphelins: {
+		{numbers: []models.RouletteNumber{1}, chips: 1},
+		{numbers: []models.RouletteNumber{6, 9}, chips: 1},
+		{numbers: []models.RouletteNumber{14, 17}, chips: 1},
+		{numbers: []models.RouletteNumber{17, 20}, chips: 1},
+		{numbers: []models.RouletteNumber{31, 34}, chips: 1},
+	},
+	BetJeuZero: {
+		{numbers: []models.RouletteNumber{0, 3}, chips: 1},
+		{numbers: []models.RouletteNumber{12, 15}, chips: 1},
+		{numbers: []models.RouletteNumber{26}, chips: 1},
+		{numbers: []models.RouletteNumber{32, 35}, chips: 1},
+	},
+}
+
+// Component is one chip placement of a racetrack bet
+type Component struct {
+	Numbers []models.RouletteNumber `json:"numbers"`
+	Amount  int64                   `json:"amount"`
+	Payout  int64                   `json:"payout"` // Winnings-to-stake ratio
+}
+
+// Units returns the number of chips a racetrack bet is split into; the bet
+// amount must be a multiple of it
+func Units(b Bet) (int64, error) {
+	if b.Type == BetNeighbours {
+		count := b.Target
+		if count == 0 {
+			count = DefaultNeighbours
+		}
+		if count < 0 {
+			return 0, fmt.Errorf("%w: invalid neighbour count %d", ErrInvalidBet, count)
+		}
+		return int64(2*count + 1), nil
+	}
+	placements, ok := racetrackPlacements[b.Type]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s is not a racetrack bet", ErrInvalidBet, b.Type)
+	}
+	var units int64
+	for _, p := range placements {
+		units += p.chips
+	}
+	return units, nil
+}
+
+// Components splits a racetrack bet into its chip placements
+func Components(wheelType models.WheelType, b Bet) ([]Component, error) {
+	wheelType = wheelType.OrDefault()
+	units, err := Units(b)
+	if err != nil {
+		return nil, err
+	}
+	if b.Amount <= 0 || b.Amount%units != 0 {
+		return nil, fmt.Errorf("%w: %s needs a positive multiple of %d chips, got %d", ErrInvalidBet, b.Type, units, b.Amount)
+	}
+	chip := b.Amount / units
+
+	if b.Type == BetNeighbours {
+		return neighbourComponents(wheelType, b, chip)
+	}
+
+	components := make([]Component, 0, len(racetrackPlacements[b.Type]))
+	for _, p := range racetrackPlacements[b.Type] {
+		components = append(components, Component{
+			Numbers: p.numbers,
+			Amount:  chip * p.chips,
+			Payout:  int64(int(models.MaxNumber)/len(p.numbers) - 1),
+		})
+	}
+	return components, nil
+}
+
+// neighbourComponents places a straight on a number and on Target pockets on each side of it
+func neighbourComponents(wheelType models.WheelType, b Bet, chip int64) ([]Component, error) {
+	if len(b.Numbers) != 1 {
+		return nil, fmt.Errorf("%w: neighbours bet needs one centre number", ErrInvalidBet)
+	}
+	layout, err := wheel.ForType(wheelType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBet, err)
+	}
+
+	count := b.Target
+	if count == 0 {
+		count = DefaultNeighbours
+	}
+	numbers, err := layout.Neighbours(b.Numbers[0], count)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBet, err)
+	}
+
+	components := make([]Component, 0, len(numbers))
+	for _, n := range numbers {
+		components = append(components, Component{
+			Numbers: []models.RouletteNumber{n},
+			Amount:  chip,
+			Payout:  int64(models.MaxNumber) - 1,
+		})
+	}
+	return components, nil
+}
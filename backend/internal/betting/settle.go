@@ -0,0 +1,122 @@
+package betting
+
+import (
+	"fmt"
+
+	"casino-backend/internal/models"
+)
+
+// Slip is the set of bets a player places on one spin
+type Slip struct {
+	Bets []Bet `json:"bets"`
+}
+
+// BetResult is the outcome of one bet of a slip
+type BetResult struct {
+	Bet      Bet   `json:"bet"`
+	Won      bool  `json:"won"`
+	Returned int64 `json:"returned"` // Stake plus winnings of the winning placements
+	Net      int64 `json:"net"`      // Returned minus the stake
+}
+
+// SlipResult is the outcome of a whole slip
+type SlipResult struct {
+	Number   models.RouletteNumber `json:"number"`
+	Staked   int64                 `json:"staked"`
+	Returned int64                 `json:"returned"`
+	Net      int64                 `json:"net"`
+	Bets     []BetResult           `json:"bets"`
+}
+
+// Total returns the amount staked on the slip
+func (s Slip) Total() int64 {
+	var total int64
+	for _, bet := range s.Bets {
+		total += bet.Amount
+	}
+	return total
+}
+
+// Validate checks every bet of the slip against the wheel
+func (s Slip) Validate(wheelType models.WheelType) error {
+	if len(s.Bets) == 0 {
+		return fmt.Errorf("%w: slip has no bets", ErrInvalidBet)
+	}
+	for i, bet := range s.Bets {
+		if err := bet.Validate(wheelType); err != nil {
+			return fmt.Errorf("bet %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Settle resolves a slip against the winning number
+func Settle(wheelType models.WheelType, slip Slip, number models.RouletteNumber) (*SlipResult, error) {
+	wheelType = wheelType.OrDefault()
+	if err := models.ValidateNumber(wheelType, number); err != nil {
+		return nil, err
+	}
+	if err := slip.Validate(wheelType); err != nil {
+		return nil, err
+	}
+
+	result := &SlipResult{
+		Number: number,
+		Bets:   make([]BetResult, 0, len(slip.Bets)),
+	}
+	for _, bet := range slip.Bets {
+		betResult, err := SettleBet(wheelType, bet, number)
+		if err != nil {
+			return nil, err
+		}
+		result.Staked += bet.Amount
+		result.Returned += betResult.Returned
+		result.Bets = append(result.Bets, *betResult)
+	}
+	result.Net = result.Returned - result.Staked
+	return result, nil
+}
+
+// SettleBet resolves a single bet against the winning number
+func SettleBet(wheelType models.WheelType, bet Bet, number models.RouletteNumber) (*BetResult, error) {
+	if err := bet.Validate(wheelType); err != nil {
+		return nil, err
+	}
+
+	var components []Component
+	if bet.Type.IsRacetrack() {
+		var err error
+		if components, err = Components(wheelType, bet); err != nil {
+			return nil, err
+		}
+	} else {
+		numbers, err := Coverage(wheelType, bet)
+		if err != nil {
+			return nil, err
+		}
+		payout, err := Payout(wheelType, bet)
+		if err != nil {
+			return nil, err
+		}
+		components = []Component{{Numbers: numbers, Amount: bet.Amount, Payout: payout}}
+	}
+
+	result := &BetResult{Bet: bet}
+	for _, component := range components {
+		if covers(component.Numbers, number) {
+			result.Won = true
+			result.Returned += component.Amount * (component.Payout + 1)
+		}
+	}
+	result.Net = result.Returned - bet.Amount
+	return result, nil
+}
+
+func covers(numbers []models.RouletteNumber, n models.RouletteNumber) bool {
+	for _, number := range numbers {
+		if number == n {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package betting
+
+import "time"
+
+// Wager statuses
+const (
+	StatusPending = "pending"
+	StatusSettled = "settled"
+)
+
+// Wager is a slip placed by a player in a room. It stays pending until the
+// next number is added to the room and is then settled against it.
+type Wager struct {
+	ID         int64       `json:"id"`
+	SessionKey string      `json:"sessionKey"`
+	PlayerID   string      `json:"playerId"`
+	Slip       Slip        `json:"slip"`
+	Staked     int64       `json:"staked"`
+	Status     string      `json:"status"`
+	SpinIndex  int         `json:"spinIndex"` // Index in history of the settling spin, -1 while pending
+	FirstSpin  int         `json:"firstSpin"` // History length when placed; earlier spins never settle the wager
+	Result     *SlipResult `json:"result,omitempty"`
+	CreatedAt  time.Time   `json:"createdAt"`
+	SettledAt  *time.Time  `json:"settledAt,omitempty"`
+}
+
+// NewWager creates a pending wager for a slip
+func NewWager(sessionKey, playerID string, slip Slip) *Wager {
+	return &Wager{
+		SessionKey: sessionKey,
+		PlayerID:   playerID,
+		Slip:       slip,
+		Staked:     slip.Total(),
+		Status:     StatusPending,
+		SpinIndex:  -1,
+		CreatedAt:  time.Now(),
+	}
+}
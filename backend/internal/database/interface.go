@@ -1,6 +1,22 @@
 package database
 
-import "casino-backend/internal/models"
+import (
+	"errors"
+	"time"
+
+	"casino-backend/internal/alerts"
+	"casino-backend/internal/auth"
+	"casino-backend/internal/betting"
+	"casino-backend/internal/models"
+	"casino-backend/internal/provablyfair"
+	"casino-backend/internal/webhooks"
+)
+
+// ErrWheelTypeLocked is returned when the wheel of a session with history is changed
+var ErrWheelTypeLocked = errors.New("wheel type can only change while the history is empty")
+
+// ErrAdminExists is returned when an admin username is taken
+var ErrAdminExists = errors.New("admin already exists")
 
 // RouletteRepositoryInterface defines the interface for roulette data operations
 type RouletteRepositoryInterface interface {
@@ -8,16 +24,74 @@ type RouletteRepositoryInterface interface {
 	GetSession(key string) (*models.RouletteSession, error)
 	CreateSession(key string) (*models.RouletteSession, error)
 	CreateSessionWithPassword(key, password string) (*models.RouletteSession, error)
+	CreateSessionFromRequest(req models.CreateSessionRequest) (*models.RouletteSession, bool, error)
 	ValidateSessionPassword(key, password string) (bool, error)
+	SetSessionPassword(key, password string) (*models.RouletteSession, error)
 	DeleteSession(key string) error
 	GetAllSessions() ([]*models.RouletteSession, error)
 	GetSessionHistorySince(key string, version int) ([]models.RouletteNumber, error)
+	SetSessionWheelType(key string, wheel models.WheelType) (*models.RouletteSession, error)
 
 	// Number operations
 	AddNumberToSession(key string, number models.RouletteNumber) (*models.RouletteSession, error)
 	RemoveNumberFromSession(key string, index int) (*models.RouletteSession, error)
 	UpdateSessionHistory(key string, history []models.RouletteNumber) (*models.RouletteSession, error)
 
+	// Bet operations
+	PlaceWager(wager *betting.Wager) (*betting.Wager, error)
+	GetPendingWagers(key string) ([]*betting.Wager, error)
+	SettleWager(id int64, spinIndex int, result *betting.SlipResult) (*betting.Wager, error)
+	GetSessionWagers(key string) ([]*betting.Wager, error)
+
+	// Bankroll operations
+	GetBankroll(key, playerID string) (*betting.Bankroll, error)
+	OpenBankroll(bankroll *betting.Bankroll, event *betting.BankrollEvent) (bool, error)
+	SettleBankroll(wager *betting.Wager) (*betting.Bankroll, error)
+	GetSessionBankrolls(key string) ([]*betting.Bankroll, error)
+
+	// Provably fair operations
+	GetActiveFairSeed(key string) (*provablyfair.Seed, error)
+	RotateFairSeed(next *provablyfair.Seed) (revealed, active *provablyfair.Seed, err error)
+	AddFairSpin(record *provablyfair.Record) (*models.RouletteSession, error)
+	GetFairSeeds(key string) ([]*provablyfair.Seed, error)
+	GetFairRecords(key string) ([]*provablyfair.Record, error)
+
+	// Alert rule operations
+	CreateAlertRule(rule *alerts.Rule) (*alerts.Rule, error)
+	GetAlertRules(key string) ([]*alerts.Rule, error)
+	DeleteAlertRule(key string, id int64) (bool, error)
+
+	// Webhook operations
+	CreateWebhook(webhook *webhooks.Webhook) (*webhooks.Webhook, error)
+	GetWebhooks() ([]*webhooks.Webhook, error)
+	DeleteWebhook(id int64) (bool, error)
+	SaveWebhookDelivery(delivery *webhooks.Delivery) error
+	GetWebhookDeliveries(webhookID int64, limit int) ([]*webhooks.Delivery, error)
+
+	// Room invite operations
+	CreateInvite(invite *auth.Invite) (*auth.Invite, error)
+	GetInvites(key string) ([]*auth.Invite, error)
+	RevokeInvite(key string, id int64) (bool, error)
+	RedeemInvite(key, codeHash string, now time.Time) (*auth.Invite, error)
+
+	// Room token operations
+	CreateRefreshToken(token *auth.RefreshToken) (*auth.RefreshToken, error)
+	RotateRefreshToken(key, tokenHash, newHash string, now time.Time) (*auth.RefreshToken, error)
+	GetRefreshTokens(key string) ([]*auth.RefreshToken, error)
+	RevokeRefreshToken(key string, id int64, now time.Time) (bool, error)
+	RevokeRefreshTokens(key string, now time.Time) (int64, error)
+	SaveTokenRevocation(revocation *auth.Revocation) (*auth.Revocation, error)
+	GetTokenRevocations(now time.Time) ([]*auth.Revocation, error)
+
+	// Admin account operations
+	CreateAdmin(admin *auth.Admin) (*auth.Admin, error)
+	GetAdmin(username string) (*auth.Admin, error)
+
+	// Dealer marker operations
+	AddDealerMarker(key string, marker models.DealerMarker) error
+	GetDealerMarkers(key string) ([]models.DealerMarker, error)
+	ClampDealerMarkers(key string, length int) error
+
 	// Health and maintenance
 	Ping() error
 	Close() error
@@ -0,0 +1,36 @@
+package database
+
+import (
+	"casino-backend/internal/auth"
+)
+
+// CreateAdmin stores an admin account and assigns its ID
+func (r *MemoryRepository) CreateAdmin(admin *auth.Admin) (*auth.Admin, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.admins[admin.Username]; exists {
+		return nil, ErrAdminExists
+	}
+
+	stored := *admin
+	stored.ID = r.nextAdminID
+	r.nextAdminID++
+	r.admins[stored.Username] = &stored
+
+	created := stored
+	return &created, nil
+}
+
+// GetAdmin returns an admin account by username, or nil if there is none
+func (r *MemoryRepository) GetAdmin(username string) (*auth.Admin, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	admin, exists := r.admins[username]
+	if !exists {
+		return nil, nil
+	}
+	found := *admin
+	return &found, nil
+}
@@ -0,0 +1,61 @@
+package database
+
+import (
+	"fmt"
+
+	"casino-backend/internal/alerts"
+)
+
+// CreateAlertRule stores a rule and assigns its ID
+func (r *MemoryRepository) CreateAlertRule(rule *alerts.Rule) (*alerts.Rule, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.sessions[rule.SessionKey]; !exists {
+		return nil, fmt.Errorf("session with key '%s' not found", rule.SessionKey)
+	}
+
+	stored := copyRule(rule)
+	stored.ID = r.nextRuleID
+	r.nextRuleID++
+	r.alertRules[rule.SessionKey] = append(r.alertRules[rule.SessionKey], stored)
+
+	return copyRule(stored), nil
+}
+
+// GetAlertRules returns the rules of a session in creation order
+func (r *MemoryRepository) GetAlertRules(key string) ([]*alerts.Rule, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	rules := make([]*alerts.Rule, 0, len(r.alertRules[key]))
+	for _, rule := range r.alertRules[key] {
+		rules = append(rules, copyRule(rule))
+	}
+	return rules, nil
+}
+
+// DeleteAlertRule removes a rule of a session, reporting whether it existed
+func (r *MemoryRepository) DeleteAlertRule(key string, id int64) (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	rules := r.alertRules[key]
+	for i, rule := range rules {
+		if rule.ID == id {
+			r.alertRules[key] = append(rules[:i:i], rules[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// copyRule returns a copy that does not share the target number with the stored rule
+func copyRule(rule *alerts.Rule) *alerts.Rule {
+	ruleCopy := *rule
+	if rule.Number != nil {
+		number := *rule.Number
+		ruleCopy.Number = &number
+	}
+	return &ruleCopy
+}
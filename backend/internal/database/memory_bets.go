@@ -0,0 +1,172 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"casino-backend/internal/betting"
+)
+
+// PlaceWager stores a pending wager and assigns its ID. The wager only settles
+// on spins added after the history it was placed against.
+func (r *MemoryRepository) PlaceWager(wager *betting.Wager) (*betting.Wager, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	session, exists := r.sessions[wager.SessionKey]
+	if !exists {
+		return nil, fmt.Errorf("session with key '%s' not found", wager.SessionKey)
+	}
+
+	stored := *wager
+	stored.ID = r.nextWagerID
+	stored.FirstSpin = len(session.History)
+	r.nextWagerID++
+	r.wagers[wager.SessionKey] = append(r.wagers[wager.SessionKey], &stored)
+
+	return copyWager(&stored), nil
+}
+
+// GetPendingWagers returns the unsettled wagers of a session in placement order
+func (r *MemoryRepository) GetPendingWagers(key string) ([]*betting.Wager, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var pending []*betting.Wager
+	for _, wager := range r.wagers[key] {
+		if wager.Status == betting.StatusPending {
+			pending = append(pending, copyWager(wager))
+		}
+	}
+	return pending, nil
+}
+
+// SettleWager records the result of a pending wager
+func (r *MemoryRepository) SettleWager(id int64, spinIndex int, result *betting.SlipResult) (*betting.Wager, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, wagers := range r.wagers {
+		for _, wager := range wagers {
+			if wager.ID != id {
+				continue
+			}
+			if wager.Status != betting.StatusPending {
+				return nil, fmt.Errorf("wager %d is already settled", id)
+			}
+			now := time.Now()
+			resultCopy := *result
+			wager.Status = betting.StatusSettled
+			wager.SpinIndex = spinIndex
+			wager.Result = &resultCopy
+			wager.SettledAt = &now
+			return copyWager(wager), nil
+		}
+	}
+	return nil, fmt.Errorf("wager %d not found", id)
+}
+
+// GetSessionWagers returns every wager of a session in placement order
+func (r *MemoryRepository) GetSessionWagers(key string) ([]*betting.Wager, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	wagers := make([]*betting.Wager, 0, len(r.wagers[key]))
+	for _, wager := range r.wagers[key] {
+		wagers = append(wagers, copyWager(wager))
+	}
+	return wagers, nil
+}
+
+// copyWager returns a copy that does not share the result with the stored wager
+func copyWager(wager *betting.Wager) *betting.Wager {
+	wagerCopy := *wager
+	if wager.Result != nil {
+		resultCopy := *wager.Result
+		wagerCopy.Result = &resultCopy
+	}
+	return &wagerCopy
+}
+
+// GetBankroll returns the bankroll of a player, or nil if none was opened
+func (r *MemoryRepository) GetBankroll(key, playerID string) (*betting.Bankroll, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	bankroll, exists := r.bankrolls[key][playerID]
+	if !exists {
+		return nil, nil
+	}
+	bankrollCopy := *bankroll
+	return &bankrollCopy, nil
+}
+
+// OpenBankroll stores a new bankroll together with its opening event. It
+// reports false, storing nothing, if the player already has a bankroll.
+func (r *MemoryRepository) OpenBankroll(bankroll *betting.Bankroll, event *betting.BankrollEvent) (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.sessions[bankroll.SessionKey]; !exists {
+		return false, fmt.Errorf("session with key '%s' not found", bankroll.SessionKey)
+	}
+	if _, exists := r.bankrolls[bankroll.SessionKey][bankroll.PlayerID]; exists {
+		return false, nil
+	}
+	r.storeBankroll(bankroll, event)
+	return true, nil
+}
+
+// SettleBankroll applies a settled wager to the bankroll of its player in one
+// step, opening a bankroll at zero first if the player has none
+func (r *MemoryRepository) SettleBankroll(wager *betting.Wager) (*betting.Bankroll, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.sessions[wager.SessionKey]; !exists {
+		return nil, fmt.Errorf("session with key '%s' not found", wager.SessionKey)
+	}
+
+	var bankroll *betting.Bankroll
+	if stored, exists := r.bankrolls[wager.SessionKey][wager.PlayerID]; exists {
+		bankrollCopy := *stored
+		bankroll = &bankrollCopy
+	} else {
+		var opened *betting.BankrollEvent
+		bankroll, opened = betting.NewBankroll(wager.SessionKey, wager.PlayerID, 0)
+		r.storeBankroll(bankroll, opened)
+	}
+	r.storeBankroll(bankroll, bankroll.Settle(wager))
+
+	bankrollCopy := *bankroll
+	return &bankrollCopy, nil
+}
+
+// storeBankroll stores a copy of a bankroll and its event; the caller must hold r.mutex
+func (r *MemoryRepository) storeBankroll(bankroll *betting.Bankroll, event *betting.BankrollEvent) {
+	if r.bankrolls[bankroll.SessionKey] == nil {
+		r.bankrolls[bankroll.SessionKey] = make(map[string]*betting.Bankroll)
+	}
+	bankrollCopy := *bankroll
+	r.bankrolls[bankroll.SessionKey][bankroll.PlayerID] = &bankrollCopy
+
+	eventCopy := *event
+	eventCopy.ID = r.nextEventID
+	r.nextEventID++
+	r.bankrollEvents[bankroll.SessionKey] = append(r.bankrollEvents[bankroll.SessionKey], &eventCopy)
+}
+
+// GetSessionBankrolls returns the bankrolls of every player of a session
+func (r *MemoryRepository) GetSessionBankrolls(key string) ([]*betting.Bankroll, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	bankrolls := make([]*betting.Bankroll, 0, len(r.bankrolls[key]))
+	for _, bankroll := range r.bankrolls[key] {
+		bankrollCopy := *bankroll
+		bankrolls = append(bankrolls, &bankrollCopy)
+	}
+	sort.Slice(bankrolls, func(i, j int) bool { return bankrolls[i].PlayerID < bankrolls[j].PlayerID })
+	return bankrolls, nil
+}
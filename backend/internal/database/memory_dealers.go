@@ -0,0 +1,42 @@
+package database
+
+import (
+	"fmt"
+
+	"casino-backend/internal/models"
+)
+
+// AddDealerMarker stores a dealer marker of a session
+func (r *MemoryRepository) AddDealerMarker(key string, marker models.DealerMarker) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.sessions[key]; !exists {
+		return fmt.Errorf("session with key '%s' not found", key)
+	}
+	r.dealerMarkers[key] = append(r.dealerMarkers[key], marker)
+	return nil
+}
+
+// GetDealerMarkers returns the dealer markers of a session in creation order
+func (r *MemoryRepository) GetDealerMarkers(key string) ([]models.DealerMarker, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return append([]models.DealerMarker{}, r.dealerMarkers[key]...), nil
+}
+
+// ClampDealerMarkers moves the markers of a session past the end of a
+// shortened history back to its end
+func (r *MemoryRepository) ClampDealerMarkers(key string, length int) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	markers := r.dealerMarkers[key]
+	for i := range markers {
+		if markers[i].Start > length {
+			markers[i].Start = length
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"casino-backend/internal/models"
+	"casino-backend/internal/provablyfair"
+)
+
+// GetActiveFairSeed returns the unrevealed seed of a session, or nil if there is none
+func (r *MemoryRepository) GetActiveFairSeed(key string) (*provablyfair.Seed, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if seed := r.activeFairSeed(key); seed != nil {
+		return copySeed(seed), nil
+	}
+	return nil, nil
+}
+
+// RotateFairSeed reveals the active seed of the session, if any, and makes next the active one
+func (r *MemoryRepository) RotateFairSeed(next *provablyfair.Seed) (*provablyfair.Seed, *provablyfair.Seed, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.sessions[next.SessionKey]; !exists {
+		return nil, nil, fmt.Errorf("session with key '%s' not found", next.SessionKey)
+	}
+
+	var revealed *provablyfair.Seed
+	if current := r.activeFairSeed(next.SessionKey); current != nil {
+		now := time.Now()
+		current.RevealedAt = &now
+		revealed = copySeed(current)
+	}
+
+	stored := *next
+	stored.ID = r.nextSeedID
+	stored.RevealedAt = nil
+	r.nextSeedID++
+	r.fairSeeds[next.SessionKey] = append(r.fairSeeds[next.SessionKey], &stored)
+
+	return revealed, copySeed(&stored), nil
+}
+
+// AddFairSpin adds the number of a fair spin to the history, stores which seed
+// and nonce produced it and advances the seed nonce, all or nothing. The spin
+// index of the record is set to the position of the number.
+func (r *MemoryRepository) AddFairSpin(record *provablyfair.Record) (*models.RouletteSession, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	session, exists := r.sessions[record.SessionKey]
+	if !exists {
+		return nil, fmt.Errorf("session with key '%s' not found", record.SessionKey)
+	}
+	seed := r.activeFairSeed(record.SessionKey)
+	if seed == nil || seed.ID != record.SeedID {
+		return nil, fmt.Errorf("seed %d is not the active seed of session '%s'", record.SeedID, record.SessionKey)
+	}
+	if seed.Nonce != record.Nonce {
+		return nil, fmt.Errorf("nonce %d of seed %d is already used", record.Nonce, record.SeedID)
+	}
+	if err := models.ValidateNumber(session.WheelType, record.Number); err != nil {
+		return nil, err
+	}
+
+	seed.Nonce++
+	record.SpinIndex = len(session.History)
+	stored := *record
+	r.fairRecords[record.SessionKey] = append(r.fairRecords[record.SessionKey], &stored)
+
+	session.History = append(session.History, record.Number)
+	session.UpdatedAt = time.Now()
+
+	sessionCopy := *session
+	sessionCopy.History = make([]models.RouletteNumber, len(session.History))
+	copy(sessionCopy.History, session.History)
+	return &sessionCopy, nil
+}
+
+// GetFairSeeds returns every seed of a session, secrets included, oldest first
+func (r *MemoryRepository) GetFairSeeds(key string) ([]*provablyfair.Seed, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	seeds := make([]*provablyfair.Seed, 0, len(r.fairSeeds[key]))
+	for _, seed := range r.fairSeeds[key] {
+		seeds = append(seeds, copySeed(seed))
+	}
+	return seeds, nil
+}
+
+// GetFairRecords returns the fair spins of a session in the order they were drawn
+func (r *MemoryRepository) GetFairRecords(key string) ([]*provablyfair.Record, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	records := make([]*provablyfair.Record, 0, len(r.fairRecords[key]))
+	for _, record := range r.fairRecords[key] {
+		recordCopy := *record
+		records = append(records, &recordCopy)
+	}
+	return records, nil
+}
+
+// activeFairSeed returns the stored active seed; the caller must hold the lock
+func (r *MemoryRepository) activeFairSeed(key string) *provablyfair.Seed {
+	seeds := r.fairSeeds[key]
+	if len(seeds) == 0 || seeds[len(seeds)-1].Revealed() {
+		return nil
+	}
+	return seeds[len(seeds)-1]
+}
+
+// copySeed returns a copy that does not share the reveal time with the stored seed
+func copySeed(seed *provablyfair.Seed) *provablyfair.Seed {
+	seedCopy := *seed
+	if seed.RevealedAt != nil {
+		revealedAt := *seed.RevealedAt
+		seedCopy.RevealedAt = &revealedAt
+	}
+	return &seedCopy
+}
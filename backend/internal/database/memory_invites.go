@@ -0,0 +1,89 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"casino-backend/internal/auth"
+)
+
+// CreateInvite stores an invite and assigns its ID
+func (r *MemoryRepository) CreateInvite(invite *auth.Invite) (*auth.Invite, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.sessions[invite.SessionKey]; !exists {
+		return nil, fmt.Errorf("session with key '%s' not found", invite.SessionKey)
+	}
+
+	stored := copyInvite(invite)
+	stored.Code = ""
+	stored.ID = r.nextInviteID
+	r.nextInviteID++
+	r.invites[invite.SessionKey] = append(r.invites[invite.SessionKey], stored)
+
+	created := copyInvite(stored)
+	created.Code = invite.Code
+	return created, nil
+}
+
+// GetInvites returns the invites of a session in creation order, revoked ones included
+func (r *MemoryRepository) GetInvites(key string) ([]*auth.Invite, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	invites := make([]*auth.Invite, 0, len(r.invites[key]))
+	for _, invite := range r.invites[key] {
+		invites = append(invites, copyInvite(invite))
+	}
+	return invites, nil
+}
+
+// RevokeInvite marks an invite of a session as revoked, reporting whether it existed
+func (r *MemoryRepository) RevokeInvite(key string, id int64) (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, invite := range r.invites[key] {
+		if invite.ID == id {
+			if invite.RevokedAt == nil {
+				now := time.Now()
+				invite.RevokedAt = &now
+			}
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// RedeemInvite uses an invite of a session by code hash. It returns nil when
+// there is no such invite or it is revoked, expired or used up.
+func (r *MemoryRepository) RedeemInvite(key, codeHash string, now time.Time) (*auth.Invite, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, invite := range r.invites[key] {
+		if invite.CodeHash == codeHash {
+			if !invite.Usable(now) {
+				return nil, nil
+			}
+			invite.Uses++
+			return copyInvite(invite), nil
+		}
+	}
+	return nil, nil
+}
+
+// copyInvite returns a copy that does not share its times with the stored invite
+func copyInvite(invite *auth.Invite) *auth.Invite {
+	inviteCopy := *invite
+	if invite.ExpiresAt != nil {
+		expiresAt := *invite.ExpiresAt
+		inviteCopy.ExpiresAt = &expiresAt
+	}
+	if invite.RevokedAt != nil {
+		revokedAt := *invite.RevokedAt
+		inviteCopy.RevokedAt = &revokedAt
+	}
+	return &inviteCopy
+}
@@ -1,7 +1,12 @@
 package database
 
 import (
+	"casino-backend/internal/alerts"
+	"casino-backend/internal/auth"
+	"casino-backend/internal/betting"
 	"casino-backend/internal/models"
+	"casino-backend/internal/provablyfair"
+	"casino-backend/internal/webhooks"
 	"fmt"
 	"log"
 	"sync"
@@ -13,14 +18,75 @@ type MemoryRepository struct {
 	sessions map[string]*models.RouletteSession
 	mutex    sync.RWMutex
 	nextID   int
+
+	wagers      map[string][]*betting.Wager
+	nextWagerID int64
+
+	bankrolls      map[string]map[string]*betting.Bankroll
+	bankrollEvents map[string][]*betting.BankrollEvent
+	nextEventID    int64
+
+	fairSeeds   map[string][]*provablyfair.Seed
+	fairRecords map[string][]*provablyfair.Record
+	nextSeedID  int64
+
+	alertRules map[string][]*alerts.Rule
+	nextRuleID int64
+
+	dealerMarkers map[string][]models.DealerMarker
+
+	webhooks       []*webhooks.Webhook
+	deliveries     map[int64][]*webhooks.Delivery
+	nextWebhookID  int64
+	nextDeliveryID int64
+
+	admins      map[string]*auth.Admin
+	nextAdminID int64
+
+	invites      map[string][]*auth.Invite
+	nextInviteID int64
+
+	refreshTokens      map[string][]*auth.RefreshToken
+	nextRefreshTokenID int64
+	revocations        []*auth.Revocation // Kept when their session is deleted, until they expire
+	nextRevocationID   int64
 }
 
 // NewMemoryRepository creates a new in-memory repository
 func NewMemoryRepository() *MemoryRepository {
 	return &MemoryRepository{
-		sessions: make(map[string]*models.RouletteSession),
-		mutex:    sync.RWMutex{},
-		nextID:   1,
+		sessions:    make(map[string]*models.RouletteSession),
+		mutex:       sync.RWMutex{},
+		nextID:      1,
+		wagers:      make(map[string][]*betting.Wager),
+		nextWagerID: 1,
+
+		bankrolls:      make(map[string]map[string]*betting.Bankroll),
+		bankrollEvents: make(map[string][]*betting.BankrollEvent),
+		nextEventID:    1,
+
+		fairSeeds:   make(map[string][]*provablyfair.Seed),
+		fairRecords: make(map[string][]*provablyfair.Record),
+		nextSeedID:  1,
+
+		alertRules: make(map[string][]*alerts.Rule),
+		nextRuleID: 1,
+
+		dealerMarkers: make(map[string][]models.DealerMarker),
+
+		deliveries:     make(map[int64][]*webhooks.Delivery),
+		nextWebhookID:  1,
+		nextDeliveryID: 1,
+
+		admins:      make(map[string]*auth.Admin),
+		nextAdminID: 1,
+
+		invites:      make(map[string][]*auth.Invite),
+		nextInviteID: 1,
+
+		refreshTokens:      make(map[string][]*auth.RefreshToken),
+		nextRefreshTokenID: 1,
+		nextRevocationID:   1,
 	}
 }
 
@@ -49,6 +115,24 @@ func (r *MemoryRepository) CreateSession(key string) (*models.RouletteSession, e
 
 // CreateSessionWithPassword creates a new session with password
 func (r *MemoryRepository) CreateSessionWithPassword(key, password string) (*models.RouletteSession, error) {
+	session, _, err := r.CreateSessionFromRequest(models.CreateSessionRequest{Key: key, Password: password})
+	return session, err
+}
+
+// CreateSessionFromRequest creates a new session with password and wheel type,
+// reporting whether it was inserted
+func (r *MemoryRepository) CreateSessionFromRequest(req models.CreateSessionRequest) (*models.RouletteSession, bool, error) {
+	key, password := req.Key, req.Password
+	wheel := req.WheelType.OrDefault()
+	if !wheel.IsValid() {
+		return nil, false, fmt.Errorf("unknown wheel type %q", req.WheelType)
+	}
+
+	password, err := hashSessionPassword(password)
+	if err != nil {
+		return nil, false, err
+	}
+
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
@@ -59,15 +143,17 @@ func (r *MemoryRepository) CreateSessionWithPassword(key, password string) (*mod
 			existingSession.Password = password
 			existingSession.UpdatedAt = time.Now()
 		}
-		return existingSession, nil
+		return existingSession, false, nil
 	}
 
 	// Create new session
-	log.Printf("[MEMORY_DB] CREATED NEW SESSION. Key: '%s', Password: '%s'", key, password)
+	log.Printf("[MEMORY_DB] CREATED NEW SESSION. Key: '%s', Protected: %t", key, password != "")
 	session := &models.RouletteSession{
 		ID:        r.nextID,
 		Key:       key,
 		Password:  password,
+		WheelType: wheel,
+		Practice:  req.Practice,
 		History:   []models.RouletteNumber{},
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
@@ -76,7 +162,7 @@ func (r *MemoryRepository) CreateSessionWithPassword(key, password string) (*mod
 	r.sessions[key] = session
 	r.nextID++
 
-	return session, nil
+	return session, true, nil
 }
 
 // ValidateSessionPassword validates password for a session
@@ -98,7 +184,55 @@ func (r *MemoryRepository) ValidateSessionPassword(key, password string) (bool,
 	}
 
 	// Проверяем пароль
-	return session.Password == password, nil
+	return auth.CheckPassword(session.Password, password), nil
+}
+
+// SetSessionPassword replaces the password of a session; an empty password removes it
+func (r *MemoryRepository) SetSessionPassword(key, password string) (*models.RouletteSession, error) {
+	hash, err := hashSessionPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	session, exists := r.sessions[key]
+	if !exists {
+		return nil, nil
+	}
+	session.Password = hash
+	session.UpdatedAt = time.Now()
+
+	sessionCopy := *session
+	sessionCopy.History = make([]models.RouletteNumber, len(session.History))
+	copy(sessionCopy.History, session.History)
+	return &sessionCopy, nil
+}
+
+// SetSessionWheelType changes the wheel of a session whose history is still empty
+func (r *MemoryRepository) SetSessionWheelType(key string, wheel models.WheelType) (*models.RouletteSession, error) {
+	if !wheel.IsValid() {
+		return nil, fmt.Errorf("unknown wheel type %q", wheel)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	session, exists := r.sessions[key]
+	if !exists {
+		return nil, fmt.Errorf("session with key '%s' not found", key)
+	}
+	if len(session.History) > 0 && session.WheelType != wheel {
+		return nil, ErrWheelTypeLocked
+	}
+
+	session.WheelType = wheel
+	session.UpdatedAt = time.Now()
+
+	sessionCopy := *session
+	sessionCopy.History = []models.RouletteNumber{}
+	return &sessionCopy, nil
 }
 
 // AddNumberToSession adds a number to a session
@@ -109,17 +243,14 @@ func (r *MemoryRepository) AddNumberToSession(key string, number models.Roulette
 	// Get or create session
 	session, exists := r.sessions[key]
 	if !exists {
-		session = &models.RouletteSession{
-			ID:        r.nextID,
-			Key:       key,
-			History:   []models.RouletteNumber{},
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
-		}
-		r.sessions[key] = session
-		r.nextID++
+		session = r.newSession(key)
 	}
 
+	if err := models.ValidateNumber(session.WheelType, number); err != nil {
+		return nil, err
+	}
+	r.sessions[key] = session
+
 	// Add number to history
 	session.History = append(session.History, number)
 	session.UpdatedAt = time.Now()
@@ -140,20 +271,18 @@ func (r *MemoryRepository) UpdateSessionHistory(key string, history []models.Rou
 	// Get or create session
 	session, exists := r.sessions[key]
 	if !exists {
-		session = &models.RouletteSession{
-			ID:        r.nextID,
-			Key:       key,
-			History:   []models.RouletteNumber{},
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
-		}
-		r.sessions[key] = session
-		r.nextID++
+		session = r.newSession(key)
 	}
 
+	if err := models.ValidateHistory(session.WheelType, history); err != nil {
+		return nil, err
+	}
+	r.sessions[key] = session
+
 	// Update history
 	session.History = make([]models.RouletteNumber, len(history))
 	copy(session.History, history)
+	session.Revision++
 	session.UpdatedAt = time.Now()
 
 	// Return a copy
@@ -164,6 +293,21 @@ func (r *MemoryRepository) UpdateSessionHistory(key string, history []models.Rou
 	return &sessionCopy, nil
 }
 
+// newSession allocates an empty session with the default wheel. The caller
+// must hold the write lock and store the session once it is used.
+func (r *MemoryRepository) newSession(key string) *models.RouletteSession {
+	session := &models.RouletteSession{
+		ID:        r.nextID,
+		Key:       key,
+		WheelType: models.DefaultWheel,
+		History:   []models.RouletteNumber{},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	r.nextID++
+	return session
+}
+
 // GetAllSessions returns all sessions
 func (r *MemoryRepository) GetAllSessions() ([]*models.RouletteSession, error) {
 	r.mutex.RLock()
@@ -187,6 +331,16 @@ func (r *MemoryRepository) DeleteSession(key string) error {
 	defer r.mutex.Unlock()
 
 	delete(r.sessions, key)
+	delete(r.wagers, key)
+	delete(r.bankrolls, key)
+	delete(r.bankrollEvents, key)
+	delete(r.fairSeeds, key)
+	delete(r.fairRecords, key)
+	delete(r.alertRules, key)
+	delete(r.dealerMarkers, key)
+	delete(r.invites, key)
+	delete(r.refreshTokens, key)
+	r.deleteSessionWebhooks(key)
 	return nil
 }
 
@@ -215,6 +369,15 @@ func (r *MemoryRepository) Close() error {
 	
 	// Clear all data
 	r.sessions = make(map[string]*models.RouletteSession)
+	r.wagers = make(map[string][]*betting.Wager)
+	r.bankrolls = make(map[string]map[string]*betting.Bankroll)
+	r.bankrollEvents = make(map[string][]*betting.BankrollEvent)
+	r.fairSeeds = make(map[string][]*provablyfair.Seed)
+	r.fairRecords = make(map[string][]*provablyfair.Record)
+	r.alertRules = make(map[string][]*alerts.Rule)
+	r.dealerMarkers = make(map[string][]models.DealerMarker)
+	r.webhooks = nil
+	r.deliveries = make(map[int64][]*webhooks.Delivery)
 	return nil
 }
 
@@ -278,7 +441,13 @@ func (r *MemoryRepository) RemoveNumberFromSession(key string, index int) (*mode
 
 	// Remove the element at the given index
 	session.History = append(session.History[:index], session.History[index+1:]...)
+	session.Revision++
 	session.UpdatedAt = time.Now()
 
-	return session, nil
+	// Return a copy
+	sessionCopy := *session
+	sessionCopy.History = make([]models.RouletteNumber, len(session.History))
+	copy(sessionCopy.History, session.History)
+
+	return &sessionCopy, nil
 } 
\ No newline at end of file
@@ -0,0 +1,133 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"casino-backend/internal/auth"
+)
+
+// CreateRefreshToken stores a refresh token and assigns its ID
+func (r *MemoryRepository) CreateRefreshToken(token *auth.RefreshToken) (*auth.RefreshToken, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.sessions[token.SessionKey]; !exists {
+		return nil, fmt.Errorf("session with key '%s' not found", token.SessionKey)
+	}
+
+	stored := copyRefreshToken(token)
+	stored.ID = r.nextRefreshTokenID
+	r.nextRefreshTokenID++
+	r.refreshTokens[token.SessionKey] = append(r.refreshTokens[token.SessionKey], stored)
+	return copyRefreshToken(stored), nil
+}
+
+// RotateRefreshToken replaces the hash of a usable refresh token of a session.
+// It returns nil when there is no such token or it is revoked or expired.
+func (r *MemoryRepository) RotateRefreshToken(key, tokenHash, newHash string, now time.Time) (*auth.RefreshToken, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, token := range r.refreshTokens[key] {
+		if token.TokenHash == tokenHash {
+			if !token.Usable(now) {
+				return nil, nil
+			}
+			token.TokenHash = newHash
+			return copyRefreshToken(token), nil
+		}
+	}
+	return nil, nil
+}
+
+// GetRefreshTokens returns the refresh tokens of a session in creation order, revoked ones included
+func (r *MemoryRepository) GetRefreshTokens(key string) ([]*auth.RefreshToken, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	tokens := make([]*auth.RefreshToken, 0, len(r.refreshTokens[key]))
+	for _, token := range r.refreshTokens[key] {
+		tokens = append(tokens, copyRefreshToken(token))
+	}
+	return tokens, nil
+}
+
+// RevokeRefreshToken marks a refresh token of a session as revoked, reporting whether it existed
+func (r *MemoryRepository) RevokeRefreshToken(key string, id int64, now time.Time) (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, token := range r.refreshTokens[key] {
+		if token.ID == id {
+			if token.RevokedAt == nil {
+				token.RevokedAt = &now
+			}
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// RevokeRefreshTokens marks every refresh token of a session as revoked and returns the highest ID
+func (r *MemoryRepository) RevokeRefreshTokens(key string, now time.Time) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var lastID int64
+	for _, token := range r.refreshTokens[key] {
+		if token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+		if token.ID > lastID {
+			lastID = token.ID
+		}
+	}
+	return lastID, nil
+}
+
+// SaveTokenRevocation stores a revocation and drops the expired ones
+func (r *MemoryRepository) SaveTokenRevocation(revocation *auth.Revocation) (*auth.Revocation, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	kept := r.revocations[:0]
+	for _, stored := range r.revocations {
+		if stored.ExpiresAt.After(revocation.CreatedAt) {
+			kept = append(kept, stored)
+		}
+	}
+
+	stored := *revocation
+	stored.ID = r.nextRevocationID
+	r.nextRevocationID++
+	r.revocations = append(kept, &stored)
+
+	saved := stored
+	return &saved, nil
+}
+
+// GetTokenRevocations returns the revocations that have not expired
+func (r *MemoryRepository) GetTokenRevocations(now time.Time) ([]*auth.Revocation, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	revocations := []*auth.Revocation{}
+	for _, revocation := range r.revocations {
+		if revocation.ExpiresAt.After(now) {
+			revocationCopy := *revocation
+			revocations = append(revocations, &revocationCopy)
+		}
+	}
+	return revocations, nil
+}
+
+// copyRefreshToken returns a copy that does not share its revocation time with the stored token
+func copyRefreshToken(token *auth.RefreshToken) *auth.RefreshToken {
+	tokenCopy := *token
+	if token.RevokedAt != nil {
+		revokedAt := *token.RevokedAt
+		tokenCopy.RevokedAt = &revokedAt
+	}
+	return &tokenCopy
+}
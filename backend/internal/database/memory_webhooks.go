@@ -0,0 +1,99 @@
+package database
+
+import (
+	"fmt"
+
+	"casino-backend/internal/webhooks"
+)
+
+// CreateWebhook stores a subscription and assigns its ID
+func (r *MemoryRepository) CreateWebhook(webhook *webhooks.Webhook) (*webhooks.Webhook, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if webhook.SessionKey != "" {
+		if _, exists := r.sessions[webhook.SessionKey]; !exists {
+			return nil, fmt.Errorf("session with key '%s' not found", webhook.SessionKey)
+		}
+	}
+
+	stored := copyWebhook(webhook)
+	stored.ID = r.nextWebhookID
+	r.nextWebhookID++
+	r.webhooks = append(r.webhooks, stored)
+
+	return copyWebhook(stored), nil
+}
+
+// GetWebhooks returns every subscription, secrets included, in creation order
+func (r *MemoryRepository) GetWebhooks() ([]*webhooks.Webhook, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	hooks := make([]*webhooks.Webhook, 0, len(r.webhooks))
+	for _, webhook := range r.webhooks {
+		hooks = append(hooks, copyWebhook(webhook))
+	}
+	return hooks, nil
+}
+
+// DeleteWebhook removes a subscription and its delivery log, reporting whether it existed
+func (r *MemoryRepository) DeleteWebhook(id int64) (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i, webhook := range r.webhooks {
+		if webhook.ID == id {
+			r.webhooks = append(r.webhooks[:i:i], r.webhooks[i+1:]...)
+			delete(r.deliveries, id)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// SaveWebhookDelivery appends an attempt to the delivery log of a webhook
+func (r *MemoryRepository) SaveWebhookDelivery(delivery *webhooks.Delivery) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stored := *delivery
+	stored.ID = r.nextDeliveryID
+	r.nextDeliveryID++
+	r.deliveries[delivery.WebhookID] = append(r.deliveries[delivery.WebhookID], &stored)
+	return nil
+}
+
+// GetWebhookDeliveries returns the latest attempts of a webhook, newest first
+func (r *MemoryRepository) GetWebhookDeliveries(webhookID int64, limit int) ([]*webhooks.Delivery, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	attempts := r.deliveries[webhookID]
+	deliveries := make([]*webhooks.Delivery, 0, len(attempts))
+	for i := len(attempts) - 1; i >= 0 && (limit <= 0 || len(deliveries) < limit); i-- {
+		delivery := *attempts[i]
+		deliveries = append(deliveries, &delivery)
+	}
+	return deliveries, nil
+}
+
+// deleteSessionWebhooks drops the subscriptions of a deleted session; the caller must hold the lock
+func (r *MemoryRepository) deleteSessionWebhooks(key string) {
+	kept := r.webhooks[:0]
+	for _, webhook := range r.webhooks {
+		if webhook.SessionKey == key {
+			delete(r.deliveries, webhook.ID)
+			continue
+		}
+		kept = append(kept, webhook)
+	}
+	r.webhooks = kept
+}
+
+// copyWebhook returns a copy that does not share the event list with the stored webhook
+func copyWebhook(webhook *webhooks.Webhook) *webhooks.Webhook {
+	webhookCopy := *webhook
+	webhookCopy.Events = append([]string(nil), webhook.Events...)
+	return &webhookCopy
+}
@@ -1,12 +1,16 @@
 package database
 
 import (
+	"database/sql"
 	"fmt"
 	"log"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
+
+	"casino-backend/internal/auth"
+	"casino-backend/internal/models"
 )
 
 // Migration represents a single database migration
@@ -15,6 +19,7 @@ type Migration struct {
 	Description string
 	Up          string
 	Down        string
+	UpFunc      func(tx *sql.Tx) error // Data changes run after Up, in the same transaction
 }
 
 // MigrationManager handles database migrations
@@ -87,7 +92,328 @@ func (m *MigrationManager) GetMigrations() []Migration {
 				EXECUTE FUNCTION update_updated_at_column()`,
 			Down: `DROP TRIGGER IF EXISTS update_roulette_sessions_updated_at ON roulette_sessions`,
 		},
+		{
+			Version:     6,
+			Description: "Add history revision to roulette sessions",
+			Up:          `ALTER TABLE roulette_sessions ADD COLUMN IF NOT EXISTS revision INTEGER NOT NULL DEFAULT 0`,
+			Down:        `ALTER TABLE roulette_sessions DROP COLUMN IF EXISTS revision`,
+		},
+		{
+			Version:     7,
+			Description: "Remove invalid roulette numbers",
+			Up:          `UPDATE roulette_numbers SET number = TRIM(number) WHERE number != TRIM(number)`,
+			UpFunc:      removeInvalidNumbers,
+			// Removed rows cannot be restored
+			Down: ``,
+		},
+		{
+			Version:     8,
+			Description: "Add wheel type to sessions",
+			Up: `ALTER TABLE roulette_sessions ADD COLUMN IF NOT EXISTS wheel_type VARCHAR(20) NOT NULL DEFAULT 'european';
+			UPDATE roulette_sessions SET wheel_type = 'american'
+				WHERE id IN (SELECT DISTINCT session_id FROM roulette_numbers WHERE number = '"00"')`,
+			Down: `ALTER TABLE roulette_sessions DROP COLUMN IF EXISTS wheel_type`,
+		},
+		{
+			Version:     9,
+			Description: "Create dealer markers table",
+			Up: `CREATE TABLE IF NOT EXISTS dealer_markers (
+				id BIGSERIAL PRIMARY KEY,
+				session_id INTEGER NOT NULL REFERENCES roulette_sessions(id) ON DELETE CASCADE,
+				dealer VARCHAR(64) NOT NULL,
+				start_index INTEGER NOT NULL,
+				created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+			);
+			CREATE INDEX IF NOT EXISTS idx_dealer_markers_session ON dealer_markers(session_id)`,
+			Down: `DROP TABLE IF EXISTS dealer_markers`,
+		},
+		{
+			Version:     10,
+			Description: "Create bets and bet results tables",
+			Up: `CREATE TABLE IF NOT EXISTS bets (
+				id BIGSERIAL PRIMARY KEY,
+				session_id INTEGER NOT NULL REFERENCES roulette_sessions(id) ON DELETE CASCADE,
+				player_id VARCHAR(64) NOT NULL,
+				slip JSONB NOT NULL,
+				staked BIGINT NOT NULL,
+				first_spin INTEGER NOT NULL,
+				status VARCHAR(20) NOT NULL DEFAULT 'pending',
+				created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+			);
+			CREATE INDEX IF NOT EXISTS idx_bets_session_status ON bets(session_id, status);
+			CREATE TABLE IF NOT EXISTS bet_results (
+				bet_id BIGINT PRIMARY KEY REFERENCES bets(id) ON DELETE CASCADE,
+				spin_index INTEGER NOT NULL,
+				number TEXT NOT NULL,
+				returned BIGINT NOT NULL,
+				net BIGINT NOT NULL,
+				details JSONB NOT NULL,
+				settled_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+			)`,
+			Down: `DROP TABLE IF EXISTS bet_results;
+			DROP TABLE IF EXISTS bets`,
+		},
+		{
+			Version:     11,
+			Description: "Create bankrolls and bankroll events tables",
+			Up: `CREATE TABLE IF NOT EXISTS bankrolls (
+				session_id INTEGER NOT NULL REFERENCES roulette_sessions(id) ON DELETE CASCADE,
+				player_id VARCHAR(64) NOT NULL,
+				starting_balance BIGINT NOT NULL,
+				balance BIGINT NOT NULL,
+				peak BIGINT NOT NULL,
+				max_drawdown BIGINT NOT NULL DEFAULT 0,
+				staked BIGINT NOT NULL DEFAULT 0,
+				returned BIGINT NOT NULL DEFAULT 0,
+				spins INTEGER NOT NULL DEFAULT 0,
+				updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+				PRIMARY KEY (session_id, player_id)
+			);
+			CREATE TABLE IF NOT EXISTS bankroll_events (
+				id BIGSERIAL PRIMARY KEY,
+				session_id INTEGER NOT NULL REFERENCES roulette_sessions(id) ON DELETE CASCADE,
+				player_id VARCHAR(64) NOT NULL,
+				kind VARCHAR(20) NOT NULL,
+				bet_id BIGINT REFERENCES bets(id) ON DELETE SET NULL,
+				delta BIGINT NOT NULL,
+				balance BIGINT NOT NULL,
+				created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+			);
+			CREATE INDEX IF NOT EXISTS idx_bankroll_events_player ON bankroll_events(session_id, player_id);
+			CREATE INDEX IF NOT EXISTS idx_bets_player ON bets(session_id, player_id)`,
+			Down: `DROP INDEX IF EXISTS idx_bets_player;
+			DROP TABLE IF EXISTS bankroll_events;
+			DROP TABLE IF EXISTS bankrolls`,
+		},
+		{
+			Version:     12,
+			Description: "Add practice flag to roulette sessions",
+			Up:          `ALTER TABLE roulette_sessions ADD COLUMN IF NOT EXISTS practice BOOLEAN NOT NULL DEFAULT FALSE`,
+			Down:        `ALTER TABLE roulette_sessions DROP COLUMN IF EXISTS practice`,
+		},
+		{
+			Version:     13,
+			Description: "Create provably fair seeds and spins tables",
+			Up: `CREATE TABLE IF NOT EXISTS fair_seeds (
+				id BIGSERIAL PRIMARY KEY,
+				session_id INTEGER NOT NULL REFERENCES roulette_sessions(id) ON DELETE CASCADE,
+				server_seed VARCHAR(64) NOT NULL,
+				server_seed_hash VARCHAR(64) NOT NULL,
+				client_seed VARCHAR(64) NOT NULL,
+				next_nonce INTEGER NOT NULL DEFAULT 0,
+				created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+				revealed_at TIMESTAMP WITH TIME ZONE
+			);
+			CREATE UNIQUE INDEX IF NOT EXISTS idx_fair_seeds_active ON fair_seeds(session_id) WHERE revealed_at IS NULL;
+			CREATE TABLE IF NOT EXISTS fair_spins (
+				seed_id BIGINT NOT NULL REFERENCES fair_seeds(id) ON DELETE CASCADE,
+				nonce INTEGER NOT NULL,
+				spin_index INTEGER NOT NULL,
+				number TEXT NOT NULL,
+				created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+				PRIMARY KEY (seed_id, nonce)
+			)`,
+			Down: `DROP TABLE IF EXISTS fair_spins;
+			DROP TABLE IF EXISTS fair_seeds`,
+		},
+		{
+			Version:     14,
+			Description: "Create alert rules table",
+			Up: `CREATE TABLE IF NOT EXISTS alert_rules (
+				id BIGSERIAL PRIMARY KEY,
+				session_id INTEGER NOT NULL REFERENCES roulette_sessions(id) ON DELETE CASCADE,
+				kind VARCHAR(20) NOT NULL,
+				number TEXT,
+				category VARCHAR(20) NOT NULL DEFAULT '',
+				group_name VARCHAR(20) NOT NULL DEFAULT '',
+				threshold INTEGER NOT NULL,
+				name VARCHAR(100) NOT NULL DEFAULT '',
+				created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+			);
+			CREATE INDEX IF NOT EXISTS idx_alert_rules_session ON alert_rules(session_id)`,
+			Down: `DROP TABLE IF EXISTS alert_rules`,
+		},
+		{
+			Version:     15,
+			Description: "Create webhooks and webhook deliveries tables",
+			Up: `CREATE TABLE IF NOT EXISTS webhooks (
+				id BIGSERIAL PRIMARY KEY,
+				session_id INTEGER REFERENCES roulette_sessions(id) ON DELETE CASCADE,
+				url TEXT NOT NULL,
+				secret VARCHAR(128) NOT NULL,
+				events TEXT NOT NULL DEFAULT '',
+				created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+			);
+			CREATE TABLE IF NOT EXISTS webhook_deliveries (
+				id BIGSERIAL PRIMARY KEY,
+				webhook_id BIGINT NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+				event_id VARCHAR(64) NOT NULL,
+				event_type VARCHAR(32) NOT NULL,
+				attempt INTEGER NOT NULL,
+				status_code INTEGER NOT NULL DEFAULT 0,
+				error TEXT NOT NULL DEFAULT '',
+				duration_ms BIGINT NOT NULL DEFAULT 0,
+				success BOOLEAN NOT NULL,
+				created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+			);
+			CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook ON webhook_deliveries(webhook_id, id)`,
+			Down: `DROP TABLE IF EXISTS webhook_deliveries;
+			DROP TABLE IF EXISTS webhooks`,
+		},
+		{
+			Version:     16,
+			Description: "Hash room passwords",
+			Up:          `UPDATE roulette_sessions SET password = '' WHERE password IS NULL`,
+			UpFunc:      hashPlaintextPasswords,
+			// Hashes cannot be turned back into passwords
+			Down: ``,
+		},
+		{
+			Version:     17,
+			Description: "Create admins table",
+			Up: `CREATE TABLE IF NOT EXISTS admins (
+				id BIGSERIAL PRIMARY KEY,
+				username VARCHAR(64) UNIQUE NOT NULL,
+				password_hash VARCHAR(255) NOT NULL,
+				created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+			)`,
+			Down: `DROP TABLE IF EXISTS admins`,
+		},
+		{
+			Version:     18,
+			Description: "Create room invites table",
+			Up: `CREATE TABLE IF NOT EXISTS room_invites (
+				id BIGSERIAL PRIMARY KEY,
+				session_id INTEGER NOT NULL REFERENCES roulette_sessions(id) ON DELETE CASCADE,
+				code_hash VARCHAR(64) UNIQUE NOT NULL,
+				role VARCHAR(16) NOT NULL,
+				max_uses INTEGER NOT NULL DEFAULT 0,
+				uses INTEGER NOT NULL DEFAULT 0,
+				expires_at TIMESTAMP WITH TIME ZONE,
+				created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+				revoked_at TIMESTAMP WITH TIME ZONE
+			);
+			CREATE INDEX IF NOT EXISTS idx_room_invites_session ON room_invites(session_id)`,
+			Down: `DROP TABLE IF EXISTS room_invites`,
+		},
+		{
+			Version:     19,
+			Description: "Create room refresh tokens and token revocations tables",
+			Up: `CREATE TABLE IF NOT EXISTS room_refresh_tokens (
+				id BIGSERIAL PRIMARY KEY,
+				session_id INTEGER NOT NULL REFERENCES roulette_sessions(id) ON DELETE CASCADE,
+				token_hash VARCHAR(64) UNIQUE NOT NULL,
+				role VARCHAR(16) NOT NULL,
+				player_id VARCHAR(64) NOT NULL DEFAULT '',
+				expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+				created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+				revoked_at TIMESTAMP WITH TIME ZONE
+			);
+			CREATE INDEX IF NOT EXISTS idx_room_refresh_tokens_session ON room_refresh_tokens(session_id);
+			CREATE TABLE IF NOT EXISTS token_revocations (
+				id BIGSERIAL PRIMARY KEY,
+				session_key VARCHAR(255) NOT NULL,
+				grant_id BIGINT NOT NULL DEFAULT 0,
+				revoke_all BOOLEAN NOT NULL DEFAULT FALSE,
+				expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+				created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+			);
+			CREATE INDEX IF NOT EXISTS idx_token_revocations_expires ON token_revocations(expires_at)`,
+			Down: `DROP TABLE IF EXISTS token_revocations;
+			DROP TABLE IF EXISTS room_refresh_tokens`,
+		},
+	}
+}
+
+// hashPlaintextPasswords replaces the plaintext room passwords with bcrypt hashes
+func hashPlaintextPasswords(tx *sql.Tx) error {
+	rows, err := tx.Query(`SELECT id, password FROM roulette_sessions WHERE password != ''`)
+	if err != nil {
+		return fmt.Errorf("failed to query passwords: %w", err)
+	}
+	plaintext := make(map[int]string)
+	for rows.Next() {
+		var id int
+		var password string
+		if err := rows.Scan(&id, &password); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan password: %w", err)
+		}
+		if !auth.IsHashed(password) {
+			plaintext[id] = password
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to query passwords: %w", err)
+	}
+
+	for id, password := range plaintext {
+		hash, err := auth.HashStoredPassword(password)
+		if err != nil {
+			return fmt.Errorf("failed to hash password of session %d: %w", id, err)
+		}
+		if _, err := tx.Exec(`UPDATE roulette_sessions SET password = $1 WHERE id = $2`, hash, id); err != nil {
+			return fmt.Errorf("failed to update password of session %d: %w", id, err)
+		}
 	}
+	log.Printf("Hashed %d room passwords", len(plaintext))
+	return nil
+}
+
+// removeInvalidNumbers deletes the numbers that are not a pocket of any wheel,
+// which would otherwise make every read of their session fail, and closes the
+// gaps they leave in the positions of the history
+func removeInvalidNumbers(tx *sql.Tx) error {
+	rows, err := tx.Query(`SELECT id, session_id, number FROM roulette_numbers`)
+	if err != nil {
+		return fmt.Errorf("failed to query numbers: %w", err)
+	}
+	invalid := make(map[int]int)
+	for rows.Next() {
+		var id, sessionID int
+		var text string
+		if err := rows.Scan(&id, &sessionID, &text); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan number: %w", err)
+		}
+		var number models.RouletteNumber
+		if err := number.Scan(text); err != nil {
+			log.Printf("Removing number %q of session %d: %v", text, sessionID, err)
+			invalid[id] = sessionID
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to query numbers: %w", err)
+	}
+
+	sessions := make(map[int]bool)
+	for id, sessionID := range invalid {
+		if _, err := tx.Exec(`DELETE FROM roulette_numbers WHERE id = $1`, id); err != nil {
+			return fmt.Errorf("failed to delete number %d: %w", id, err)
+		}
+		sessions[sessionID] = true
+	}
+
+	for sessionID := range sessions {
+		// Renumber through negative positions so that no two rows of the
+		// session share a position on the way
+		renumber := []string{
+			`UPDATE roulette_numbers n SET position = -ranked.pos
+				FROM (SELECT id, ROW_NUMBER() OVER (ORDER BY position) AS pos FROM roulette_numbers WHERE session_id = $1) ranked
+				WHERE n.id = ranked.id`,
+			`UPDATE roulette_numbers SET position = -position - 1 WHERE session_id = $1`,
+			`UPDATE roulette_sessions SET revision = revision + 1 WHERE id = $1`,
+		}
+		for _, query := range renumber {
+			if _, err := tx.Exec(query, sessionID); err != nil {
+				return fmt.Errorf("failed to renumber history of session %d: %w", sessionID, err)
+			}
+		}
+	}
+	log.Printf("Removed %d invalid numbers from %d sessions", len(invalid), len(sessions))
+	return nil
 }
 
 // InitMigrationsTable creates the migrations tracking table
@@ -183,6 +509,11 @@ func (m *MigrationManager) ApplyMigration(migration Migration) error {
 			return fmt.Errorf("failed to execute migration %d statement '%s': %w", migration.Version, stmt, err)
 		}
 	}
+	if migration.UpFunc != nil {
+		if err := migration.UpFunc(tx); err != nil {
+			return fmt.Errorf("failed to execute migration %d: %w", migration.Version, err)
+		}
+	}
 
 	// Record migration as applied
 	executionTime := int(time.Since(startTime).Milliseconds())
@@ -0,0 +1,157 @@
+package database
+
+import (
+	"sync"
+
+	"casino-backend/internal/models"
+	"casino-backend/internal/provablyfair"
+)
+
+// HistoryObserver is notified after a session history has been written
+type HistoryObserver interface {
+	NumberAdded(session *models.RouletteSession, number models.RouletteNumber)
+	NumberRemoved(session *models.RouletteSession, index int)
+	HistoryReplaced(session *models.RouletteSession)
+}
+
+// SessionObserver is notified after a session has been created. Observers
+// registered with AddObserver receive it when they implement it.
+type SessionObserver interface {
+	SessionCreated(session *models.RouletteSession)
+}
+
+// SessionDeleteObserver is notified after a session has been deleted.
+// Observers registered with AddObserver receive it when they implement it.
+type SessionDeleteObserver interface {
+	SessionDeleted(key string)
+}
+
+// ObservedRepository wraps a repository and notifies observers after every
+// successful history write, so that REST handlers, the WebSocket hub and any
+// other writer share the same hooks.
+type ObservedRepository struct {
+	RouletteRepositoryInterface
+
+	mu        sync.RWMutex
+	observers []HistoryObserver
+}
+
+// NewObservedRepository wraps a repository
+func NewObservedRepository(repo RouletteRepositoryInterface) *ObservedRepository {
+	return &ObservedRepository{RouletteRepositoryInterface: repo}
+}
+
+// AddObserver registers an observer for all subsequent writes
+func (r *ObservedRepository) AddObserver(observer HistoryObserver) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.observers = append(r.observers, observer)
+}
+
+// CreateSession creates a session and notifies observers if it is new
+func (r *ObservedRepository) CreateSession(key string) (*models.RouletteSession, error) {
+	session, _, err := r.CreateSessionFromRequest(models.CreateSessionRequest{Key: key})
+	return session, err
+}
+
+// CreateSessionWithPassword creates a session and notifies observers if it is new
+func (r *ObservedRepository) CreateSessionWithPassword(key, password string) (*models.RouletteSession, error) {
+	session, _, err := r.CreateSessionFromRequest(models.CreateSessionRequest{Key: key, Password: password})
+	return session, err
+}
+
+// CreateSessionFromRequest creates a session and notifies observers if it is
+// new. Creating an existing session may only set its password, which is not
+// reported.
+func (r *ObservedRepository) CreateSessionFromRequest(req models.CreateSessionRequest) (*models.RouletteSession, bool, error) {
+	session, created, err := r.RouletteRepositoryInterface.CreateSessionFromRequest(req)
+	if err != nil || !created {
+		return session, created, err
+	}
+	for _, observer := range r.snapshot() {
+		if sessionObserver, ok := observer.(SessionObserver); ok {
+			sessionObserver.SessionCreated(session)
+		}
+	}
+	return session, true, nil
+}
+
+// AddNumberToSession adds a number and notifies observers
+func (r *ObservedRepository) AddNumberToSession(key string, number models.RouletteNumber) (*models.RouletteSession, error) {
+	session, err := r.RouletteRepositoryInterface.AddNumberToSession(key, number)
+	if err != nil {
+		return nil, err
+	}
+	for _, observer := range r.snapshot() {
+		observer.NumberAdded(session, number)
+	}
+	return session, nil
+}
+
+// AddFairSpin adds the number of a fair spin and notifies observers
+func (r *ObservedRepository) AddFairSpin(record *provablyfair.Record) (*models.RouletteSession, error) {
+	session, err := r.RouletteRepositoryInterface.AddFairSpin(record)
+	if err != nil {
+		return nil, err
+	}
+	for _, observer := range r.snapshot() {
+		observer.NumberAdded(session, record.Number)
+	}
+	return session, nil
+}
+
+// RemoveNumberFromSession removes a number and notifies observers
+func (r *ObservedRepository) RemoveNumberFromSession(key string, index int) (*models.RouletteSession, error) {
+	session, err := r.RouletteRepositoryInterface.RemoveNumberFromSession(key, index)
+	if err != nil {
+		return nil, err
+	}
+	for _, observer := range r.snapshot() {
+		observer.NumberRemoved(session, index)
+	}
+	return session, nil
+}
+
+// UpdateSessionHistory replaces the history and notifies observers
+func (r *ObservedRepository) UpdateSessionHistory(key string, history []models.RouletteNumber) (*models.RouletteSession, error) {
+	session, err := r.RouletteRepositoryInterface.UpdateSessionHistory(key, history)
+	if err != nil {
+		return nil, err
+	}
+	for _, observer := range r.snapshot() {
+		observer.HistoryReplaced(session)
+	}
+	return session, nil
+}
+
+// SetSessionWheelType changes the wheel of an empty session. Observers see it
+// as a replaced history, since anything they built for the old wheel is stale.
+func (r *ObservedRepository) SetSessionWheelType(key string, wheel models.WheelType) (*models.RouletteSession, error) {
+	session, err := r.RouletteRepositoryInterface.SetSessionWheelType(key, wheel)
+	if err != nil {
+		return nil, err
+	}
+	for _, observer := range r.snapshot() {
+		observer.HistoryReplaced(session)
+	}
+	return session, nil
+}
+
+// DeleteSession deletes a session and notifies observers
+func (r *ObservedRepository) DeleteSession(key string) error {
+	if err := r.RouletteRepositoryInterface.DeleteSession(key); err != nil {
+		return err
+	}
+	for _, observer := range r.snapshot() {
+		if deleteObserver, ok := observer.(SessionDeleteObserver); ok {
+			deleteObserver.SessionDeleted(key)
+		}
+	}
+	return nil
+}
+
+func (r *ObservedRepository) snapshot() []HistoryObserver {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]HistoryObserver(nil), r.observers...)
+}
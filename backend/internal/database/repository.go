@@ -2,11 +2,11 @@ package database
 
 import (
 	"database/sql"
-	"encoding/json"
 	"fmt"
 	"log"
 	"time"
 
+	"casino-backend/internal/auth"
 	"casino-backend/internal/models"
 )
 
@@ -26,46 +26,70 @@ func (r *RouletteRepository) CreateSession(key string) (*models.RouletteSession,
 
 // CreateSessionWithPassword creates a new roulette session with password
 func (r *RouletteRepository) CreateSessionWithPassword(key, password string) (*models.RouletteSession, error) {
+	session, _, err := r.CreateSessionFromRequest(models.CreateSessionRequest{Key: key, Password: password})
+	return session, err
+}
+
+// CreateSessionFromRequest creates a new roulette session with password and wheel type,
+// reporting whether it was inserted. The wheel type of an existing session is never changed.
+func (r *RouletteRepository) CreateSessionFromRequest(req models.CreateSessionRequest) (*models.RouletteSession, bool, error) {
+	key, password := req.Key, req.Password
+	wheel := req.WheelType.OrDefault()
+	if !wheel.IsValid() {
+		return nil, false, fmt.Errorf("unknown wheel type %q", req.WheelType)
+	}
+	password, err := hashSessionPassword(password)
+	if err != nil {
+		return nil, false, err
+	}
+
 	query := `
-		INSERT INTO roulette_sessions (key, password, created_at, updated_at)
-		VALUES ($1, $2, $3, $3)
+		INSERT INTO roulette_sessions (key, password, wheel_type, practice, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $5)
 		ON CONFLICT (key) DO UPDATE SET
 			password = CASE
 				WHEN roulette_sessions.password = '' AND EXCLUDED.password != '' THEN EXCLUDED.password
 				ELSE roulette_sessions.password
 			END,
 			updated_at = EXCLUDED.updated_at
-		RETURNING id, key, password, created_at, updated_at
+		RETURNING id, key, password, wheel_type, practice, revision, created_at, updated_at, xmax = 0
 	`
 
 	now := time.Now()
 	var session models.RouletteSession
+	var storedPassword sql.NullString
+	var created bool
 
-	err := r.db.QueryRow(query, key, password, now).Scan(
+	err = r.db.QueryRow(query, key, password, wheel, req.Practice, now).Scan(
 		&session.ID,
 		&session.Key,
-		&session.Password,
+		&storedPassword,
+		&session.WheelType,
+		&session.Practice,
+		&session.Revision,
 		&session.CreatedAt,
 		&session.UpdatedAt,
+		&created,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create session: %w", err)
+		return nil, false, fmt.Errorf("failed to create session: %w", err)
 	}
+	session.Password = storedPassword.String
 
-	// Выводим лог, если сессия была только что создана (а не обновлена)
-	// Проверяем, что разница между created_at и updated_at очень маленькая
-	if session.UpdatedAt.Sub(session.CreatedAt) < time.Millisecond*100 {
-		log.Printf("[DB] CREATED NEW SESSION. Key: '%s', Password: '%s'", key, password)
+	// Выводим лог, если сессия была только что создана (а не обновлена).
+	// У вставленной строки xmax равен нулю, у обновленной через ON CONFLICT - нет.
+	if created {
+		log.Printf("[DB] CREATED NEW SESSION. Key: '%s', Protected: %t", key, password != "")
 	}
 
 	// Load existing history
 	history, err := r.getSessionHistory(session.ID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load session history: %w", err)
+		return nil, false, fmt.Errorf("failed to load session history: %w", err)
 	}
 
 	session.History = history
-	return &session, nil
+	return &session, created, nil
 }
 
 // ValidateSessionPassword validates password for a session
@@ -89,13 +113,38 @@ func (r *RouletteRepository) ValidateSessionPassword(key, password string) (bool
 	}
 
 	// Проверяем пароль
-	return storedPassword.String == password, nil
+	return auth.CheckPassword(storedPassword.String, password), nil
+}
+
+// SetSessionPassword replaces the password of a session; an empty password removes it
+func (r *RouletteRepository) SetSessionPassword(key, password string) (*models.RouletteSession, error) {
+	hash, err := hashSessionPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := r.db.Exec(`UPDATE roulette_sessions SET password = $2, updated_at = $3 WHERE key = $1`, key, hash, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to set session password: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return nil, nil
+	}
+	return r.GetSession(key)
+}
+
+// hashSessionPassword hashes a room password, keeping an empty one empty
+func hashSessionPassword(password string) (string, error) {
+	if password == "" {
+		return "", nil
+	}
+	return auth.HashPassword(password)
 }
 
 // GetSession retrieves a session by key
 func (r *RouletteRepository) GetSession(key string) (*models.RouletteSession, error) {
 	query := `
-		SELECT id, key, password, created_at, updated_at
+		SELECT id, key, password, wheel_type, practice, revision, created_at, updated_at
 		FROM roulette_sessions
 		WHERE key = $1
 	`
@@ -106,6 +155,9 @@ func (r *RouletteRepository) GetSession(key string) (*models.RouletteSession, er
 		&session.ID,
 		&session.Key,
 		&password,
+		&session.WheelType,
+		&session.Practice,
+		&session.Revision,
 		&session.CreatedAt,
 		&session.UpdatedAt,
 	)
@@ -130,6 +182,40 @@ func (r *RouletteRepository) GetSession(key string) (*models.RouletteSession, er
 	return &session, nil
 }
 
+// SetSessionWheelType changes the wheel of a session whose history is still empty
+func (r *RouletteRepository) SetSessionWheelType(key string, wheel models.WheelType) (*models.RouletteSession, error) {
+	if !wheel.IsValid() {
+		return nil, fmt.Errorf("unknown wheel type %q", wheel)
+	}
+
+	query := `
+		UPDATE roulette_sessions SET wheel_type = $2, updated_at = $3
+		WHERE key = $1 AND (wheel_type = $2 OR NOT EXISTS (
+			SELECT 1 FROM roulette_numbers WHERE session_id = roulette_sessions.id
+		))
+	`
+	result, err := r.db.Exec(query, key, wheel, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to update wheel type: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check affected rows: %w", err)
+	}
+
+	session, err := r.GetSession(key)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, fmt.Errorf("session with key '%s' not found", key)
+	}
+	if rowsAffected == 0 {
+		return nil, ErrWheelTypeLocked
+	}
+	return session, nil
+}
+
 // GetSessionHistorySince retrieves session history since a given version (position)
 func (r *RouletteRepository) GetSessionHistorySince(key string, version int) ([]models.RouletteNumber, error) {
 	// Сначала получаем ID сессии по ключу
@@ -157,15 +243,10 @@ func (r *RouletteRepository) GetSessionHistorySince(key string, version int) ([]
 
 	var history []models.RouletteNumber
 	for rows.Next() {
-		var numberStr string
-		if err := rows.Scan(&numberStr); err != nil {
+		var number models.RouletteNumber
+		if err := rows.Scan(&number); err != nil {
 			return nil, fmt.Errorf("failed to scan number: %w", err)
 		}
-
-		number, err := stringToNumber(numberStr)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse number string: %w", err)
-		}
 		history = append(history, number)
 	}
 
@@ -187,6 +268,10 @@ func (r *RouletteRepository) AddNumberToSession(key string, number models.Roulet
 		return nil, err
 	}
 
+	if err := models.ValidateNumber(session.WheelType, number); err != nil {
+		return nil, err
+	}
+
 	// Get next position
 	var maxPosition sql.NullInt64
 	posQuery := `SELECT MAX(position) FROM roulette_numbers WHERE session_id = $1`
@@ -200,18 +285,12 @@ func (r *RouletteRepository) AddNumberToSession(key string, number models.Roulet
 		position = int(maxPosition.Int64) + 1
 	}
 
-	// Convert number to string for storage
-	numberStr, err := numberToString(number)
-	if err != nil {
-		return nil, fmt.Errorf("failed to convert number: %w", err)
-	}
-
 	// Insert number
 	insertQuery := `
 		INSERT INTO roulette_numbers (session_id, number, position)
 		VALUES ($1, $2, $3)
 	`
-	_, err = tx.Exec(insertQuery, session.ID, numberStr, position)
+	_, err = tx.Exec(insertQuery, session.ID, number, position)
 	if err != nil {
 		return nil, fmt.Errorf("failed to insert number: %w", err)
 	}
@@ -276,8 +355,9 @@ func (r *RouletteRepository) RemoveNumberFromSession(key string, index int) (*mo
 		return nil, fmt.Errorf("failed to update positions after deletion: %w", err)
 	}
 
-	// Update session timestamp
-	_, err = tx.Exec(`UPDATE roulette_sessions SET updated_at = $1 WHERE id = $2`, time.Now(), sessionID)
+	// Update session timestamp; the numbers after the index have shifted, so
+	// the history gets a new revision
+	_, err = tx.Exec(`UPDATE roulette_sessions SET updated_at = $1, revision = revision + 1 WHERE id = $2`, time.Now(), sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update session timestamp: %w", err)
 	}
@@ -304,6 +384,10 @@ func (r *RouletteRepository) UpdateSessionHistory(key string, history []models.R
 		return nil, err
 	}
 
+	if err := models.ValidateHistory(session.WheelType, history); err != nil {
+		return nil, err
+	}
+
 	// Delete existing numbers
 	deleteQuery := `DELETE FROM roulette_numbers WHERE session_id = $1`
 	_, err = tx.Exec(deleteQuery, session.ID)
@@ -313,23 +397,18 @@ func (r *RouletteRepository) UpdateSessionHistory(key string, history []models.R
 
 	// Insert new history
 	for i, number := range history {
-		numberStr, err := numberToString(number)
-		if err != nil {
-			return nil, fmt.Errorf("failed to convert number at position %d: %w", i, err)
-		}
-
 		insertQuery := `
 			INSERT INTO roulette_numbers (session_id, number, position)
 			VALUES ($1, $2, $3)
 		`
-		_, err = tx.Exec(insertQuery, session.ID, numberStr, i)
+		_, err = tx.Exec(insertQuery, session.ID, number, i)
 		if err != nil {
 			return nil, fmt.Errorf("failed to insert number at position %d: %w", i, err)
 		}
 	}
 
-	// Update session timestamp
-	updateQuery := `UPDATE roulette_sessions SET updated_at = $1 WHERE id = $2`
+	// Update session timestamp and start a new revision of the history
+	updateQuery := `UPDATE roulette_sessions SET updated_at = $1, revision = revision + 1 WHERE id = $2`
 	_, err = tx.Exec(updateQuery, time.Now(), session.ID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update session: %w", err)
@@ -347,7 +426,7 @@ func (r *RouletteRepository) UpdateSessionHistory(key string, history []models.R
 // GetAllSessions retrieves all sessions
 func (r *RouletteRepository) GetAllSessions() ([]*models.RouletteSession, error) {
 	query := `
-		SELECT id, key, created_at, updated_at
+		SELECT id, key, wheel_type, practice, revision, created_at, updated_at
 		FROM roulette_sessions
 		ORDER BY updated_at DESC
 	`
@@ -364,6 +443,9 @@ func (r *RouletteRepository) GetAllSessions() ([]*models.RouletteSession, error)
 		err := rows.Scan(
 			&session.ID,
 			&session.Key,
+			&session.WheelType,
+			&session.Practice,
+			&session.Revision,
 			&session.CreatedAt,
 			&session.UpdatedAt,
 		)
@@ -425,17 +507,12 @@ func (r *RouletteRepository) getSessionHistory(sessionID int) ([]models.Roulette
 
 	var history []models.RouletteNumber
 	for rows.Next() {
-		var numberStr string
-		err := rows.Scan(&numberStr)
+		var number models.RouletteNumber
+		err := rows.Scan(&number)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan number: %w", err)
 		}
 
-		number, err := stringToNumber(numberStr)
-		if err != nil {
-			return nil, fmt.Errorf("failed to convert number: %w", err)
-		}
-
 		history = append(history, number)
 	}
 
@@ -446,22 +523,6 @@ func (r *RouletteRepository) getSessionHistory(sessionID int) ([]models.Roulette
 	return history, nil
 }
 
-// Helper function to convert RouletteNumber to string
-func numberToString(number models.RouletteNumber) (string, error) {
-	data, err := json.Marshal(number)
-	if err != nil {
-		return "", err
-	}
-	return string(data), nil
-}
-
-// Helper function to convert string to RouletteNumber
-func stringToNumber(str string) (models.RouletteNumber, error) {
-	var number models.RouletteNumber
-	err := json.Unmarshal([]byte(str), &number)
-	return number, err
-}
-
 // Ping checks database connectivity
 func (r *RouletteRepository) Ping() error {
 	return r.db.Ping()
@@ -0,0 +1,42 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"casino-backend/internal/auth"
+)
+
+// CreateAdmin stores an admin account and assigns its ID
+func (r *RouletteRepository) CreateAdmin(admin *auth.Admin) (*auth.Admin, error) {
+	query := `
+		INSERT INTO admins (username, password_hash, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (username) DO NOTHING
+		RETURNING id
+	`
+	stored := *admin
+	err := r.db.QueryRow(query, admin.Username, admin.PasswordHash, admin.CreatedAt).Scan(&stored.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrAdminExists
+		}
+		return nil, fmt.Errorf("failed to insert admin: %w", err)
+	}
+	return &stored, nil
+}
+
+// GetAdmin returns an admin account by username, or nil if there is none
+func (r *RouletteRepository) GetAdmin(username string) (*auth.Admin, error) {
+	query := `SELECT id, username, password_hash, created_at FROM admins WHERE username = $1`
+
+	var admin auth.Admin
+	err := r.db.QueryRow(query, username).Scan(&admin.ID, &admin.Username, &admin.PasswordHash, &admin.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get admin: %w", err)
+	}
+	return &admin, nil
+}
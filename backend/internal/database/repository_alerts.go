@@ -0,0 +1,87 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"casino-backend/internal/alerts"
+	"casino-backend/internal/models"
+)
+
+// CreateAlertRule stores a rule and assigns its ID
+func (r *RouletteRepository) CreateAlertRule(rule *alerts.Rule) (*alerts.Rule, error) {
+	query := `
+		INSERT INTO alert_rules (session_id, kind, number, category, group_name, threshold, name, created_at)
+		SELECT id, $2, $3, $4, $5, $6, $7, $8 FROM roulette_sessions WHERE key = $1
+		RETURNING id
+	`
+
+	var number interface{}
+	if rule.Number != nil {
+		number = *rule.Number
+	}
+
+	stored := *rule
+	err := r.db.QueryRow(query, rule.SessionKey, rule.Kind, number, rule.Category, rule.Group, rule.Threshold, rule.Name, rule.CreatedAt).Scan(&stored.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("session with key '%s' not found", rule.SessionKey)
+		}
+		return nil, fmt.Errorf("failed to insert alert rule: %w", err)
+	}
+	return &stored, nil
+}
+
+// GetAlertRules returns the rules of a session in creation order
+func (r *RouletteRepository) GetAlertRules(key string) ([]*alerts.Rule, error) {
+	query := `
+		SELECT a.id, s.key, a.kind, a.number, a.category, a.group_name, a.threshold, a.name, a.created_at
+		FROM alert_rules a
+		JOIN roulette_sessions s ON s.id = a.session_id
+		WHERE s.key = $1
+		ORDER BY a.id ASC
+	`
+	rows, err := r.db.Query(query, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alert rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []*alerts.Rule{}
+	for rows.Next() {
+		var (
+			rule   alerts.Rule
+			number sql.NullString
+		)
+		err := rows.Scan(&rule.ID, &rule.SessionKey, &rule.Kind, &number, &rule.Category, &rule.Group, &rule.Threshold, &rule.Name, &rule.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan alert rule: %w", err)
+		}
+		if number.Valid {
+			var n models.RouletteNumber
+			if err := n.Scan(number.String); err != nil {
+				return nil, fmt.Errorf("failed to decode number of alert rule %d: %w", rule.ID, err)
+			}
+			rule.Number = &n
+		}
+		rules = append(rules, &rule)
+	}
+	return rules, rows.Err()
+}
+
+// DeleteAlertRule removes a rule of a session, reporting whether it existed
+func (r *RouletteRepository) DeleteAlertRule(key string, id int64) (bool, error) {
+	query := `
+		DELETE FROM alert_rules
+		WHERE id = $1 AND session_id = (SELECT id FROM roulette_sessions WHERE key = $2)
+	`
+	res, err := r.db.Exec(query, id, key)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete alert rule %d: %w", id, err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check affected rows: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
@@ -0,0 +1,384 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"casino-backend/internal/betting"
+)
+
+// wagerColumns is the column list read by scanWager
+const wagerColumns = `
+	b.id, s.key, b.player_id, b.slip, b.staked, b.status, b.first_spin, b.created_at,
+	r.spin_index, r.details, r.settled_at`
+
+// wagerJoins joins a bet with its session and optional result
+const wagerJoins = `
+	FROM bets b
+	JOIN roulette_sessions s ON s.id = b.session_id
+	LEFT JOIN bet_results r ON r.bet_id = b.id`
+
+// PlaceWager stores a pending wager and assigns its ID. The wager only settles
+// on spins added after the history it was placed against.
+func (r *RouletteRepository) PlaceWager(wager *betting.Wager) (*betting.Wager, error) {
+	slip, err := json.Marshal(wager.Slip)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode bet slip: %w", err)
+	}
+
+	query := `
+		INSERT INTO bets (session_id, player_id, slip, staked, status, first_spin, created_at)
+		SELECT s.id, $2, $3, $4, $5,
+			(SELECT COUNT(*) FROM roulette_numbers n WHERE n.session_id = s.id), $6
+		FROM roulette_sessions s WHERE s.key = $1
+		RETURNING id, first_spin
+	`
+
+	stored := *wager
+	err = r.db.QueryRow(query, wager.SessionKey, wager.PlayerID, slip, wager.Staked, betting.StatusPending, wager.CreatedAt).Scan(&stored.ID, &stored.FirstSpin)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("session with key '%s' not found", wager.SessionKey)
+		}
+		return nil, fmt.Errorf("failed to insert bet: %w", err)
+	}
+	stored.Status = betting.StatusPending
+	return &stored, nil
+}
+
+// GetPendingWagers returns the unsettled wagers of a session in placement order
+func (r *RouletteRepository) GetPendingWagers(key string) ([]*betting.Wager, error) {
+	query := `SELECT` + wagerColumns + wagerJoins + `
+		WHERE s.key = $1 AND b.status = $2
+		ORDER BY b.id ASC
+	`
+	return r.queryWagers(query, key, betting.StatusPending)
+}
+
+// SettleWager records the result of a pending wager
+func (r *RouletteRepository) SettleWager(id int64, spinIndex int, result *betting.SlipResult) (*betting.Wager, error) {
+	details, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode bet result: %w", err)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`UPDATE bets SET status = $1 WHERE id = $2 AND status = $3`, betting.StatusSettled, id, betting.StatusPending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update bet %d: %w", id, err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return nil, fmt.Errorf("wager %d not found or already settled", id)
+	}
+
+	insertQuery := `
+		INSERT INTO bet_results (bet_id, spin_index, number, returned, net, details, settled_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err = tx.Exec(insertQuery, id, spinIndex, result.Number, result.Returned, result.Net, details, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert bet result: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	wagers, err := r.queryWagers(`SELECT`+wagerColumns+wagerJoins+` WHERE b.id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(wagers) == 0 {
+		return nil, fmt.Errorf("wager %d not found", id)
+	}
+	return wagers[0], nil
+}
+
+// GetSessionWagers returns every wager of a session in placement order
+func (r *RouletteRepository) GetSessionWagers(key string) ([]*betting.Wager, error) {
+	query := `SELECT` + wagerColumns + wagerJoins + `
+		WHERE s.key = $1
+		ORDER BY b.id ASC
+	`
+	return r.queryWagers(query, key)
+}
+
+func (r *RouletteRepository) queryWagers(query string, args ...interface{}) ([]*betting.Wager, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bets: %w", err)
+	}
+	defer rows.Close()
+
+	wagers := []*betting.Wager{}
+	for rows.Next() {
+		wager, err := scanWager(rows)
+		if err != nil {
+			return nil, err
+		}
+		wagers = append(wagers, wager)
+	}
+	return wagers, rows.Err()
+}
+
+func scanWager(rows *sql.Rows) (*betting.Wager, error) {
+	var (
+		wager     betting.Wager
+		slip      []byte
+		spinIndex sql.NullInt64
+		details   []byte
+		settledAt sql.NullTime
+	)
+	err := rows.Scan(
+		&wager.ID,
+		&wager.SessionKey,
+		&wager.PlayerID,
+		&slip,
+		&wager.Staked,
+		&wager.Status,
+		&wager.FirstSpin,
+		&wager.CreatedAt,
+		&spinIndex,
+		&details,
+		&settledAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan bet: %w", err)
+	}
+
+	if err := json.Unmarshal(slip, &wager.Slip); err != nil {
+		return nil, fmt.Errorf("failed to decode bet slip %d: %w", wager.ID, err)
+	}
+
+	wager.SpinIndex = -1
+	if spinIndex.Valid {
+		wager.SpinIndex = int(spinIndex.Int64)
+	}
+	if details != nil {
+		var result betting.SlipResult
+		if err := json.Unmarshal(details, &result); err != nil {
+			return nil, fmt.Errorf("failed to decode bet result %d: %w", wager.ID, err)
+		}
+		wager.Result = &result
+	}
+	if settledAt.Valid {
+		wager.SettledAt = &settledAt.Time
+	}
+	return &wager, nil
+}
+
+// bankrollColumns is the column list read by scanBankroll
+const bankrollColumns = `
+	s.key, b.player_id, b.starting_balance, b.balance, b.peak, b.max_drawdown,
+	b.staked, b.returned, b.spins, b.updated_at
+	FROM bankrolls b
+	JOIN roulette_sessions s ON s.id = b.session_id`
+
+// GetBankroll returns the bankroll of a player, or nil if none was opened
+func (r *RouletteRepository) GetBankroll(key, playerID string) (*betting.Bankroll, error) {
+	rows, err := r.db.Query(`SELECT`+bankrollColumns+` WHERE s.key = $1 AND b.player_id = $2`, key, playerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bankroll: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+	return scanBankroll(rows)
+}
+
+// OpenBankroll stores a new bankroll together with its opening event. It
+// reports false, storing nothing, if the player already has a bankroll.
+func (r *RouletteRepository) OpenBankroll(bankroll *betting.Bankroll, event *betting.BankrollEvent) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	sessionID, err := bankrollSessionID(tx, bankroll.SessionKey)
+	if err != nil {
+		return false, err
+	}
+	opened, err := insertBankroll(tx, sessionID, bankroll)
+	if err != nil || !opened {
+		return false, err
+	}
+	if err := insertBankrollEvent(tx, sessionID, event); err != nil {
+		return false, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return true, nil
+}
+
+// SettleBankroll applies a settled wager to the bankroll of its player in one
+// transaction, opening a bankroll at zero first if the player has none. The
+// bankroll row stays locked from the read to the update, so concurrent
+// settlements and openings never overwrite each other.
+func (r *RouletteRepository) SettleBankroll(wager *betting.Wager) (*betting.Bankroll, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	sessionID, err := bankrollSessionID(tx, wager.SessionKey)
+	if err != nil {
+		return nil, err
+	}
+	empty, opened := betting.NewBankroll(wager.SessionKey, wager.PlayerID, 0)
+	inserted, err := insertBankroll(tx, sessionID, empty)
+	if err != nil {
+		return nil, err
+	}
+	if inserted {
+		if err := insertBankrollEvent(tx, sessionID, opened); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := tx.Query(`SELECT`+bankrollColumns+` WHERE b.session_id = $1 AND b.player_id = $2 FOR UPDATE OF b`,
+		sessionID, wager.PlayerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock bankroll: %w", err)
+	}
+	if !rows.Next() {
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to lock bankroll: %w", err)
+		}
+		return nil, fmt.Errorf("bankroll of player %s not found", wager.PlayerID)
+	}
+	bankroll, err := scanBankroll(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	event := bankroll.Settle(wager)
+	updateQuery := `
+		UPDATE bankrolls
+		SET balance = $3, peak = $4, max_drawdown = $5, staked = $6, returned = $7, spins = $8, updated_at = $9
+		WHERE session_id = $1 AND player_id = $2
+	`
+	_, err = tx.Exec(updateQuery, sessionID, bankroll.PlayerID, bankroll.Balance, bankroll.Peak, bankroll.MaxDrawdown,
+		bankroll.Staked, bankroll.Returned, bankroll.Spins, bankroll.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update bankroll: %w", err)
+	}
+	if err := insertBankrollEvent(tx, sessionID, event); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return bankroll, nil
+}
+
+// bankrollSessionID returns the ID of the session a bankroll belongs to
+func bankrollSessionID(tx *sql.Tx, key string) (int, error) {
+	var sessionID int
+	err := tx.QueryRow(`SELECT id FROM roulette_sessions WHERE key = $1`, key).Scan(&sessionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("session with key '%s' not found", key)
+		}
+		return 0, fmt.Errorf("failed to get session ID: %w", err)
+	}
+	return sessionID, nil
+}
+
+// insertBankroll inserts a bankroll unless the player already has one,
+// reporting whether it did
+func insertBankroll(tx *sql.Tx, sessionID int, bankroll *betting.Bankroll) (bool, error) {
+	insertQuery := `
+		INSERT INTO bankrolls (session_id, player_id, starting_balance, balance, peak, max_drawdown, staked, returned, spins, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (session_id, player_id) DO NOTHING
+	`
+	result, err := tx.Exec(insertQuery, sessionID, bankroll.PlayerID, bankroll.StartingBalance, bankroll.Balance,
+		bankroll.Peak, bankroll.MaxDrawdown, bankroll.Staked, bankroll.Returned, bankroll.Spins, bankroll.UpdatedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to insert bankroll: %w", err)
+	}
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return inserted == 1, nil
+}
+
+// insertBankrollEvent records a change of a bankroll
+func insertBankrollEvent(tx *sql.Tx, sessionID int, event *betting.BankrollEvent) error {
+	var wagerID sql.NullInt64
+	if event.WagerID != 0 {
+		wagerID = sql.NullInt64{Int64: event.WagerID, Valid: true}
+	}
+	eventQuery := `
+		INSERT INTO bankroll_events (session_id, player_id, kind, bet_id, delta, balance, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := tx.Exec(eventQuery, sessionID, event.PlayerID, event.Kind, wagerID, event.Delta, event.Balance, event.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert bankroll event: %w", err)
+	}
+	return nil
+}
+
+// GetSessionBankrolls returns the bankrolls of every player of a session
+func (r *RouletteRepository) GetSessionBankrolls(key string) ([]*betting.Bankroll, error) {
+	rows, err := r.db.Query(`SELECT`+bankrollColumns+` WHERE s.key = $1 ORDER BY b.player_id`, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bankrolls: %w", err)
+	}
+	defer rows.Close()
+
+	bankrolls := []*betting.Bankroll{}
+	for rows.Next() {
+		bankroll, err := scanBankroll(rows)
+		if err != nil {
+			return nil, err
+		}
+		bankrolls = append(bankrolls, bankroll)
+	}
+	return bankrolls, rows.Err()
+}
+
+func scanBankroll(rows *sql.Rows) (*betting.Bankroll, error) {
+	var bankroll betting.Bankroll
+	err := rows.Scan(
+		&bankroll.SessionKey,
+		&bankroll.PlayerID,
+		&bankroll.StartingBalance,
+		&bankroll.Balance,
+		&bankroll.Peak,
+		&bankroll.MaxDrawdown,
+		&bankroll.Staked,
+		&bankroll.Returned,
+		&bankroll.Spins,
+		&bankroll.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan bankroll: %w", err)
+	}
+	if bankroll.Staked > 0 {
+		bankroll.ROI = float64(bankroll.Returned-bankroll.Staked) / float64(bankroll.Staked)
+	}
+	return &bankroll, nil
+}
@@ -0,0 +1,67 @@
+package database
+
+import (
+	"fmt"
+
+	"casino-backend/internal/models"
+)
+
+// AddDealerMarker stores a dealer marker of a session
+func (r *RouletteRepository) AddDealerMarker(key string, marker models.DealerMarker) error {
+	query := `
+		INSERT INTO dealer_markers (session_id, dealer, start_index)
+		SELECT id, $2, $3 FROM roulette_sessions WHERE key = $1
+	`
+	result, err := r.db.Exec(query, key, marker.Dealer, marker.Start)
+	if err != nil {
+		return fmt.Errorf("failed to insert dealer marker: %w", err)
+	}
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if inserted == 0 {
+		return fmt.Errorf("session with key '%s' not found", key)
+	}
+	return nil
+}
+
+// GetDealerMarkers returns the dealer markers of a session in creation order
+func (r *RouletteRepository) GetDealerMarkers(key string) ([]models.DealerMarker, error) {
+	query := `
+		SELECT m.dealer, m.start_index
+		FROM dealer_markers m
+		JOIN roulette_sessions s ON s.id = m.session_id
+		WHERE s.key = $1
+		ORDER BY m.id ASC
+	`
+	rows, err := r.db.Query(query, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dealer markers: %w", err)
+	}
+	defer rows.Close()
+
+	markers := []models.DealerMarker{}
+	for rows.Next() {
+		var marker models.DealerMarker
+		if err := rows.Scan(&marker.Dealer, &marker.Start); err != nil {
+			return nil, fmt.Errorf("failed to scan dealer marker: %w", err)
+		}
+		markers = append(markers, marker)
+	}
+	return markers, rows.Err()
+}
+
+// ClampDealerMarkers moves the markers of a session past the end of a
+// shortened history back to its end
+func (r *RouletteRepository) ClampDealerMarkers(key string, length int) error {
+	query := `
+		UPDATE dealer_markers m SET start_index = $2
+		FROM roulette_sessions s
+		WHERE s.id = m.session_id AND s.key = $1 AND m.start_index > $2
+	`
+	if _, err := r.db.Exec(query, key, length); err != nil {
+		return fmt.Errorf("failed to clamp dealer markers: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"casino-backend/internal/models"
+	"casino-backend/internal/provablyfair"
+)
+
+// seedColumns is the column list read by scanSeed
+const seedColumns = `
+	f.id, s.key, f.server_seed, f.server_seed_hash, f.client_seed, f.next_nonce,
+	f.created_at, f.revealed_at
+	FROM fair_seeds f
+	JOIN roulette_sessions s ON s.id = f.session_id`
+
+// GetActiveFairSeed returns the unrevealed seed of a session, or nil if there is none
+func (r *RouletteRepository) GetActiveFairSeed(key string) (*provablyfair.Seed, error) {
+	seeds, err := r.querySeeds(`SELECT`+seedColumns+` WHERE s.key = $1 AND f.revealed_at IS NULL`, key)
+	if err != nil || len(seeds) == 0 {
+		return nil, err
+	}
+	return seeds[0], nil
+}
+
+// RotateFairSeed reveals the active seed of the session, if any, and makes next the active one
+func (r *RouletteRepository) RotateFairSeed(next *provablyfair.Seed) (*provablyfair.Seed, *provablyfair.Seed, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var sessionID int
+	err = tx.QueryRow(`SELECT id FROM roulette_sessions WHERE key = $1 FOR UPDATE`, next.SessionKey).Scan(&sessionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil, fmt.Errorf("session with key '%s' not found", next.SessionKey)
+		}
+		return nil, nil, fmt.Errorf("failed to lock session: %w", err)
+	}
+
+	now := time.Now()
+	var revealedID int64
+	err = tx.QueryRow(`UPDATE fair_seeds SET revealed_at = $1 WHERE session_id = $2 AND revealed_at IS NULL RETURNING id`,
+		now, sessionID).Scan(&revealedID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, nil, fmt.Errorf("failed to reveal seed: %w", err)
+	}
+
+	insertQuery := `
+		INSERT INTO fair_seeds (session_id, server_seed, server_seed_hash, client_seed, next_nonce, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+	var activeID int64
+	err = tx.QueryRow(insertQuery, sessionID, next.ServerSeed, next.ServerSeedHash, next.ClientSeed, next.Nonce, next.CreatedAt).Scan(&activeID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to insert seed: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	seeds, err := r.querySeeds(`SELECT`+seedColumns+` WHERE f.id IN ($1, $2) ORDER BY f.id ASC`, revealedID, activeID)
+	if err != nil {
+		return nil, nil, err
+	}
+	var revealed, active *provablyfair.Seed
+	for _, seed := range seeds {
+		if seed.ID == activeID {
+			active = seed
+		} else {
+			revealed = seed
+		}
+	}
+	if active == nil {
+		return nil, nil, fmt.Errorf("seed %d not found", activeID)
+	}
+	return revealed, active, nil
+}
+
+// AddFairSpin adds the number of a fair spin to the history, stores which seed
+// and nonce produced it and advances the seed nonce in one transaction. The
+// spin index of the record is set to the position of the number.
+func (r *RouletteRepository) AddFairSpin(record *provablyfair.Record) (*models.RouletteSession, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Claiming the nonce first locks the seed row, so concurrent spins of the
+	// same seed wait here instead of racing for a position
+	var sessionID int
+	err = tx.QueryRow(`UPDATE fair_seeds SET next_nonce = next_nonce + 1
+		WHERE id = $1 AND next_nonce = $2 AND revealed_at IS NULL
+		RETURNING session_id`, record.SeedID, record.Nonce).Scan(&sessionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("nonce %d of seed %d is already used or the seed is revealed", record.Nonce, record.SeedID)
+		}
+		return nil, fmt.Errorf("failed to update seed %d: %w", record.SeedID, err)
+	}
+
+	var maxPosition sql.NullInt64
+	err = tx.QueryRow(`SELECT MAX(position) FROM roulette_numbers WHERE session_id = $1`, sessionID).Scan(&maxPosition)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get max position: %w", err)
+	}
+	position := 0
+	if maxPosition.Valid {
+		position = int(maxPosition.Int64) + 1
+	}
+	record.SpinIndex = position
+
+	_, err = tx.Exec(`INSERT INTO roulette_numbers (session_id, number, position) VALUES ($1, $2, $3)`, sessionID, record.Number, position)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert number: %w", err)
+	}
+	_, err = tx.Exec(`UPDATE roulette_sessions SET updated_at = $1 WHERE id = $2`, time.Now(), sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update session: %w", err)
+	}
+
+	insertQuery := `
+		INSERT INTO fair_spins (seed_id, nonce, spin_index, number, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	_, err = tx.Exec(insertQuery, record.SeedID, record.Nonce, record.SpinIndex, record.Number, record.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert fair spin: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return r.GetSession(record.SessionKey)
+}
+
+// GetFairSeeds returns every seed of a session, secrets included, oldest first
+func (r *RouletteRepository) GetFairSeeds(key string) ([]*provablyfair.Seed, error) {
+	return r.querySeeds(`SELECT`+seedColumns+` WHERE s.key = $1 ORDER BY f.id ASC`, key)
+}
+
+// GetFairRecords returns the fair spins of a session in the order they were drawn
+func (r *RouletteRepository) GetFairRecords(key string) ([]*provablyfair.Record, error) {
+	query := `
+		SELECT p.seed_id, s.key, p.nonce, p.spin_index, p.number, p.created_at
+		FROM fair_spins p
+		JOIN fair_seeds f ON f.id = p.seed_id
+		JOIN roulette_sessions s ON s.id = f.session_id
+		WHERE s.key = $1
+		ORDER BY p.seed_id ASC, p.nonce ASC
+	`
+	rows, err := r.db.Query(query, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query fair spins: %w", err)
+	}
+	defer rows.Close()
+
+	records := []*provablyfair.Record{}
+	for rows.Next() {
+		var record provablyfair.Record
+		err := rows.Scan(&record.SeedID, &record.SessionKey, &record.Nonce, &record.SpinIndex, &record.Number, &record.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan fair spin: %w", err)
+		}
+		records = append(records, &record)
+	}
+	return records, rows.Err()
+}
+
+func (r *RouletteRepository) querySeeds(query string, args ...interface{}) ([]*provablyfair.Seed, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query seeds: %w", err)
+	}
+	defer rows.Close()
+
+	seeds := []*provablyfair.Seed{}
+	for rows.Next() {
+		var (
+			seed       provablyfair.Seed
+			revealedAt sql.NullTime
+		)
+		err := rows.Scan(
+			&seed.ID,
+			&seed.SessionKey,
+			&seed.ServerSeed,
+			&seed.ServerSeedHash,
+			&seed.ClientSeed,
+			&seed.Nonce,
+			&seed.CreatedAt,
+			&revealedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan seed: %w", err)
+		}
+		if revealedAt.Valid {
+			seed.RevealedAt = &revealedAt.Time
+		}
+		seeds = append(seeds, &seed)
+	}
+	return seeds, rows.Err()
+}
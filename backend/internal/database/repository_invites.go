@@ -0,0 +1,115 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"casino-backend/internal/auth"
+)
+
+// inviteColumns are the columns scanned by scanInvite
+const inviteColumns = `i.id, s.key, i.code_hash, i.role, i.max_uses, i.uses, i.expires_at, i.created_at, i.revoked_at`
+
+// CreateInvite stores an invite and assigns its ID
+func (r *RouletteRepository) CreateInvite(invite *auth.Invite) (*auth.Invite, error) {
+	query := `
+		INSERT INTO room_invites (session_id, code_hash, role, max_uses, expires_at, created_at)
+		SELECT id, $2, $3, $4, $5, $6 FROM roulette_sessions WHERE key = $1
+		RETURNING id
+	`
+	stored := *invite
+	err := r.db.QueryRow(query, invite.SessionKey, invite.CodeHash, invite.Role, invite.MaxUses, invite.ExpiresAt, invite.CreatedAt).Scan(&stored.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("session with key '%s' not found", invite.SessionKey)
+		}
+		return nil, fmt.Errorf("failed to insert invite: %w", err)
+	}
+	return &stored, nil
+}
+
+// GetInvites returns the invites of a session in creation order, revoked ones included
+func (r *RouletteRepository) GetInvites(key string) ([]*auth.Invite, error) {
+	query := `
+		SELECT ` + inviteColumns + `
+		FROM room_invites i
+		JOIN roulette_sessions s ON s.id = i.session_id
+		WHERE s.key = $1
+		ORDER BY i.id ASC
+	`
+	rows, err := r.db.Query(query, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query invites: %w", err)
+	}
+	defer rows.Close()
+
+	invites := []*auth.Invite{}
+	for rows.Next() {
+		invite, err := scanInvite(rows)
+		if err != nil {
+			return nil, err
+		}
+		invites = append(invites, invite)
+	}
+	return invites, rows.Err()
+}
+
+// RevokeInvite marks an invite of a session as revoked, reporting whether it existed
+func (r *RouletteRepository) RevokeInvite(key string, id int64) (bool, error) {
+	query := `
+		UPDATE room_invites SET revoked_at = COALESCE(revoked_at, $3)
+		WHERE id = $1 AND session_id = (SELECT id FROM roulette_sessions WHERE key = $2)
+	`
+	res, err := r.db.Exec(query, id, key, time.Now())
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke invite %d: %w", id, err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke invite %d: %w", id, err)
+	}
+	return rows > 0, nil
+}
+
+// RedeemInvite uses an invite of a session by code hash. It returns nil when
+// there is no such invite or it is revoked, expired or used up. The use is
+// counted in the same statement, so concurrent logins cannot exceed maxUses.
+func (r *RouletteRepository) RedeemInvite(key, codeHash string, now time.Time) (*auth.Invite, error) {
+	query := `
+		UPDATE room_invites i SET uses = i.uses + 1
+		FROM roulette_sessions s
+		WHERE s.id = i.session_id AND s.key = $1 AND i.code_hash = $2
+			AND i.revoked_at IS NULL
+			AND (i.expires_at IS NULL OR i.expires_at > $3)
+			AND (i.max_uses = 0 OR i.uses < i.max_uses)
+		RETURNING ` + inviteColumns
+	invite, err := scanInvite(r.db.QueryRow(query, key, codeHash, now))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return invite, err
+}
+
+// scanInvite reads the inviteColumns of a row
+func scanInvite(row interface{ Scan(...interface{}) error }) (*auth.Invite, error) {
+	var (
+		invite    auth.Invite
+		expiresAt sql.NullTime
+		revokedAt sql.NullTime
+	)
+	err := row.Scan(&invite.ID, &invite.SessionKey, &invite.CodeHash, &invite.Role, &invite.MaxUses, &invite.Uses, &expiresAt, &invite.CreatedAt, &revokedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan invite: %w", err)
+	}
+	if expiresAt.Valid {
+		invite.ExpiresAt = &expiresAt.Time
+	}
+	if revokedAt.Valid {
+		invite.RevokedAt = &revokedAt.Time
+	}
+	return &invite, nil
+}
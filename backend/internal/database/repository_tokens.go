@@ -0,0 +1,172 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"casino-backend/internal/auth"
+)
+
+// refreshTokenColumns are the columns scanned by scanRefreshToken
+const refreshTokenColumns = `t.id, s.key, t.token_hash, t.role, t.player_id, t.expires_at, t.created_at, t.revoked_at`
+
+// CreateRefreshToken stores a refresh token and assigns its ID
+func (r *RouletteRepository) CreateRefreshToken(token *auth.RefreshToken) (*auth.RefreshToken, error) {
+	query := `
+		INSERT INTO room_refresh_tokens (session_id, token_hash, role, player_id, expires_at, created_at)
+		SELECT id, $2, $3, $4, $5, $6 FROM roulette_sessions WHERE key = $1
+		RETURNING id
+	`
+	stored := *token
+	err := r.db.QueryRow(query, token.SessionKey, token.TokenHash, token.Role, token.PlayerID, token.ExpiresAt, token.CreatedAt).Scan(&stored.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("session with key '%s' not found", token.SessionKey)
+		}
+		return nil, fmt.Errorf("failed to insert refresh token: %w", err)
+	}
+	return &stored, nil
+}
+
+// RotateRefreshToken replaces the hash of a usable refresh token of a session.
+// It returns nil when there is no such token or it is revoked or expired. The
+// check and the rotation are one statement, so a token is exchanged only once.
+func (r *RouletteRepository) RotateRefreshToken(key, tokenHash, newHash string, now time.Time) (*auth.RefreshToken, error) {
+	query := `
+		UPDATE room_refresh_tokens t SET token_hash = $3
+		FROM roulette_sessions s
+		WHERE s.id = t.session_id AND s.key = $1 AND t.token_hash = $2
+			AND t.revoked_at IS NULL AND t.expires_at > $4
+		RETURNING ` + refreshTokenColumns
+	token, err := scanRefreshToken(r.db.QueryRow(query, key, tokenHash, newHash, now))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return token, err
+}
+
+// GetRefreshTokens returns the refresh tokens of a session in creation order, revoked ones included
+func (r *RouletteRepository) GetRefreshTokens(key string) ([]*auth.RefreshToken, error) {
+	query := `
+		SELECT ` + refreshTokenColumns + `
+		FROM room_refresh_tokens t
+		JOIN roulette_sessions s ON s.id = t.session_id
+		WHERE s.key = $1
+		ORDER BY t.id ASC
+	`
+	rows, err := r.db.Query(query, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query refresh tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := []*auth.RefreshToken{}
+	for rows.Next() {
+		token, err := scanRefreshToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, rows.Err()
+}
+
+// RevokeRefreshToken marks a refresh token of a session as revoked, reporting whether it existed
+func (r *RouletteRepository) RevokeRefreshToken(key string, id int64, now time.Time) (bool, error) {
+	query := `
+		UPDATE room_refresh_tokens SET revoked_at = COALESCE(revoked_at, $3)
+		WHERE id = $1 AND session_id = (SELECT id FROM roulette_sessions WHERE key = $2)
+	`
+	res, err := r.db.Exec(query, id, key, now)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke refresh token %d: %w", id, err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke refresh token %d: %w", id, err)
+	}
+	return rows > 0, nil
+}
+
+// RevokeRefreshTokens marks every refresh token of a session as revoked and returns the highest ID
+func (r *RouletteRepository) RevokeRefreshTokens(key string, now time.Time) (int64, error) {
+	query := `
+		WITH revoked AS (
+			UPDATE room_refresh_tokens SET revoked_at = $2
+			WHERE revoked_at IS NULL AND session_id = (SELECT id FROM roulette_sessions WHERE key = $1)
+		)
+		SELECT COALESCE(MAX(t.id), 0)
+		FROM room_refresh_tokens t
+		JOIN roulette_sessions s ON s.id = t.session_id
+		WHERE s.key = $1
+	`
+	var lastID int64
+	if err := r.db.QueryRow(query, key, now).Scan(&lastID); err != nil {
+		return 0, fmt.Errorf("failed to revoke refresh tokens of session %s: %w", key, err)
+	}
+	return lastID, nil
+}
+
+// SaveTokenRevocation stores a revocation and drops the expired ones
+func (r *RouletteRepository) SaveTokenRevocation(revocation *auth.Revocation) (*auth.Revocation, error) {
+	if _, err := r.db.Exec(`DELETE FROM token_revocations WHERE expires_at <= $1`, revocation.CreatedAt); err != nil {
+		return nil, fmt.Errorf("failed to delete expired token revocations: %w", err)
+	}
+
+	query := `
+		INSERT INTO token_revocations (session_key, grant_id, revoke_all, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+	stored := *revocation
+	err := r.db.QueryRow(query, revocation.SessionKey, revocation.GrantID, revocation.All, revocation.ExpiresAt, revocation.CreatedAt).Scan(&stored.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert token revocation: %w", err)
+	}
+	return &stored, nil
+}
+
+// GetTokenRevocations returns the revocations that have not expired
+func (r *RouletteRepository) GetTokenRevocations(now time.Time) ([]*auth.Revocation, error) {
+	query := `
+		SELECT id, session_key, grant_id, revoke_all, expires_at, created_at
+		FROM token_revocations
+		WHERE expires_at > $1
+		ORDER BY id ASC
+	`
+	rows, err := r.db.Query(query, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query token revocations: %w", err)
+	}
+	defer rows.Close()
+
+	revocations := []*auth.Revocation{}
+	for rows.Next() {
+		var revocation auth.Revocation
+		if err := rows.Scan(&revocation.ID, &revocation.SessionKey, &revocation.GrantID, &revocation.All, &revocation.ExpiresAt, &revocation.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan token revocation: %w", err)
+		}
+		revocations = append(revocations, &revocation)
+	}
+	return revocations, rows.Err()
+}
+
+// scanRefreshToken reads the refreshTokenColumns of a row
+func scanRefreshToken(row interface{ Scan(...interface{}) error }) (*auth.RefreshToken, error) {
+	var (
+		token     auth.RefreshToken
+		revokedAt sql.NullTime
+	)
+	err := row.Scan(&token.ID, &token.SessionKey, &token.TokenHash, &token.Role, &token.PlayerID, &token.ExpiresAt, &token.CreatedAt, &revokedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan refresh token: %w", err)
+	}
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+	return &token, nil
+}
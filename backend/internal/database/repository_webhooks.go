@@ -0,0 +1,126 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"casino-backend/internal/webhooks"
+)
+
+// CreateWebhook stores a subscription and assigns its ID
+func (r *RouletteRepository) CreateWebhook(webhook *webhooks.Webhook) (*webhooks.Webhook, error) {
+	var sessionID sql.NullInt64
+	if webhook.SessionKey != "" {
+		err := r.db.QueryRow(`SELECT id FROM roulette_sessions WHERE key = $1`, webhook.SessionKey).Scan(&sessionID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, fmt.Errorf("session with key '%s' not found", webhook.SessionKey)
+			}
+			return nil, fmt.Errorf("failed to get session: %w", err)
+		}
+	}
+
+	query := `
+		INSERT INTO webhooks (session_id, url, secret, events, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+	stored := *webhook
+	err := r.db.QueryRow(query, sessionID, webhook.URL, webhook.Secret, strings.Join(webhook.Events, ","), webhook.CreatedAt).Scan(&stored.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert webhook: %w", err)
+	}
+	return &stored, nil
+}
+
+// GetWebhooks returns every subscription, secrets included, in creation order
+func (r *RouletteRepository) GetWebhooks() ([]*webhooks.Webhook, error) {
+	query := `
+		SELECT w.id, COALESCE(s.key, ''), w.url, w.secret, w.events, w.created_at
+		FROM webhooks w
+		LEFT JOIN roulette_sessions s ON s.id = w.session_id
+		ORDER BY w.id ASC
+	`
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	hooks := []*webhooks.Webhook{}
+	for rows.Next() {
+		var (
+			webhook webhooks.Webhook
+			events  string
+		)
+		if err := rows.Scan(&webhook.ID, &webhook.SessionKey, &webhook.URL, &webhook.Secret, &events, &webhook.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %w", err)
+		}
+		if events != "" {
+			webhook.Events = strings.Split(events, ",")
+		}
+		hooks = append(hooks, &webhook)
+	}
+	return hooks, rows.Err()
+}
+
+// DeleteWebhook removes a subscription and its delivery log, reporting whether it existed
+func (r *RouletteRepository) DeleteWebhook(id int64) (bool, error) {
+	res, err := r.db.Exec(`DELETE FROM webhooks WHERE id = $1`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete webhook %d: %w", id, err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check affected rows: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// SaveWebhookDelivery appends an attempt to the delivery log of a webhook
+func (r *RouletteRepository) SaveWebhookDelivery(delivery *webhooks.Delivery) error {
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, attempt, status_code, error, duration_ms, success, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err := r.db.Exec(query, delivery.WebhookID, delivery.EventID, delivery.EventType, delivery.Attempt,
+		delivery.StatusCode, delivery.Error, delivery.Duration, delivery.Success, delivery.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// GetWebhookDeliveries returns the latest attempts of a webhook, newest first
+func (r *RouletteRepository) GetWebhookDeliveries(webhookID int64, limit int) ([]*webhooks.Delivery, error) {
+	query := `
+		SELECT id, webhook_id, event_id, event_type, attempt, status_code, error, duration_ms, success, created_at
+		FROM webhook_deliveries
+		WHERE webhook_id = $1
+		ORDER BY id DESC
+	`
+	args := []interface{}{webhookID}
+	if limit > 0 {
+		query += ` LIMIT $2`
+		args = append(args, limit)
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []*webhooks.Delivery{}
+	for rows.Next() {
+		var delivery webhooks.Delivery
+		err := rows.Scan(&delivery.ID, &delivery.WebhookID, &delivery.EventID, &delivery.EventType, &delivery.Attempt,
+			&delivery.StatusCode, &delivery.Error, &delivery.Duration, &delivery.Success, &delivery.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, &delivery)
+	}
+	return deliveries, rows.Err()
+}
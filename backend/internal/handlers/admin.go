@@ -4,26 +4,35 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
+	"sort"
 	"time"
 
+	"casino-backend/internal/analytics"
+	"casino-backend/internal/auth"
+	"casino-backend/internal/betting"
 	"casino-backend/internal/database"
+	"casino-backend/internal/models"
 	"casino-backend/pkg/websocket"
 	"github.com/gorilla/mux"
 )
 
 type Connection struct {
-	ID           string    `json:"id"`
-	Key          string    `json:"key"`
-	ConnectedAt  time.Time `json:"connectedAt"`
-	LastActivity time.Time `json:"lastActivity"`
-	Status       string    `json:"status"`
-	IPAddress    string    `json:"ipAddress,omitempty"`
-	UserAgent    string    `json:"userAgent,omitempty"`
+	ID           string            `json:"id"`
+	Key          string            `json:"key"`
+	ConnectedAt  time.Time         `json:"connectedAt"`
+	LastActivity time.Time         `json:"lastActivity"`
+	Status       string            `json:"status"`
+	IPAddress    string            `json:"ipAddress,omitempty"`
+	UserAgent    string            `json:"userAgent,omitempty"`
+	PlayerID     string            `json:"playerId,omitempty"`
+	Role         auth.RoomRole     `json:"role,omitempty"`
+	Bankroll     *betting.Bankroll `json:"bankroll,omitempty"`
 }
 
 type Session struct {
 	Key               string       `json:"key"`
-	Password          string       `json:"password,omitempty"`
+	Protected         bool         `json:"protected,omitempty"` // У комнаты есть пароль
+	WheelType         string       `json:"wheelType,omitempty"`
 	CreatedAt         time.Time    `json:"createdAt"`
 	LastActivity      time.Time    `json:"lastActivity"`
 	HistoryLength     int          `json:"historyLength"`
@@ -43,12 +52,14 @@ type AdminStats struct {
 type AdminHandler struct {
 	repo database.RouletteRepositoryInterface
 	wsHub *websocket.Hub
+	tokens *auth.RoomTokens
 }
 
-func NewAdminHandler(repo database.RouletteRepositoryInterface, wsHub *websocket.Hub) *AdminHandler {
+func NewAdminHandler(repo database.RouletteRepositoryInterface, wsHub *websocket.Hub, tokens *auth.RoomTokens) *AdminHandler {
 	return &AdminHandler{
 		repo: repo,
 		wsHub: wsHub,
+		tokens: tokens,
 	}
 }
 
@@ -59,7 +70,7 @@ func (h *AdminHandler) GetSessions(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 
 	// Обработка preflight запросов
 	if r.Method == "OPTIONS" {
@@ -87,7 +98,7 @@ func (h *AdminHandler) GetStats(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
@@ -135,7 +146,7 @@ func (h *AdminHandler) GetSessionHistory(w http.ResponseWriter, r *http.Request)
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
@@ -158,18 +169,9 @@ func (h *AdminHandler) GetSessionHistory(w http.ResponseWriter, r *http.Request)
 	}
 	
 	// Извлекаем историю из сессии
-	var history []int
-	if session != nil {
-		history = make([]int, len(session.History))
-		for i, num := range session.History {
-			if val, ok := num.(int); ok {
-				history[i] = val
-			} else if val, ok := num.(float64); ok {
-				history[i] = int(val)
-			}
-		}
-	} else {
-		history = []int{}
+	history := []models.RouletteNumber{}
+	if session != nil && session.History != nil {
+		history = session.History
 	}
 
 	if err := json.NewEncoder(w).Encode(history); err != nil {
@@ -178,12 +180,103 @@ func (h *AdminHandler) GetSessionHistory(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+// SessionFairness содержит вердикт проверки честности колеса для сессии
+type SessionFairness struct {
+	Key        string                    `json:"key"`
+	TotalSpins int                       `json:"totalSpins"`
+	Verdict    analytics.Verdict         `json:"verdict"`
+	Report     *analytics.FairnessReport `json:"report"`
+}
+
+// GetSessionFairness возвращает отчет о честности колеса для конкретной сессии
+func (h *AdminHandler) GetSessionFairness(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	sessionKey := mux.Vars(r)["key"]
+	if sessionKey == "" {
+		http.Error(w, "Session key is required", http.StatusBadRequest)
+		return
+	}
+
+	session, err := h.repo.GetSession(sessionKey)
+	if err != nil {
+		http.Error(w, "Failed to get session", http.StatusInternalServerError)
+		return
+	}
+	if session == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	report, err := analytics.AnalyzeFairness(session.WheelType, session.History)
+	if err != nil {
+		http.Error(w, "Failed to analyze session", http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		http.Error(w, "Failed to encode report", http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetFairness возвращает вердикты по всем сессиям, подозрительные столы идут первыми
+func (h *AdminHandler) GetFairness(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	sessions, err := h.repo.GetAllSessions()
+	if err != nil {
+		http.Error(w, "Failed to get sessions", http.StatusInternalServerError)
+		return
+	}
+
+	results := make([]SessionFairness, 0, len(sessions))
+	for _, session := range sessions {
+		report, err := analytics.AnalyzeFairness(session.WheelType, session.History)
+		if err != nil {
+			log.Printf("[ADMIN] Failed to analyze session %s: %v", session.Key, err)
+			continue
+		}
+		results = append(results, SessionFairness{
+			Key:        session.Key,
+			TotalSpins: report.TotalSpins,
+			Verdict:    report.Verdict,
+			Report:     report,
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Report.MinAdjustedPValue < results[j].Report.MinAdjustedPValue
+	})
+
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		http.Error(w, "Failed to encode results", http.StatusInternalServerError)
+		return
+	}
+}
+
 // DisconnectUser принудительно отключает пользователя
 func (h *AdminHandler) DisconnectUser(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
@@ -230,16 +323,29 @@ func (h *AdminHandler) getSessionsFromHub() []Session {
 		// Получаем длину истории из базы данных
 		dbSession, err := h.repo.GetSession(sessionKey)
 		historyLength := 0
-		password := ""
+		protected := false
+		wheelType := ""
 		if err == nil && dbSession != nil {
 			historyLength = len(dbSession.History)
-			password = dbSession.Password
+			protected = dbSession.Password != ""
+			wheelType = string(dbSession.WheelType)
+		}
+
+		// Банкроллы игроков по их постоянному ID
+		bankrolls := make(map[string]*betting.Bankroll)
+		if playerBankrolls, err := h.repo.GetSessionBankrolls(sessionKey); err == nil {
+			for _, bankroll := range playerBankrolls {
+				bankrolls[bankroll.PlayerID] = bankroll
+			}
+		} else {
+			log.Printf("[ADMIN] Failed to load bankrolls of session %s: %v", sessionKey, err)
 		}
 		
 		// Создаем сессию для админ-панели
 		adminSession := Session{
 			Key:               sessionKey,
-			Password:          password,
+			Protected:         protected,
+			WheelType:         wheelType,
 			CreatedAt:         sessionData.CreatedAt,
 			LastActivity:      sessionData.LastActivity,
 			HistoryLength:     historyLength,
@@ -258,6 +364,9 @@ func (h *AdminHandler) getSessionsFromHub() []Session {
 				Status:       conn.Status,
 				IPAddress:    conn.IPAddress,
 				UserAgent:    conn.UserAgent,
+				PlayerID:     conn.PlayerID,
+				Role:         conn.Role,
+				Bankroll:     bankrolls[conn.PlayerID],
 			}
 			
 			if conn.Status == "connected" {
@@ -275,12 +384,112 @@ func (h *AdminHandler) getSessionsFromHub() []Session {
 	return sessions
 }
 
-// RegisterAdminRoutes регистрирует маршруты для админ-панели
-func (h *AdminHandler) RegisterAdminRoutes(router *mux.Router) {
+// ResetPasswordRequest задает новый пароль комнаты; пустой пароль снимает защиту
+type ResetPasswordRequest struct {
+	Password string `json:"password"`
+}
+
+// ResetSessionPassword заменяет пароль комнаты. Сохраненный пароль узнать нельзя,
+// поэтому забытый пароль сбрасывается.
+func (h *AdminHandler) ResetSessionPassword(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	sessionKey := mux.Vars(r)["key"]
+	if sessionKey == "" {
+		http.Error(w, "Session key is required", http.StatusBadRequest)
+		return
+	}
+
+	var req ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(req.Password) > auth.MaxPasswordLength {
+		http.Error(w, "Password is too long", http.StatusBadRequest)
+		return
+	}
+
+	session, err := h.repo.SetSessionPassword(sessionKey, req.Password)
+	if err != nil {
+		log.Printf("[ADMIN] Failed to reset password of session %s: %v", sessionKey, err)
+		http.Error(w, "Failed to reset password", http.StatusInternalServerError)
+		return
+	}
+	if session == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+	log.Printf("[ADMIN] Password of session %s reset, protected: %t", sessionKey, session.Password != "")
+
+	// Токены, выданные по старому паролю, отзываются, а их владельцы отключаются
+	if err := h.tokens.RevokeRoom(sessionKey); err != nil {
+		log.Printf("[ADMIN] Failed to revoke tokens of session %s: %v", sessionKey, err)
+		http.Error(w, "Password was reset, but failed to revoke room tokens", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"key":       session.Key,
+		"protected": session.Password != "",
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// IssueOwnerToken выдает токены владельца комнаты. Комнаты, созданные через
+// WebSocket или до появления ролей, владельца не имеют, и только так его получают.
+func (h *AdminHandler) IssueOwnerToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	sessionKey := mux.Vars(r)["key"]
+	session, err := h.repo.GetSession(sessionKey)
+	if err != nil {
+		log.Printf("[ADMIN] Failed to get session %s: %v", sessionKey, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if session == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	pair, err := h.tokens.Issue(sessionKey, auth.RoomOwner)
+	if err != nil {
+		log.Printf("[ADMIN] Failed to issue owner token for session %s: %v", sessionKey, err)
+		http.Error(w, "Failed to create token", http.StatusInternalServerError)
+		return
+	}
+	log.Printf("[ADMIN] Owner token issued for session %s", sessionKey)
+
+	writeJSON(w, models.APIResponse{Success: true, Data: pair})
+}
+
+// RegisterAdminRoutes регистрирует маршруты для админ-панели и возвращает
+// подроутер /api/admin для маршрутов других обработчиков
+func (h *AdminHandler) RegisterAdminRoutes(router *mux.Router) *mux.Router {
 	adminRouter := router.PathPrefix("/api/admin").Subrouter()
 	
 	adminRouter.HandleFunc("/sessions", h.GetSessions).Methods("GET", "OPTIONS")
 	adminRouter.HandleFunc("/stats", h.GetStats).Methods("GET", "OPTIONS")
 	adminRouter.HandleFunc("/sessions/{key}/history", h.GetSessionHistory).Methods("GET", "OPTIONS")
+	adminRouter.HandleFunc("/sessions/{key}/fairness", h.GetSessionFairness).Methods("GET", "OPTIONS")
+	adminRouter.HandleFunc("/fairness", h.GetFairness).Methods("GET", "OPTIONS")
 	adminRouter.HandleFunc("/connections/{id}/disconnect", h.DisconnectUser).Methods("POST", "OPTIONS")
+	adminRouter.HandleFunc("/sessions/{key}/password", h.ResetSessionPassword).Methods("POST", "OPTIONS")
+	adminRouter.HandleFunc("/sessions/{key}/owner-token", h.IssueOwnerToken).Methods("POST", "OPTIONS")
+	return adminRouter
 } 
\ No newline at end of file
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"casino-backend/internal/auth"
+	"casino-backend/internal/database"
+	"casino-backend/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+// AdminLoginRequest holds the credentials of an admin account
+type AdminLoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// AdminLoginResponse carries an admin token, sent as "Authorization: Bearer <token>"
+type AdminLoginResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// AdminAuthHandler logs admins in and guards the admin API
+type AdminAuthHandler struct {
+	repo      database.RouletteRepositoryInterface
+	jwtSecret []byte
+}
+
+// NewAdminAuthHandler creates a new admin auth handler
+func NewAdminAuthHandler(repo database.RouletteRepositoryInterface, jwtSecret []byte) *AdminAuthHandler {
+	return &AdminAuthHandler{repo: repo, jwtSecret: jwtSecret}
+}
+
+// RegisterRoutes registers the login route. It must not be registered on the
+// admin router, which only lets authenticated requests through.
+func (h *AdminAuthHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/admin/login", h.Login).Methods("POST", "OPTIONS")
+}
+
+// Login handles POST /api/admin/login
+func (h *AdminAuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var req AdminLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	admin, err := h.repo.GetAdmin(req.Username)
+	if err != nil {
+		log.Printf("Error getting admin %s: %v", req.Username, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !auth.CheckAdminPassword(admin, req.Password) {
+		log.Printf("[ADMIN] Failed login for %q from %s", req.Username, r.RemoteAddr)
+		http.Error(w, "Invalid username or password", http.StatusUnauthorized)
+		return
+	}
+
+	token, expiresAt, err := auth.IssueAdminToken(h.jwtSecret, admin, time.Now())
+	if err != nil {
+		log.Printf("Error issuing token for admin %s: %v", admin.Username, err)
+		http.Error(w, "Failed to create token", http.StatusInternalServerError)
+		return
+	}
+	log.Printf("[ADMIN] %s logged in", admin.Username)
+
+	writeJSON(w, models.APIResponse{Success: true, Data: AdminLoginResponse{Token: token, ExpiresAt: expiresAt}})
+}
+
+// RequireAdmin is the middleware of the admin router: requests need a valid admin token.
+// Preflight requests pass, they carry no credentials.
+func (h *AdminAuthHandler) RequireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token, ok := bearerToken(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Authorization required", http.StatusUnauthorized)
+			return
+		}
+		if _, err := auth.ParseAdminToken(h.jwtSecret, token); err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// bearerToken extracts the token of an "Authorization: Bearer" header
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	const prefix = "Bearer "
+	if len(header) <= len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return "", false
+	}
+	return strings.TrimSpace(header[len(prefix):]), true
+}
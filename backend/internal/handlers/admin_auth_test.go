@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"casino-backend/internal/auth"
+	"casino-backend/internal/database"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/mux"
+)
+
+func TestAdminRoutesRequireAdminToken(t *testing.T) {
+	secret := []byte("test-secret")
+	repo := database.NewMemoryRepository()
+	admin, err := auth.NewAdmin("root", "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.CreateAdmin(admin); err != nil {
+		t.Fatal(err)
+	}
+
+	// Same wiring as the server
+	authHandler := NewAdminAuthHandler(repo, secret)
+	router := mux.NewRouter()
+	api := router.PathPrefix("/api").Subrouter()
+	authHandler.RegisterRoutes(api)
+	adminRouter := NewAdminHandler(repo, nil, auth.NewRoomTokens(repo, secret)).RegisterAdminRoutes(router)
+	adminRouter.Use(authHandler.RequireAdmin)
+
+	serve := func(method, path, body, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := serve("POST", "/api/admin/login", `{"username":"root","password":"wrong"}`, ""); rr.Code != http.StatusUnauthorized {
+		t.Errorf("login with a wrong password: got status %d, want %d", rr.Code, http.StatusUnauthorized)
+	}
+	if rr := serve("POST", "/api/admin/login", `{"username":"nobody","password":"correct horse"}`, ""); rr.Code != http.StatusUnauthorized {
+		t.Errorf("login of a missing admin: got status %d, want %d", rr.Code, http.StatusUnauthorized)
+	}
+
+	rr := serve("POST", "/api/admin/login", `{"username":"root","password":"correct horse"}`, "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("login: got status %d, want %d", rr.Code, http.StatusOK)
+	}
+	var response struct {
+		Data AdminLoginResponse `json:"data"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Data.Token == "" || time.Until(response.Data.ExpiresAt) > auth.AdminTokenTTL {
+		t.Fatalf("login returned %+v", response.Data)
+	}
+
+	roomToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"key": "room",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}).SignedString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, _, err := auth.IssueAdminToken(secret, admin, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged, _, err := auth.IssueAdminToken([]byte("other-secret"), admin, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{"no token", "", http.StatusUnauthorized},
+		{"room token", roomToken, http.StatusUnauthorized},
+		{"expired token", expired, http.StatusUnauthorized},
+		{"forged token", forged, http.StatusUnauthorized},
+		{"admin token", response.Data.Token, http.StatusOK},
+	}
+	for _, tt := range tests {
+		if rr := serve("GET", "/api/admin/sessions/room/history", "", tt.token); rr.Code != tt.want {
+			t.Errorf("%s: got status %d, want %d", tt.name, rr.Code, tt.want)
+		}
+	}
+}
+
+func TestAdminIssuesOwnerTokens(t *testing.T) {
+	secret := []byte("test-secret")
+	repo := database.NewMemoryRepository()
+	admin, err := auth.NewAdmin("root", "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.CreateAdmin(admin); err != nil {
+		t.Fatal(err)
+	}
+	// A room created over WebSocket has no owner
+	if _, err := repo.CreateSession("room"); err != nil {
+		t.Fatal(err)
+	}
+
+	authHandler := NewAdminAuthHandler(repo, secret)
+	router := mux.NewRouter()
+	adminRouter := NewAdminHandler(repo, nil, auth.NewRoomTokens(repo, secret)).RegisterAdminRoutes(router)
+	adminRouter.Use(authHandler.RequireAdmin)
+	token, _, err := auth.IssueAdminToken(secret, admin, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serve := func(key, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/admin/sessions/"+key+"/owner-token", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := serve("room", ""); rr.Code != http.StatusUnauthorized {
+		t.Errorf("without an admin token: got status %d, want %d", rr.Code, http.StatusUnauthorized)
+	}
+	if rr := serve("missing", token); rr.Code != http.StatusNotFound {
+		t.Errorf("missing room: got status %d, want %d", rr.Code, http.StatusNotFound)
+	}
+	rr := serve("room", token)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", rr.Code, rr.Body)
+	}
+	var response struct {
+		Data auth.TokenPair `json:"data"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	claims, err := auth.ParseRoomToken(secret, response.Data.Token, "room")
+	if err != nil || claims.Role != auth.RoomOwner || response.Data.RefreshToken == "" {
+		t.Errorf("issued pair %+v: claims %+v, error %v", response.Data, claims, err)
+	}
+}
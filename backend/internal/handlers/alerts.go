@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"casino-backend/internal/alerts"
+	"casino-backend/internal/analytics"
+	"casino-backend/internal/auth"
+	"casino-backend/internal/database"
+	"casino-backend/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+// AlertHandler manages the alert rules of the rooms
+type AlertHandler struct {
+	roomAccess
+	engine *analytics.AlertEngine
+}
+
+// NewAlertHandler creates a new alert rule handler; only editors and owners change rules
+func NewAlertHandler(engine *analytics.AlertEngine, repo database.RouletteRepositoryInterface, tokens *auth.RoomTokens) *AlertHandler {
+	return &AlertHandler{roomAccess: roomAccess{repo: repo, tokens: tokens}, engine: engine}
+}
+
+// RegisterRoutes registers the alert rule routes
+func (h *AlertHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/roulette/{key}/alerts", h.GetRules).Methods("GET", "OPTIONS")
+	r.HandleFunc("/roulette/{key}/alerts", h.CreateRule).Methods("POST", "OPTIONS")
+	r.HandleFunc("/roulette/{key}/alerts/{id}", h.DeleteRule).Methods("DELETE", "OPTIONS")
+}
+
+// GetRules handles GET /api/roulette/{key}/alerts
+func (h *AlertHandler) GetRules(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.loadSession(w, r)
+	if !ok {
+		return
+	}
+
+	rules, err := h.engine.Rules(session.Key)
+	if err != nil {
+		log.Printf("Error getting alert rules of session %s: %v", session.Key, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if rules == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, models.APIResponse{Success: true, Data: rules})
+}
+
+// CreateRule handles POST /api/roulette/{key}/alerts
+func (h *AlertHandler) CreateRule(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+	if !h.requireEditor(w, r, key) {
+		return
+	}
+
+	var rule alerts.Rule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	rule.SessionKey = key
+	rule.CreatedAt = time.Now()
+
+	created, err := h.engine.CreateRule(&rule)
+	if err != nil {
+		if errors.Is(err, alerts.ErrInvalidRule) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Error creating alert rule of session %s: %v", key, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if created == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, models.APIResponse{Success: true, Data: created})
+}
+
+// DeleteRule handles DELETE /api/roulette/{key}/alerts/{id}
+func (h *AlertHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if !h.requireEditor(w, r, vars["key"]) {
+		return
+	}
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid rule ID", http.StatusBadRequest)
+		return
+	}
+
+	deleted, err := h.engine.DeleteRule(vars["key"], id)
+	if err != nil {
+		log.Printf("Error deleting alert rule %d of session %s: %v", id, vars["key"], err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !deleted {
+		http.Error(w, "Rule not found", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, models.APIResponse{Success: true})
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"casino-backend/internal/analytics"
+	"casino-backend/internal/models"
+)
+
+// GetStats handles GET /api/roulette/{key}/stats?window=N
+func (h *RouletteHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.loadSession(w, r)
+	if !ok {
+		return
+	}
+
+	window, err := intQueryParam(r, "window", 0)
+	if err != nil || window < 0 {
+		http.Error(w, "Invalid window parameter (must be non-negative integer)", http.StatusBadRequest)
+		return
+	}
+
+	history := analytics.LastN(session.History, window)
+	writeJSON(w, models.APIResponse{
+		Success: true,
+		Data:    analytics.ComputeStats(session.WheelType, history),
+	})
+}
+
+// GetForecast handles GET /api/roulette/{key}/forecast?decay=&sectorWeight=&longTermPenalty=
+func (h *RouletteHandler) GetForecast(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.loadSession(w, r)
+	if !ok {
+		return
+	}
+
+	config := analytics.DefaultForecastConfig
+	var err error
+	if config.Decay, err = floatQueryParam(r, "decay", config.Decay); err != nil {
+		http.Error(w, "Invalid decay parameter", http.StatusBadRequest)
+		return
+	}
+	if config.SectorWeight, err = floatQueryParam(r, "sectorWeight", config.SectorWeight); err != nil {
+		http.Error(w, "Invalid sectorWeight parameter", http.StatusBadRequest)
+		return
+	}
+	if config.LongTermPenalty, err = floatQueryParam(r, "longTermPenalty", config.LongTermPenalty); err != nil {
+		http.Error(w, "Invalid longTermPenalty parameter", http.StatusBadRequest)
+		return
+	}
+	if err := config.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, models.APIResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"config":   config,
+			"forecast": analytics.BuildCombinedForecast(session.WheelType, session.History, config),
+		},
+	})
+}
+
+// GetFairness handles GET /api/roulette/{key}/fairness?window=N
+func (h *RouletteHandler) GetFairness(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.loadSession(w, r)
+	if !ok {
+		return
+	}
+
+	window, err := intQueryParam(r, "window", 0)
+	if err != nil || window < 0 {
+		http.Error(w, "Invalid window parameter (must be non-negative integer)", http.StatusBadRequest)
+		return
+	}
+
+	report, err := analytics.AnalyzeFairness(session.WheelType, analytics.LastN(session.History, window))
+	if err != nil {
+		log.Printf("Error analyzing fairness of session %s: %v", session.Key, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, models.APIResponse{Success: true, Data: report})
+}
+
+// GetTransitions handles GET /api/roulette/{key}/transitions?window=N
+func (h *RouletteHandler) GetTransitions(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.loadSession(w, r)
+	if !ok {
+		return
+	}
+
+	window, err := intQueryParam(r, "window", 0)
+	if err != nil || window < 0 {
+		http.Error(w, "Invalid window parameter (must be non-negative integer)", http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, models.APIResponse{
+		Success: true,
+		Data:    analytics.AnalyzeTransitions(session.WheelType, analytics.LastN(session.History, window)),
+	})
+}
+
+// intQueryParam parses an optional integer query parameter
+func intQueryParam(r *http.Request, name string, defaultValue int) (int, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return defaultValue, nil
+	}
+	return strconv.Atoi(raw)
+}
+
+// floatQueryParam parses an optional float query parameter
+func floatQueryParam(r *http.Request, name string, defaultValue float64) (float64, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return defaultValue, nil
+	}
+	return strconv.ParseFloat(raw, 64)
+}
+
+// writeJSON writes a JSON response with the usual CORS headers
+func writeJSON(w http.ResponseWriter, response interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"casino-backend/internal/analytics"
+	"casino-backend/internal/models"
+	"casino-backend/internal/simulation"
+)
+
+// BacktestRequest configures a replay of a room history
+type BacktestRequest struct {
+	Strategies []simulation.Spec `json:"strategies"` // Every registered strategy when empty
+	Bankroll   int64             `json:"bankroll"`
+	MinBet     int64             `json:"minBet"`
+	MaxBet     int64             `json:"maxBet"`
+	Window     int               `json:"window"` // Replay only the last N spins, 0 for the whole history
+}
+
+// Backtest handles POST /api/roulette/{key}/backtest
+func (h *RouletteHandler) Backtest(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.loadSession(w, r)
+	if !ok {
+		return
+	}
+
+	var req BacktestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Window < 0 {
+		http.Error(w, "Invalid window (must be non-negative integer)", http.StatusBadRequest)
+		return
+	}
+
+	config := simulation.Config{
+		Wheel:    session.WheelType,
+		Bankroll: req.Bankroll,
+		MinBet:   req.MinBet,
+		MaxBet:   req.MaxBet,
+	}
+	// Every failure here comes from the request: limits, strategy names or bets
+	results, err := simulation.Backtest(req.Strategies, config, analytics.LastN(session.History, req.Window))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, models.APIResponse{Success: true, Data: results})
+}
+
+// MonteCarloRequest configures simulated sessions on a fair wheel
+type MonteCarloRequest struct {
+	Strategy   simulation.Spec  `json:"strategy"`
+	WheelType  models.WheelType `json:"wheelType,omitempty"` // The room's wheel by default
+	Bankroll   int64            `json:"bankroll"`
+	MinBet     int64            `json:"minBet"`
+	MaxBet     int64            `json:"maxBet"`
+	Iterations int              `json:"iterations"` // 1000 by default, fewer for long sessions
+	Spins      int              `json:"spins"`      // Length of the room history by default
+	Seed       int64            `json:"seed"`       // Random when 0
+}
+
+// MonteCarloComparison sets the simulated sessions beside what the strategy did at the table
+type MonteCarloComparison struct {
+	*simulation.MonteCarloResult
+	Table           *simulation.Summary `json:"table,omitempty"`           // The strategy over the room history, on the room's wheel only
+	TablePercentile *float64            `json:"tablePercentile,omitempty"` // Share of fair sessions that ended at or below the table
+}
+
+// Defaults of a Monte Carlo request
+const (
+	defaultMonteCarloIterations = 1000
+	defaultMonteCarloSpins      = 100
+)
+
+// MonteCarlo handles POST /api/roulette/{key}/montecarlo
+func (h *RouletteHandler) MonteCarlo(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.loadSession(w, r)
+	if !ok {
+		return
+	}
+
+	var req MonteCarloRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.WheelType == "" {
+		req.WheelType = session.WheelType
+	} else if !req.WheelType.IsValid() {
+		http.Error(w, "Unknown wheel type", http.StatusBadRequest)
+		return
+	}
+	if req.Spins == 0 {
+		switch {
+		case len(session.History) == 0:
+			req.Spins = defaultMonteCarloSpins
+		case len(session.History) > simulation.MaxSpins:
+			req.Spins = simulation.MaxSpins
+		default:
+			req.Spins = len(session.History)
+		}
+	}
+	if req.Iterations == 0 && req.Spins > 0 {
+		req.Iterations = defaultMonteCarloIterations
+		if req.Iterations*req.Spins > simulation.MaxTotalSpins {
+			req.Iterations = simulation.MaxTotalSpins / req.Spins
+		}
+	}
+	if req.Iterations > simulation.MaxIterations || req.Spins > simulation.MaxSpins ||
+		req.Iterations*req.Spins > simulation.MaxTotalSpins {
+		http.Error(w, fmt.Sprintf("Too many spins: at most %d iterations of %d spins and %d spins in total",
+			simulation.MaxIterations, simulation.MaxSpins, simulation.MaxTotalSpins), http.StatusBadRequest)
+		return
+	}
+
+	config := simulation.Config{
+		Wheel:    req.WheelType,
+		Bankroll: req.Bankroll,
+		MinBet:   req.MinBet,
+		MaxBet:   req.MaxBet,
+	}
+	result, err := simulation.MonteCarlo(req.Strategy, simulation.MonteCarloConfig{
+		Config:     config,
+		Iterations: req.Iterations,
+		Spins:      req.Spins,
+		Seed:       req.Seed,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	comparison := MonteCarloComparison{MonteCarloResult: result}
+	if len(session.History) > 0 && req.WheelType.OrDefault() == session.WheelType.OrDefault() {
+		actual, err := simulation.Backtest([]simulation.Spec{req.Strategy}, config, session.History)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		comparison.Table = &actual[0].Summary
+		percentile := result.PercentileOf(actual[0].Summary.FinalBalance)
+		comparison.TablePercentile = &percentile
+	}
+
+	writeJSON(w, models.APIResponse{Success: true, Data: comparison})
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"casino-backend/internal/betting"
+	"casino-backend/internal/models"
+)
+
+// BetsSummary is the profit/loss record of a room
+type BetsSummary struct {
+	Bets     []*betting.Wager `json:"bets"`
+	Pending  int              `json:"pending"`
+	Settled  int              `json:"settled"`
+	Staked   int64            `json:"staked"`   // Stake of settled bets
+	Returned int64            `json:"returned"` // Stake plus winnings paid back
+	Net      int64            `json:"net"`      // Player profit, negative when the players lost
+}
+
+// GetBets handles GET /api/roulette/{key}/bets
+func (h *RouletteHandler) GetBets(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.loadSession(w, r)
+	if !ok {
+		return
+	}
+
+	wagers, err := h.repo.GetSessionWagers(session.Key)
+	if err != nil {
+		log.Printf("Error getting bets of session %s: %v", session.Key, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	summary := BetsSummary{Bets: wagers}
+	for _, wager := range wagers {
+		if wager.Result == nil {
+			summary.Pending++
+			continue
+		}
+		summary.Settled++
+		summary.Staked += wager.Result.Staked
+		summary.Returned += wager.Result.Returned
+		summary.Net += wager.Result.Net
+	}
+
+	writeJSON(w, models.APIResponse{Success: true, Data: summary})
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"casino-backend/internal/models"
+	"casino-backend/internal/provablyfair"
+)
+
+// ProvablyFairState lists the commitment of a room and its past seeds
+type ProvablyFairState struct {
+	Active *provablyfair.Seed   `json:"active,omitempty"` // Server seed hidden
+	Seeds  []*provablyfair.Seed `json:"seeds"`            // Oldest first, server seeds of revealed ones included
+}
+
+// RotateSeedRequest sets the client seed of the next commitment
+type RotateSeedRequest struct {
+	ClientSeed string `json:"clientSeed"` // Random when empty
+}
+
+// AuditedSpin is a fair spin together with what can be checked about it
+type AuditedSpin struct {
+	*provablyfair.Record
+	Seed      *provablyfair.Seed `json:"seed"`
+	Verified  *bool              `json:"verified,omitempty"` // Set once the seed is revealed
+	InHistory bool               `json:"inHistory"`          // The history still holds the number at spinIndex
+}
+
+// VerifyRequest is a stateless check of a revealed seed
+type VerifyRequest struct {
+	ServerSeed     string           `json:"serverSeed"`
+	ServerSeedHash string           `json:"serverSeedHash"`
+	ClientSeed     string           `json:"clientSeed"`
+	Nonce          int              `json:"nonce"`
+	WheelType      models.WheelType `json:"wheelType"`
+}
+
+// GetProvablyFair handles GET /api/roulette/{key}/provably-fair
+func (h *RouletteHandler) GetProvablyFair(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.loadSession(w, r)
+	if !ok {
+		return
+	}
+
+	seeds, err := h.repo.GetFairSeeds(session.Key)
+	if err != nil {
+		log.Printf("Error getting seeds of session %s: %v", session.Key, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	state := ProvablyFairState{Seeds: make([]*provablyfair.Seed, 0, len(seeds))}
+	for _, seed := range seeds {
+		public := seed.Public()
+		if !seed.Revealed() {
+			state.Active = public
+		}
+		state.Seeds = append(state.Seeds, public)
+	}
+
+	writeJSON(w, models.APIResponse{Success: true, Data: state})
+}
+
+// RotateSeed handles POST /api/roulette/{key}/provably-fair/rotate
+func (h *RouletteHandler) RotateSeed(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.loadSession(w, r)
+	if !ok || !h.requireEditor(w, r, session.Key) {
+		return
+	}
+
+	var req RotateSeedRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	change, err := h.wsHub.RotateFairSeed(session.Key, req.ClientSeed)
+	if err != nil {
+		if errors.Is(err, provablyfair.ErrInvalidSeed) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Error rotating seed of session %s: %v", session.Key, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, models.APIResponse{Success: true, Data: change})
+}
+
+// GetFairSpins handles GET /api/roulette/{key}/provably-fair/spins?index=N
+func (h *RouletteHandler) GetFairSpins(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.loadSession(w, r)
+	if !ok {
+		return
+	}
+	index, err := intQueryParam(r, "index", -1)
+	if err != nil || index < -1 {
+		http.Error(w, "Invalid index (must be non-negative integer)", http.StatusBadRequest)
+		return
+	}
+
+	seeds, err := h.repo.GetFairSeeds(session.Key)
+	if err != nil {
+		log.Printf("Error getting seeds of session %s: %v", session.Key, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	records, err := h.repo.GetFairRecords(session.Key)
+	if err != nil {
+		log.Printf("Error getting fair spins of session %s: %v", session.Key, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	seedsByID := make(map[int64]*provablyfair.Seed, len(seeds))
+	for _, seed := range seeds {
+		seedsByID[seed.ID] = seed
+	}
+
+	spins := []AuditedSpin{}
+	for _, record := range records {
+		if index >= 0 && record.SpinIndex != index {
+			continue
+		}
+		seed := seedsByID[record.SeedID]
+		if seed == nil {
+			continue
+		}
+		spin := AuditedSpin{
+			Record:    record,
+			Seed:      seed.Public(),
+			InHistory: record.SpinIndex < len(session.History) && session.History[record.SpinIndex] == record.Number,
+		}
+		if seed.Revealed() {
+			number, err := provablyfair.Spin(session.WheelType, seed.ServerSeed, seed.ClientSeed, record.Nonce)
+			verified := err == nil && number == record.Number && provablyfair.HashSeed(seed.ServerSeed) == seed.ServerSeedHash
+			spin.Verified = &verified
+		}
+		spins = append(spins, spin)
+	}
+
+	writeJSON(w, models.APIResponse{Success: true, Data: spins})
+}
+
+// VerifyFairSpin handles POST /api/provably-fair/verify
+func (h *RouletteHandler) VerifyFairSpin(w http.ResponseWriter, r *http.Request) {
+	var req VerifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	verification, err := provablyfair.Verify(req.WheelType, req.ServerSeed, req.ServerSeedHash, req.ClientSeed, req.Nonce)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, models.APIResponse{Success: true, Data: verification})
+}
@@ -0,0 +1,354 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"casino-backend/internal/auth"
+	"casino-backend/internal/database"
+	"casino-backend/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+// errNotOwner is returned for room management requests without an owner token
+var errNotOwner = errors.New("only the room owner can do this")
+
+// IssueTokenRequest names the role of a new room token
+type IssueTokenRequest struct {
+	Role auth.RoomRole `json:"role"`
+}
+
+// RefreshRequest exchanges a refresh token for a new access token
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// roomAccess checks the room tokens of requests. Handlers with room endpoints
+// embed it.
+type roomAccess struct {
+	repo   database.RouletteRepositoryInterface
+	tokens *auth.RoomTokens
+}
+
+// roomClaims verifies the "Authorization: Bearer" room token of a request, revocations included
+func (h *roomAccess) roomClaims(r *http.Request, key string) (*auth.RoomClaims, error) {
+	token, ok := bearerToken(r)
+	if !ok {
+		return nil, auth.ErrInvalidToken
+	}
+	return h.tokens.Parse(token, key)
+}
+
+// requireRoomToken checks that a request carries a valid token of the room, answering it otherwise
+func (h *roomAccess) requireRoomToken(w http.ResponseWriter, r *http.Request, key string) (*auth.RoomClaims, bool) {
+	claims, err := h.roomClaims(r, key)
+	if err != nil {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "Authorization required", http.StatusUnauthorized)
+		return nil, false
+	}
+	return claims, true
+}
+
+// requireViewer checks that a request may read a room, answering it otherwise.
+// Password-protected rooms need a token of any role.
+func (h *roomAccess) requireViewer(w http.ResponseWriter, r *http.Request, session *models.RouletteSession) bool {
+	if session.Password == "" {
+		return true
+	}
+	_, ok := h.requireRoomToken(w, r, session.Key)
+	return ok
+}
+
+// loadSession fetches the session named in the URL, writing an error response
+// if it is missing or the request may not read it
+func (h *roomAccess) loadSession(w http.ResponseWriter, r *http.Request) (*models.RouletteSession, bool) {
+	key := mux.Vars(r)["key"]
+	if key == "" {
+		http.Error(w, "Key is required", http.StatusBadRequest)
+		return nil, false
+	}
+
+	session, err := h.repo.GetSession(key)
+	if err != nil {
+		log.Printf("Error getting session %s: %v", key, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return nil, false
+	}
+	if session == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return nil, false
+	}
+	if !h.requireViewer(w, r, session) {
+		return nil, false
+	}
+	return session, true
+}
+
+// requireOwner checks that a request carries an owner token of the room, answering it otherwise
+func (h *roomAccess) requireOwner(w http.ResponseWriter, r *http.Request, key string) bool {
+	claims, ok := h.requireRoomToken(w, r, key)
+	if !ok {
+		return false
+	}
+	if claims.Role != auth.RoomOwner {
+		http.Error(w, errNotOwner.Error(), http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// requireEditor checks that a request may change a room, answering it otherwise.
+// Password-protected rooms need an owner or editor token. A token sent to an
+// open room is checked as well, so revoked and viewer tokens are refused there too.
+func (h *roomAccess) requireEditor(w http.ResponseWriter, r *http.Request, key string) bool {
+	if _, ok := bearerToken(r); !ok {
+		session, err := h.repo.GetSession(key)
+		if err != nil {
+			log.Printf("Error getting session %s: %v", key, err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return false
+		}
+		if session == nil || session.Password == "" {
+			return true
+		}
+	}
+
+	claims, ok := h.requireRoomToken(w, r, key)
+	if !ok {
+		return false
+	}
+	if !claims.Role.CanEdit() {
+		http.Error(w, "Viewers cannot change the room", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// IssueRoomToken handles POST /api/rooms/{key}/tokens: an owner invites others with tokens of any role
+func (h *RouletteHandler) IssueRoomToken(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+	if !h.requireOwner(w, r, key) {
+		return
+	}
+	if _, ok := h.loadSession(w, r); !ok {
+		return
+	}
+
+	var req IssueTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if !req.Role.IsValid() {
+		http.Error(w, "Role must be owner, editor or viewer", http.StatusBadRequest)
+		return
+	}
+
+	pair, err := h.tokens.Issue(key, req.Role)
+	if err != nil {
+		log.Printf("Error issuing %s token for session %s: %v", req.Role, key, err)
+		http.Error(w, "Failed to create token", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, models.APIResponse{Success: true, Data: pair})
+}
+
+// RefreshRoomToken handles POST /api/rooms/{key}/refresh. The refresh token is
+// rotated, so the new one must be kept.
+func (h *RouletteHandler) RefreshRoomToken(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+
+	var req RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	pair, err := h.tokens.Refresh(key, req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidToken) {
+			http.Error(w, "Invalid, expired or revoked refresh token", http.StatusUnauthorized)
+			return
+		}
+		log.Printf("Error refreshing token for session %s: %v", key, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, models.APIResponse{Success: true, Data: pair})
+}
+
+// Logout handles POST /api/rooms/{key}/logout: the refresh token of the
+// bearer and every access token issued with it stop working
+func (h *RouletteHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+	claims, ok := h.requireRoomToken(w, r, key)
+	if !ok {
+		return
+	}
+	if claims.GrantID == 0 {
+		http.Error(w, "Token was issued before refresh tokens and cannot be revoked", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.tokens.RevokeGrant(key, claims.GrantID); err != nil {
+		log.Printf("Error revoking grant %d of session %s: %v", claims.GrantID, key, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, models.APIResponse{Success: true})
+}
+
+// GetRoomGrants handles GET /api/rooms/{key}/tokens: the refresh tokens handed out for the room
+func (h *RouletteHandler) GetRoomGrants(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+	if !h.requireOwner(w, r, key) {
+		return
+	}
+
+	grants, err := h.tokens.Grants(key)
+	if err != nil {
+		log.Printf("Error getting refresh tokens of session %s: %v", key, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, models.APIResponse{Success: true, Data: grants})
+}
+
+// RevokeRoomGrant handles DELETE /api/rooms/{key}/tokens/{id}. Clients
+// connected with the revoked tokens are disconnected.
+func (h *RouletteHandler) RevokeRoomGrant(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key := vars["key"]
+	if !h.requireOwner(w, r, key) {
+		return
+	}
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid token ID", http.StatusBadRequest)
+		return
+	}
+
+	revoked, err := h.tokens.RevokeGrant(key, id)
+	if err != nil {
+		log.Printf("Error revoking grant %d of session %s: %v", id, key, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !revoked {
+		http.Error(w, "Token not found", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, models.APIResponse{Success: true})
+}
+
+// CreateInviteRequest describes a new invite
+type CreateInviteRequest struct {
+	Role      auth.RoomRole `json:"role"`
+	MaxUses   int           `json:"maxUses"`             // 0 for unlimited
+	ExpiresAt *time.Time    `json:"expiresAt,omitempty"` // Never expires when empty
+}
+
+// CreateInvite handles POST /api/rooms/{key}/invites. The code is only returned here.
+func (h *RouletteHandler) CreateInvite(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+	if !h.requireOwner(w, r, key) {
+		return
+	}
+	if _, ok := h.loadSession(w, r); !ok {
+		return
+	}
+
+	var req CreateInviteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	invite, err := auth.NewInvite(key, req.Role, req.MaxUses, req.ExpiresAt, time.Now())
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidInvite) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Error creating invite for session %s: %v", key, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	invites, err := h.repo.GetInvites(key)
+	if err != nil {
+		log.Printf("Error getting invites of session %s: %v", key, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if len(invites) >= auth.MaxInvitesPerRoom {
+		http.Error(w, "Too many invites for this room", http.StatusBadRequest)
+		return
+	}
+
+	created, err := h.repo.CreateInvite(invite)
+	if err != nil {
+		log.Printf("Error storing invite for session %s: %v", key, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	created.Code = invite.Code
+
+	writeJSON(w, models.APIResponse{Success: true, Data: created})
+}
+
+// GetInvites handles GET /api/rooms/{key}/invites
+func (h *RouletteHandler) GetInvites(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+	if !h.requireOwner(w, r, key) {
+		return
+	}
+
+	invites, err := h.repo.GetInvites(key)
+	if err != nil {
+		log.Printf("Error getting invites of session %s: %v", key, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, models.APIResponse{Success: true, Data: invites})
+}
+
+// RevokeInvite handles DELETE /api/rooms/{key}/invites/{id}. Tokens already
+// issued through the invite stay valid.
+func (h *RouletteHandler) RevokeInvite(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key := vars["key"]
+	if !h.requireOwner(w, r, key) {
+		return
+	}
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid invite ID", http.StatusBadRequest)
+		return
+	}
+
+	revoked, err := h.repo.RevokeInvite(key, id)
+	if err != nil {
+		log.Printf("Error revoking invite %d of session %s: %v", id, key, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !revoked {
+		http.Error(w, "Invite not found", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, models.APIResponse{Success: true})
+}
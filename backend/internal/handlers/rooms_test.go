@@ -0,0 +1,419 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"casino-backend/internal/analytics"
+	"casino-backend/internal/auth"
+	"casino-backend/internal/database"
+	"casino-backend/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+// authResponse is the body of POST /api/rooms/auth
+type authResponse struct {
+	Data RoomAuthResponse `json:"data"`
+}
+
+// roomToken issues an access token of a room the way /api/rooms/auth does
+func roomToken(t *testing.T, tokens *auth.RoomTokens, key string, role auth.RoomRole) string {
+	t.Helper()
+	pair, err := tokens.Issue(key, role)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pair.Token
+}
+func TestRoomOwnerIssuesTokens(t *testing.T) {
+	secret := []byte("test-secret")
+	repo := database.NewMemoryRepository()
+	handler := NewRouletteHandler(repo, nil, auth.NewRoomTokens(repo, secret))
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router.PathPrefix("/api").Subrouter())
+
+	serve := func(path, body, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+	authenticate := func(password string) RoomAuthResponse {
+		rr := serve("/api/rooms/auth", `{"key":"room","password":"`+password+`"}`, "")
+		if rr.Code != http.StatusOK {
+			t.Fatalf("authenticate: got status %d: %s", rr.Code, rr.Body)
+		}
+		var response authResponse
+		if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		return response.Data
+	}
+
+	owner := authenticate("secret")
+	if owner.Role != auth.RoomOwner {
+		t.Fatalf("creator got role %q, want owner", owner.Role)
+	}
+	editor := authenticate("secret")
+	if editor.Role != auth.RoomEditor {
+		t.Fatalf("password holder got role %q, want editor", editor.Role)
+	}
+
+	rr := serve("/api/rooms/room/tokens", `{"role":"viewer"}`, owner.Token)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("owner issuing a token: got status %d: %s", rr.Code, rr.Body)
+	}
+	var response struct {
+		Data auth.TokenPair `json:"data"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	claims, err := auth.ParseRoomToken(secret, response.Data.Token, "room")
+	if err != nil || claims.Role != auth.RoomViewer || claims.PlayerID == "" || claims.PlayerID != response.Data.PlayerID {
+		t.Fatalf("issued token: claims %+v, error %v", claims, err)
+	}
+
+	tests := []struct {
+		name  string
+		body  string
+		token string
+		want  int
+	}{
+		{"no token", `{"role":"viewer"}`, "", http.StatusUnauthorized},
+		{"editor token", `{"role":"viewer"}`, editor.Token, http.StatusForbidden},
+		{"viewer token", `{"role":"owner"}`, response.Data.Token, http.StatusForbidden},
+		{"unknown role", `{"role":"admin"}`, owner.Token, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if rr := serve("/api/rooms/room/tokens", tt.body, tt.token); rr.Code != tt.want {
+			t.Errorf("%s: got status %d, want %d", tt.name, rr.Code, tt.want)
+		}
+	}
+}
+
+// racingRepository never finds a session, as when another client creates it
+// between the lookup and the insert
+type racingRepository struct {
+	*database.MemoryRepository
+}
+
+func (r racingRepository) GetSession(key string) (*models.RouletteSession, error) {
+	return nil, nil
+}
+
+func TestConcurrentCreatorIsNotOwner(t *testing.T) {
+	repo := racingRepository{database.NewMemoryRepository()}
+	handler := NewRouletteHandler(repo, nil, auth.NewRoomTokens(repo, []byte("test-secret")))
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router.PathPrefix("/api").Subrouter())
+
+	if _, err := repo.CreateSessionWithPassword("room", "first"); err != nil {
+		t.Fatal(err)
+	}
+
+	authenticate := func(password string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("POST", "/api/rooms/auth", strings.NewReader(`{"key":"room","password":"`+password+`"}`)))
+		return rr
+	}
+	if rr := authenticate("second"); rr.Code != http.StatusUnauthorized {
+		t.Errorf("losing creator with another password: got status %d, want %d", rr.Code, http.StatusUnauthorized)
+	}
+	rr := authenticate("first")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("losing creator with the password: got status %d: %s", rr.Code, rr.Body)
+	}
+	var response authResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Data.Role != auth.RoomEditor {
+		t.Errorf("losing creator got role %q, want editor", response.Data.Role)
+	}
+}
+
+func TestRoomInvites(t *testing.T) {
+	repo := database.NewMemoryRepository()
+	handler := NewRouletteHandler(repo, nil, auth.NewRoomTokens(repo, []byte("test-secret")))
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router.PathPrefix("/api").Subrouter())
+
+	serve := func(method, path, body, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+	decode := func(rr *httptest.ResponseRecorder, v interface{}) {
+		t.Helper()
+		if rr.Code != http.StatusOK {
+			t.Fatalf("got status %d: %s", rr.Code, rr.Body)
+		}
+		if err := json.NewDecoder(rr.Body).Decode(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var owner authResponse
+	decode(serve("POST", "/api/rooms/auth", `{"key":"room","password":"secret"}`, ""), &owner)
+
+	var created struct {
+		Data auth.Invite `json:"data"`
+	}
+	decode(serve("POST", "/api/rooms/room/invites", `{"role":"viewer","maxUses":1}`, owner.Data.Token), &created)
+	if created.Data.Code == "" || created.Data.Role != auth.RoomViewer {
+		t.Fatalf("created invite %+v", created.Data)
+	}
+
+	var guest authResponse
+	decode(serve("POST", "/api/rooms/auth", `{"key":"room","invite":"`+created.Data.Code+`"}`, ""), &guest)
+	if guest.Data.Role != auth.RoomViewer {
+		t.Errorf("invite holder got role %q, want viewer", guest.Data.Role)
+	}
+	if rr := serve("POST", "/api/rooms/auth", `{"key":"room","invite":"`+created.Data.Code+`"}`, ""); rr.Code != http.StatusUnauthorized {
+		t.Errorf("used up invite: got status %d, want %d", rr.Code, http.StatusUnauthorized)
+	}
+
+	var editorInvite struct {
+		Data auth.Invite `json:"data"`
+	}
+	decode(serve("POST", "/api/rooms/room/invites", `{"role":"editor"}`, owner.Data.Token), &editorInvite)
+	if rr := serve("DELETE", "/api/rooms/room/invites/"+strconv.FormatInt(editorInvite.Data.ID, 10), "", owner.Data.Token); rr.Code != http.StatusOK {
+		t.Fatalf("revoke: got status %d", rr.Code)
+	}
+	if rr := serve("POST", "/api/rooms/auth", `{"key":"room","invite":"`+editorInvite.Data.Code+`"}`, ""); rr.Code != http.StatusUnauthorized {
+		t.Errorf("revoked invite: got status %d, want %d", rr.Code, http.StatusUnauthorized)
+	}
+
+	var listed struct {
+		Data []auth.Invite `json:"data"`
+	}
+	decode(serve("GET", "/api/rooms/room/invites", "", owner.Data.Token), &listed)
+	if len(listed.Data) != 2 || listed.Data[0].Uses != 1 || listed.Data[1].RevokedAt == nil {
+		t.Fatalf("listed invites %+v", listed.Data)
+	}
+	for _, invite := range listed.Data {
+		if invite.Code != "" {
+			t.Errorf("invite %d listed with its code", invite.ID)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		body  string
+		token string
+		want  int
+	}{
+		{"viewer token", `{"role":"viewer"}`, guest.Data.Token, http.StatusForbidden},
+		{"unknown role", `{"role":"admin"}`, owner.Data.Token, http.StatusBadRequest},
+		{"negative uses", `{"role":"viewer","maxUses":-1}`, owner.Data.Token, http.StatusBadRequest},
+		{"expired", `{"role":"viewer","expiresAt":"2020-01-01T00:00:00Z"}`, owner.Data.Token, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if rr := serve("POST", "/api/rooms/room/invites", tt.body, tt.token); rr.Code != tt.want {
+			t.Errorf("%s: got status %d, want %d", tt.name, rr.Code, tt.want)
+		}
+	}
+}
+
+func TestRoomTokenRefreshAndRevocation(t *testing.T) {
+	repo := database.NewMemoryRepository()
+	handler := NewRouletteHandler(repo, nil, auth.NewRoomTokens(repo, []byte("test-secret")))
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router.PathPrefix("/api").Subrouter())
+
+	serve := func(method, path, body, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+	decode := func(rr *httptest.ResponseRecorder, v interface{}) {
+		t.Helper()
+		if rr.Code != http.StatusOK {
+			t.Fatalf("got status %d: %s", rr.Code, rr.Body)
+		}
+		if err := json.NewDecoder(rr.Body).Decode(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	save := func(token string) int {
+		return serve("POST", "/api/roulette/save", `{"key":"room","number":7}`, token).Code
+	}
+
+	var owner, editor authResponse
+	decode(serve("POST", "/api/rooms/auth", `{"key":"room","password":"secret"}`, ""), &owner)
+	decode(serve("POST", "/api/rooms/auth", `{"key":"room","password":"secret"}`, ""), &editor)
+	if owner.Data.RefreshToken == "" || owner.Data.ExpiresAt.IsZero() {
+		t.Fatalf("authentication returned no refresh token: %v", owner)
+	}
+
+	var refreshed struct {
+		Data auth.TokenPair `json:"data"`
+	}
+	decode(serve("POST", "/api/rooms/room/refresh", `{"refreshToken":"`+owner.Data.RefreshToken+`"}`, ""), &refreshed)
+	if refreshed.Data.Role != auth.RoomOwner || refreshed.Data.RefreshToken == owner.Data.RefreshToken {
+		t.Fatalf("refreshed tokens %+v", refreshed.Data)
+	}
+	if rr := serve("POST", "/api/rooms/room/refresh", `{"refreshToken":"`+owner.Data.RefreshToken+`"}`, ""); rr.Code != http.StatusUnauthorized {
+		t.Errorf("reused refresh token: got status %d, want %d", rr.Code, http.StatusUnauthorized)
+	}
+
+	if code := save(""); code != http.StatusUnauthorized {
+		t.Errorf("save without a token: got status %d, want %d", code, http.StatusUnauthorized)
+	}
+	if code := save(editor.Data.Token); code != http.StatusOK {
+		t.Errorf("save with an editor token: got status %d", code)
+	}
+
+	if rr := serve("POST", "/api/rooms/room/logout", "", editor.Data.Token); rr.Code != http.StatusOK {
+		t.Fatalf("logout: got status %d: %s", rr.Code, rr.Body)
+	}
+	if code := save(editor.Data.Token); code != http.StatusUnauthorized {
+		t.Errorf("save after logout: got status %d, want %d", code, http.StatusUnauthorized)
+	}
+	if rr := serve("POST", "/api/rooms/room/refresh", `{"refreshToken":"`+editor.Data.RefreshToken+`"}`, ""); rr.Code != http.StatusUnauthorized {
+		t.Errorf("refresh after logout: got status %d, want %d", rr.Code, http.StatusUnauthorized)
+	}
+
+	var grants struct {
+		Data []auth.RefreshToken `json:"data"`
+	}
+	decode(serve("GET", "/api/rooms/room/tokens", "", refreshed.Data.Token), &grants)
+	if len(grants.Data) != 2 || grants.Data[0].RevokedAt != nil || grants.Data[1].RevokedAt == nil {
+		t.Fatalf("listed grants %+v", grants.Data)
+	}
+
+	var viewer struct {
+		Data auth.TokenPair `json:"data"`
+	}
+	decode(serve("POST", "/api/rooms/room/tokens", `{"role":"viewer"}`, refreshed.Data.Token), &viewer)
+	if code := save(viewer.Data.Token); code != http.StatusForbidden {
+		t.Errorf("save with a viewer token: got status %d, want %d", code, http.StatusForbidden)
+	}
+	if rr := serve("DELETE", "/api/rooms/room/tokens/3", "", refreshed.Data.Token); rr.Code != http.StatusOK {
+		t.Fatalf("revoke: got status %d: %s", rr.Code, rr.Body)
+	}
+	if code := save(viewer.Data.Token); code != http.StatusUnauthorized {
+		t.Errorf("save with a revoked token: got status %d, want %d", code, http.StatusUnauthorized)
+	}
+	if rr := serve("DELETE", "/api/rooms/room/tokens/99", "", refreshed.Data.Token); rr.Code != http.StatusNotFound {
+		t.Errorf("revoking an unknown token: got status %d, want %d", rr.Code, http.StatusNotFound)
+	}
+}
+
+func TestRoomWritesNeedEditors(t *testing.T) {
+	repo := database.NewMemoryRepository()
+	tokens := auth.NewRoomTokens(repo, []byte("test-secret"))
+	router := mux.NewRouter()
+	api := router.PathPrefix("/api").Subrouter()
+	NewAlertHandler(analytics.NewAlertEngine(repo), repo, tokens).RegisterRoutes(api)
+	NewSignatureHandler(analytics.NewSignatureRegistry(repo), repo, tokens).RegisterRoutes(api)
+	NewRouletteHandler(repo, nil, tokens).RegisterRoutes(api)
+
+	if _, err := repo.CreateSessionWithPassword("room", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	editor := roomToken(t, tokens, "room", auth.RoomEditor)
+	viewer := roomToken(t, tokens, "room", auth.RoomViewer)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		token  string
+		want   int
+	}{
+		{"anonymous save", "POST", "/api/roulette/save", `{"key":"room","number":7}`, "", http.StatusUnauthorized},
+		{"viewer save", "POST", "/api/roulette/save", `{"key":"room","number":7}`, viewer, http.StatusForbidden},
+		{"editor save", "POST", "/api/roulette/save", `{"key":"room","number":7}`, editor, http.StatusOK},
+		{"viewer history", "PUT", "/api/roulette/room", `{"history":[1,2]}`, viewer, http.StatusForbidden},
+		{"anonymous rule", "POST", "/api/roulette/room/alerts", `{"kind":"absent","number":17,"threshold":5}`, "", http.StatusUnauthorized},
+		{"viewer rule", "POST", "/api/roulette/room/alerts", `{"kind":"absent","number":17,"threshold":5}`, viewer, http.StatusForbidden},
+		{"editor rule", "POST", "/api/roulette/room/alerts", `{"kind":"absent","number":17,"threshold":5}`, editor, http.StatusOK},
+		{"viewer delete", "DELETE", "/api/roulette/room/alerts/1", "", viewer, http.StatusForbidden},
+		{"editor delete", "DELETE", "/api/roulette/room/alerts/1", "", editor, http.StatusOK},
+		{"anonymous dealer", "POST", "/api/roulette/room/dealer", `{"dealer":"Ann"}`, "", http.StatusUnauthorized},
+		{"viewer dealer", "POST", "/api/roulette/room/dealer", `{"dealer":"Ann"}`, viewer, http.StatusForbidden},
+		{"editor dealer", "POST", "/api/roulette/room/dealer", `{"dealer":"Ann"}`, editor, http.StatusOK},
+		{"long dealer name", "POST", "/api/roulette/room/dealer", `{"dealer":"` + strings.Repeat("a", analytics.MaxDealerNameLength+1) + `"}`, editor, http.StatusBadRequest},
+		{"viewer seed rotation", "POST", "/api/roulette/room/provably-fair/rotate", "", viewer, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		if rr.Code != tt.want {
+			t.Errorf("%s: got status %d, want %d: %s", tt.name, rr.Code, tt.want, rr.Body)
+		}
+	}
+}
+
+func TestProtectedRoomReadsNeedTokens(t *testing.T) {
+	repo := database.NewMemoryRepository()
+	tokens := auth.NewRoomTokens(repo, []byte("test-secret"))
+	router := mux.NewRouter()
+	api := router.PathPrefix("/api").Subrouter()
+	NewAlertHandler(analytics.NewAlertEngine(repo), repo, tokens).RegisterRoutes(api)
+	NewSignatureHandler(analytics.NewSignatureRegistry(repo), repo, tokens).RegisterRoutes(api)
+	NewRouletteHandler(repo, nil, tokens).RegisterRoutes(api)
+
+	if _, err := repo.CreateSessionWithPassword("room", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.CreateSession("open"); err != nil {
+		t.Fatal(err)
+	}
+	viewer := roomToken(t, tokens, "room", auth.RoomViewer)
+	other := roomToken(t, tokens, "open", auth.RoomOwner)
+
+	for _, path := range []string{"", "/stats", "/forecast", "/fairness", "/transitions", "/bets", "/provably-fair", "/alerts", "/signature"} {
+		tests := []struct {
+			name  string
+			key   string
+			token string
+			want  int
+		}{
+			{"anonymous", "room", "", http.StatusUnauthorized},
+			{"other room's token", "room", other, http.StatusUnauthorized},
+			{"viewer", "room", viewer, http.StatusOK},
+			{"open room", "open", "", http.StatusOK},
+		}
+		for _, tt := range tests {
+			req := httptest.NewRequest("GET", "/api/roulette/"+tt.key+path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+			if rr.Code != tt.want {
+				t.Errorf("GET %q, %s: got status %d, want %d: %s", path, tt.name, rr.Code, tt.want, rr.Body)
+			}
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/api/roulette/missing/alerts", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("alert rules of a missing room: got status %d, want %d", rr.Code, http.StatusNotFound)
+	}
+}
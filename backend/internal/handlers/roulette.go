@@ -2,27 +2,35 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"time"
 
+	"casino-backend/internal/auth"
 	"casino-backend/internal/database"
 	"casino-backend/internal/models"
+	"casino-backend/pkg/websocket"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/gorilla/mux"
 )
 
+// RoomAuthResponse — токены входа в комнату, как при их продлении, и тип колеса комнаты
+type RoomAuthResponse struct {
+	auth.TokenPair
+	WheelType models.WheelType `json:"wheelType"`
+}
+
 type RouletteHandler struct {
-	repo      database.RouletteRepositoryInterface
-	jwtSecret []byte
+	roomAccess
+	wsHub *websocket.Hub
 }
 
 // NewRouletteHandler creates a new roulette handler
-func NewRouletteHandler(repo database.RouletteRepositoryInterface, jwtSecret string) *RouletteHandler {
+func NewRouletteHandler(repo database.RouletteRepositoryInterface, wsHub *websocket.Hub, tokens *auth.RoomTokens) *RouletteHandler {
 	return &RouletteHandler{
-		repo:      repo,
-		jwtSecret: []byte(jwtSecret),
+		roomAccess: roomAccess{repo: repo, tokens: tokens},
+		wsHub:      wsHub,
 	}
 }
 
@@ -30,7 +38,26 @@ func NewRouletteHandler(repo database.RouletteRepositoryInterface, jwtSecret str
 func (h *RouletteHandler) RegisterRoutes(r *mux.Router) {
 	r.HandleFunc("/roulette/sessions", h.GetSessions).Methods("GET", "OPTIONS")
 	r.HandleFunc("/rooms/auth", h.AuthenticateRoom).Methods("POST", "OPTIONS")
+	r.HandleFunc("/rooms/{key}/refresh", h.RefreshRoomToken).Methods("POST", "OPTIONS")
+	r.HandleFunc("/rooms/{key}/logout", h.Logout).Methods("POST", "OPTIONS")
+	r.HandleFunc("/rooms/{key}/tokens", h.GetRoomGrants).Methods("GET", "OPTIONS")
+	r.HandleFunc("/rooms/{key}/tokens", h.IssueRoomToken).Methods("POST", "OPTIONS")
+	r.HandleFunc("/rooms/{key}/tokens/{id}", h.RevokeRoomGrant).Methods("DELETE", "OPTIONS")
+	r.HandleFunc("/rooms/{key}/invites", h.GetInvites).Methods("GET", "OPTIONS")
+	r.HandleFunc("/rooms/{key}/invites", h.CreateInvite).Methods("POST", "OPTIONS")
+	r.HandleFunc("/rooms/{key}/invites/{id}", h.RevokeInvite).Methods("DELETE", "OPTIONS")
 	r.HandleFunc("/roulette/save", h.SaveNumber).Methods("POST", "OPTIONS")
+	r.HandleFunc("/roulette/{key}/stats", h.GetStats).Methods("GET", "OPTIONS")
+	r.HandleFunc("/roulette/{key}/forecast", h.GetForecast).Methods("GET", "OPTIONS")
+	r.HandleFunc("/roulette/{key}/fairness", h.GetFairness).Methods("GET", "OPTIONS")
+	r.HandleFunc("/roulette/{key}/transitions", h.GetTransitions).Methods("GET", "OPTIONS")
+	r.HandleFunc("/roulette/{key}/bets", h.GetBets).Methods("GET", "OPTIONS")
+	r.HandleFunc("/roulette/{key}/backtest", h.Backtest).Methods("POST", "OPTIONS")
+	r.HandleFunc("/roulette/{key}/montecarlo", h.MonteCarlo).Methods("POST", "OPTIONS")
+	r.HandleFunc("/roulette/{key}/provably-fair", h.GetProvablyFair).Methods("GET", "OPTIONS")
+	r.HandleFunc("/roulette/{key}/provably-fair/rotate", h.RotateSeed).Methods("POST", "OPTIONS")
+	r.HandleFunc("/roulette/{key}/provably-fair/spins", h.GetFairSpins).Methods("GET", "OPTIONS")
+	r.HandleFunc("/provably-fair/verify", h.VerifyFairSpin).Methods("POST", "OPTIONS")
 	r.HandleFunc("/roulette/{key}", h.GetHistory).Methods("GET", "OPTIONS")
 	r.HandleFunc("/roulette/{key}", h.UpdateHistory).Methods("PUT", "OPTIONS")
 }
@@ -47,11 +74,7 @@ func (h *RouletteHandler) AuthenticateRoom(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	var req struct {
-		Key      string `json:"key"`
-		Password string `json:"password"`
-	}
-
+	var req models.CreateSessionRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
@@ -62,6 +85,11 @@ func (h *RouletteHandler) AuthenticateRoom(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	if req.WheelType != "" && !req.WheelType.IsValid() {
+		http.Error(w, "Unknown wheel type", http.StatusBadRequest)
+		return
+	}
+
 	session, err := h.repo.GetSession(req.Key)
 	if err != nil {
 		http.Error(w, "Internal server error while getting session", http.StatusInternalServerError)
@@ -69,45 +97,106 @@ func (h *RouletteHandler) AuthenticateRoom(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	// Если сессии не существует, создаем ее
-	if session == nil {
+	// Если сессии не существует, создаем ее. Создатель комнаты становится ее владельцем.
+	role := auth.RoomEditor
+	switch {
+	case req.Invite != "":
+		// Приглашение заменяет пароль и задает роль
+		if session == nil {
+			http.Error(w, "Session not found", http.StatusNotFound)
+			return
+		}
+		invite, err := h.repo.RedeemInvite(req.Key, auth.HashInviteCode(req.Invite), time.Now())
+		if err != nil {
+			http.Error(w, "Internal server error during invite validation", http.StatusInternalServerError)
+			log.Printf("Error redeeming invite for session %s: %v", req.Key, err)
+			return
+		}
+		if invite == nil {
+			http.Error(w, "Invalid or expired invite", http.StatusUnauthorized)
+			return
+		}
+		log.Printf("Invite %d of session %s used, %d uses so far", invite.ID, req.Key, invite.Uses)
+		role = invite.Role
+	case session == nil:
+		// Пароль новой комнаты хешируется bcrypt, который читает не больше 72 байт.
+		// Более длинные пароли старых комнат проверяются как прежде.
+		if len(req.Password) > auth.MaxPasswordLength {
+			http.Error(w, "Password is too long", http.StatusBadRequest)
+			return
+		}
 		log.Printf("Session %s not found, creating new one.", req.Key)
-		session, err = h.repo.CreateSessionWithPassword(req.Key, req.Password)
+		var created bool
+		session, created, err = h.repo.CreateSessionFromRequest(req)
 		if err != nil {
 			http.Error(w, "Failed to create session", http.StatusInternalServerError)
 			log.Printf("Error creating session %s: %v", req.Key, err)
 			return
 		}
-	} else {
-		// Сессия существует, проверяем пароль, если он установлен
-		if session.Password != "" {
-			valid, err := h.repo.ValidateSessionPassword(req.Key, req.Password)
-			if err != nil {
-				http.Error(w, "Internal server error during password validation", http.StatusInternalServerError)
-				log.Printf("Error validating password for session %s: %v", req.Key, err)
-				return
-			}
-			if !valid {
-				http.Error(w, "Invalid password", http.StatusUnauthorized)
-				return
-			}
+		if created {
+			role = auth.RoomOwner
+			break
+		}
+		// Комнату одновременно создал другой клиент: входим в нее как в существующую
+		log.Printf("Session %s was created concurrently, entering it.", req.Key)
+		if session.Password != "" && !h.checkRoomPassword(w, req.Key, req.Password) {
+			return
+		}
+	case session.Password != "":
+		// Сессия существует, проверяем пароль
+		if !h.checkRoomPassword(w, req.Key, req.Password) {
+			return
 		}
 	}
 
-	// Генерируем JWT токен
-	claims := jwt.MapClaims{
-		"key": req.Key,
-		"exp": time.Now().Add(time.Hour * 24).Unix(), // Токен живет 24 часа
+	// Тип колеса существующей комнаты меняется, только пока ее история пуста
+	if req.WheelType != "" && req.WheelType != session.WheelType.OrDefault() {
+		if !role.CanEdit() {
+			http.Error(w, "Viewers cannot change the wheel type", http.StatusForbidden)
+			return
+		}
+		session, err = h.repo.SetSessionWheelType(req.Key, req.WheelType)
+		if errors.Is(err, database.ErrWheelTypeLocked) {
+			http.Error(w, "Room already has a history on another wheel type", http.StatusConflict)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Failed to change wheel type", http.StatusInternalServerError)
+			log.Printf("Error changing wheel type of session %s: %v", req.Key, err)
+			return
+		}
+		if h.wsHub != nil {
+			h.wsHub.HistoryReplaced(session)
+		}
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(h.jwtSecret)
+	// Выдаем короткоживущий access-токен с ролью в комнате и refresh-токен для его продления
+	pair, err := h.tokens.Issue(req.Key, role)
 	if err != nil {
 		http.Error(w, "Failed to create token", http.StatusInternalServerError)
+		log.Printf("Error issuing %s token for session %s: %v", role, req.Key, err)
 		return
 	}
 
-	json.NewEncoder(w).Encode(map[string]string{"token": tokenString})
+	writeJSON(w, models.APIResponse{
+		Success: true,
+		Data:    RoomAuthResponse{TokenPair: *pair, WheelType: session.WheelType},
+	})
+}
+
+// checkRoomPassword проверяет пароль защищенной комнаты и отвечает на запрос, если он неверен
+func (h *RouletteHandler) checkRoomPassword(w http.ResponseWriter, key, password string) bool {
+	valid, err := h.repo.ValidateSessionPassword(key, password)
+	if err != nil {
+		http.Error(w, "Internal server error during password validation", http.StatusInternalServerError)
+		log.Printf("Error validating password for session %s: %v", key, err)
+		return false
+	}
+	if !valid {
+		http.Error(w, "Invalid password", http.StatusUnauthorized)
+		return false
+	}
+	return true
 }
 
 // GetHistory handles GET /api/roulette/{key}
@@ -126,6 +215,9 @@ func (h *RouletteHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
+	if session != nil && !h.requireViewer(w, r, session) {
+		return
+	}
 
 	var history []models.RouletteNumber
 	if session != nil {
@@ -150,6 +242,10 @@ func (h *RouletteHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
 func (h *RouletteHandler) SaveNumber(w http.ResponseWriter, r *http.Request) {
 	var req models.SaveNumberRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		if errors.Is(err, models.ErrInvalidNumber) {
+			writeNumberError(w, err)
+			return
+		}
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
@@ -158,14 +254,25 @@ func (h *RouletteHandler) SaveNumber(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Key and number are required", http.StatusBadRequest)
 		return
 	}
+	if !h.requireEditor(w, r, req.Key) {
+		return
+	}
 
-	session, err := h.repo.AddNumberToSession(req.Key, req.Number)
+	session, err := h.repo.AddNumberToSession(req.Key, *req.Number)
+	if errors.Is(err, models.ErrInvalidNumber) {
+		writeNumberError(w, err)
+		return
+	}
 	if err != nil {
 		log.Printf("Error saving number: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
+	if h.wsHub != nil {
+		h.wsHub.NumberAdded(session, *req.Number)
+	}
+
 	response := models.APIResponse{
 		Success: true,
 		Data:    session,
@@ -191,24 +298,35 @@ func (h *RouletteHandler) UpdateHistory(w http.ResponseWriter, r *http.Request)
 
 	var req models.UpdateHistoryRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		if errors.Is(err, models.ErrInvalidNumber) {
+			writeNumberError(w, err)
+			return
+		}
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
 	req.Key = key // Ensure key from URL is used
-
-	if !isValidHistory(req.History) {
-		http.Error(w, "Invalid history format", http.StatusBadRequest)
+	if !h.requireEditor(w, r, req.Key) {
 		return
 	}
 
 	session, err := h.repo.UpdateSessionHistory(req.Key, req.History)
+	if errors.Is(err, models.ErrInvalidNumber) {
+		writeNumberError(w, err)
+		return
+	}
 	if err != nil {
 		log.Printf("Error updating history: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
+	// Connected clients hold stale history now, push the replacement to them
+	if h.wsHub != nil {
+		h.wsHub.HistoryReplaced(session)
+	}
+
 	response := models.APIResponse{
 		Success: true,
 		Data:    session,
@@ -251,34 +369,20 @@ func (h *RouletteHandler) HandleOptions(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusOK)
 }
 
-// Helper function to validate history format
-func isValidHistory(history []models.RouletteNumber) bool {
-	if history == nil {
-		return true // Empty history is valid
+// writeNumberError responds with 400 and the details of a rejected number
+func writeNumberError(w http.ResponseWriter, err error) {
+	response := models.APIResponse{
+		Success: false,
+		Error:   err.Error(),
 	}
 
-	for _, number := range history {
-		if !isValidRouletteNumber(number) {
-			return false
-		}
+	var numberErr *models.NumberError
+	if errors.As(err, &numberErr) {
+		response.Details = numberErr
 	}
-	return true
-}
 
-// Helper function to validate individual roulette number
-func isValidRouletteNumber(number models.RouletteNumber) bool {
-	switch v := number.(type) {
-	case float64:
-		// Check if it's a valid integer number between 0 and 36
-		if v != float64(int(v)) {
-			return false // Not an integer
-		}
-		intVal := int(v)
-		return intVal >= 0 && intVal <= 36
-	case string:
-		// Only "00" is allowed as string
-		return v == "00"
-	default:
-		return false
-	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(response)
 } 
\ No newline at end of file
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"casino-backend/internal/auth"
+	"casino-backend/internal/database"
+	"casino-backend/internal/models"
+)
+
+func TestSaveNumberRejectsInvalidNumbers(t *testing.T) {
+	repo := database.NewMemoryRepository()
+	handler := NewRouletteHandler(repo, nil, auth.NewRoomTokens(repo, []byte("test-secret")))
+
+	bodies := []string{
+		`{"key":"room","number":"abc"}`,
+		`{"key":"room","number":99}`,
+		`{"key":"room","number":-1}`,
+	}
+
+	for _, body := range bodies {
+		req := httptest.NewRequest("POST", "/api/roulette/save", strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		handler.SaveNumber(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("body %s: got status %d, want %d", body, rr.Code, http.StatusBadRequest)
+			continue
+		}
+
+		var response models.APIResponse
+		if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+			t.Fatalf("body %s: failed to decode response: %v", body, err)
+		}
+		if response.Success || response.Details == nil {
+			t.Errorf("body %s: expected structured error, got %+v", body, response)
+		}
+	}
+
+	session, err := repo.GetSession("room")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session != nil && len(session.History) != 0 {
+		t.Errorf("invalid numbers reached storage: %v", session.History)
+	}
+}
+
+func TestSaveNumberUsesSessionWheel(t *testing.T) {
+	repo := database.NewMemoryRepository()
+	handler := NewRouletteHandler(repo, nil, auth.NewRoomTokens(repo, []byte("test-secret")))
+
+	if _, _, err := repo.CreateSessionFromRequest(models.CreateSessionRequest{Key: "american", WheelType: models.WheelAmerican}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.CreateSession("european"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		key  string
+		want int
+	}{
+		{key: "american", want: http.StatusOK},
+		{key: "european", want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		body := `{"key":"` + tt.key + `","number":"00"}`
+		req := httptest.NewRequest("POST", "/api/roulette/save", strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		handler.SaveNumber(rr, req)
+
+		if rr.Code != tt.want {
+			t.Errorf("%s: got status %d, want %d: %s", tt.key, rr.Code, tt.want, rr.Body.String())
+		}
+	}
+
+	session, err := repo.GetSession("american")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(session.History) != 1 || session.History[0] != models.DoubleZero {
+		t.Errorf("unexpected history: %v", session.History)
+	}
+}
+
+func TestAuthenticateChangesWheelOfEmptyRooms(t *testing.T) {
+	repo := database.NewMemoryRepository()
+	handler := NewRouletteHandler(repo, nil, auth.NewRoomTokens(repo, []byte("test-secret")))
+
+	// Rooms joined over WebSocket are created on the default wheel
+	if _, err := repo.CreateSession("room"); err != nil {
+		t.Fatal(err)
+	}
+	authenticate := func(wheelType string) *httptest.ResponseRecorder {
+		body := `{"key":"room","wheel_type":"` + wheelType + `"}`
+		rr := httptest.NewRecorder()
+		handler.AuthenticateRoom(rr, httptest.NewRequest("POST", "/api/rooms/auth", strings.NewReader(body)))
+		return rr
+	}
+
+	rr := authenticate("american")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", rr.Code, rr.Body)
+	}
+	var response authResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	session, err := repo.GetSession("room")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Data.WheelType != models.WheelAmerican || session.WheelType != models.WheelAmerican {
+		t.Fatalf("wheel type %q answered, %q stored, want american", response.Data.WheelType, session.WheelType)
+	}
+
+	if _, err := repo.AddNumberToSession("room", models.DoubleZero); err != nil {
+		t.Fatal(err)
+	}
+	if rr := authenticate("triple_zero"); rr.Code != http.StatusConflict {
+		t.Errorf("other wheel for a room with history: got status %d, want %d", rr.Code, http.StatusConflict)
+	}
+	if rr := authenticate("american"); rr.Code != http.StatusOK {
+		t.Errorf("same wheel for a room with history: got status %d, want %d", rr.Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"casino-backend/internal/analytics"
+	"casino-backend/internal/auth"
+	"casino-backend/internal/database"
+	"casino-backend/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+// SignatureHandler serves the dealer signature analysis
+type SignatureHandler struct {
+	roomAccess
+	signatures *analytics.SignatureRegistry
+}
+
+// NewSignatureHandler creates a new dealer signature handler; only editors and owners start dealer segments
+func NewSignatureHandler(signatures *analytics.SignatureRegistry, repo database.RouletteRepositoryInterface, tokens *auth.RoomTokens) *SignatureHandler {
+	return &SignatureHandler{roomAccess: roomAccess{repo: repo, tokens: tokens}, signatures: signatures}
+}
+
+// RegisterRoutes registers the dealer signature routes
+func (h *SignatureHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/roulette/{key}/signature", h.GetSignature).Methods("GET", "OPTIONS")
+	r.HandleFunc("/roulette/{key}/dealer", h.StartDealer).Methods("POST", "OPTIONS")
+}
+
+// GetSignature handles GET /api/roulette/{key}/signature
+func (h *SignatureHandler) GetSignature(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.loadSession(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := h.signatures.Report(session.Key)
+	if err != nil {
+		log.Printf("Error building signature report of session %s: %v", session.Key, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if report == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, models.APIResponse{Success: true, Data: report})
+}
+
+// StartDealer handles POST /api/roulette/{key}/dealer with {"dealer": "name"}
+func (h *SignatureHandler) StartDealer(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+	if !h.requireEditor(w, r, key) {
+		return
+	}
+
+	var req struct {
+		Dealer string `json:"dealer"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.Dealer = strings.TrimSpace(req.Dealer)
+	if req.Dealer == "" {
+		http.Error(w, "Dealer is required", http.StatusBadRequest)
+		return
+	}
+
+	marker, err := h.signatures.StartDealer(key, req.Dealer)
+	if errors.Is(err, analytics.ErrInvalidDealer) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Error starting dealer segment of session %s: %v", key, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if marker == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, models.APIResponse{Success: true, Data: marker})
+}
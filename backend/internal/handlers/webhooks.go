@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"casino-backend/internal/database"
+	"casino-backend/internal/models"
+	"casino-backend/internal/webhooks"
+
+	"github.com/gorilla/mux"
+)
+
+// defaultDeliveryLimit is the number of delivery attempts listed by default
+const defaultDeliveryLimit = 50
+
+// WebhookHandler manages outgoing webhooks
+type WebhookHandler struct {
+	repo       database.RouletteRepositoryInterface
+	dispatcher *webhooks.Dispatcher
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(repo database.RouletteRepositoryInterface, dispatcher *webhooks.Dispatcher) *WebhookHandler {
+	return &WebhookHandler{repo: repo, dispatcher: dispatcher}
+}
+
+// RegisterRoutes registers the webhook routes on the admin router
+func (h *WebhookHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/webhooks", h.GetWebhooks).Methods("GET", "OPTIONS")
+	r.HandleFunc("/webhooks", h.CreateWebhook).Methods("POST", "OPTIONS")
+	r.HandleFunc("/webhooks/{id}", h.DeleteWebhook).Methods("DELETE", "OPTIONS")
+	r.HandleFunc("/webhooks/{id}/deliveries", h.GetDeliveries).Methods("GET", "OPTIONS")
+}
+
+// GetWebhooks handles GET /api/admin/webhooks
+func (h *WebhookHandler) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	hooks, err := h.dispatcher.Webhooks()
+	if err != nil {
+		log.Printf("Error getting webhooks: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, models.APIResponse{Success: true, Data: hooks})
+}
+
+// CreateWebhook handles POST /api/admin/webhooks. The secret is only returned here.
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var webhook webhooks.Webhook
+	if err := json.NewDecoder(r.Body).Decode(&webhook); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	webhook.ID = 0
+	webhook.CreatedAt = time.Now()
+
+	// An empty session key subscribes to every room
+	if webhook.SessionKey != "" {
+		session, err := h.repo.GetSession(webhook.SessionKey)
+		if err != nil {
+			log.Printf("Error getting session %s: %v", webhook.SessionKey, err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if session == nil {
+			http.Error(w, "Session not found", http.StatusNotFound)
+			return
+		}
+	}
+
+	created, err := h.dispatcher.CreateWebhook(&webhook)
+	if err != nil {
+		if errors.Is(err, webhooks.ErrInvalidWebhook) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Error creating webhook: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, models.APIResponse{Success: true, Data: created})
+}
+
+// DeleteWebhook handles DELETE /api/admin/webhooks/{id}
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookID(w, r)
+	if !ok {
+		return
+	}
+
+	deleted, err := h.dispatcher.DeleteWebhook(id)
+	if err != nil {
+		log.Printf("Error deleting webhook %d: %v", id, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !deleted {
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, models.APIResponse{Success: true})
+}
+
+// GetDeliveries handles GET /api/admin/webhooks/{id}/deliveries?limit=N
+func (h *WebhookHandler) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookID(w, r)
+	if !ok {
+		return
+	}
+	limit, err := intQueryParam(r, "limit", defaultDeliveryLimit)
+	if err != nil || limit < 0 {
+		http.Error(w, "Invalid limit (must be non-negative integer)", http.StatusBadRequest)
+		return
+	}
+
+	deliveries, err := h.dispatcher.Deliveries(id, limit)
+	if err != nil {
+		log.Printf("Error getting deliveries of webhook %d: %v", id, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, models.APIResponse{Success: true, Data: deliveries})
+}
+
+func webhookID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid webhook ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
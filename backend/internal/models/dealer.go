@@ -0,0 +1,7 @@
+package models
+
+// DealerMarker records that a dealer took over a room at a history index
+type DealerMarker struct {
+	Dealer string `json:"dealer"`
+	Start  int    `json:"start"`
+}
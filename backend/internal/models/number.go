@@ -0,0 +1,153 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidNumber is returned when a value is not a pocket of the wheel
+var ErrInvalidNumber = errors.New("invalid roulette number")
+
+// RouletteNumber represents a roulette pocket: 0-36, "00" or "000"
+type RouletteNumber int
+
+// DoubleZero is the "00" pocket of the American and triple-zero wheels
+const DoubleZero RouletteNumber = -1
+
+// TripleZero is the "000" pocket of the triple-zero wheel
+const TripleZero RouletteNumber = -2
+
+// MaxNumber is the highest numbered pocket on the wheel
+const MaxNumber RouletteNumber = 36
+
+// ParseRouletteNumber parses a pocket label such as "17", "00" or "000"
+func ParseRouletteNumber(s string) (RouletteNumber, error) {
+	s = strings.TrimSpace(s)
+	switch s {
+	case "00":
+		return DoubleZero, nil
+	case "000":
+		return TripleZero, nil
+	}
+
+	value, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, newNumberError(s, "not a number")
+	}
+
+	number := RouletteNumber(value)
+	if value < 0 || !number.IsValid() {
+		return 0, newNumberError(s, "not a pocket of the wheel")
+	}
+	return number, nil
+}
+
+// IsValid reports whether the number is a pocket of any supported wheel.
+// Use WheelType.Contains to check against a particular wheel.
+func (n RouletteNumber) IsValid() bool {
+	return n == DoubleZero || n == TripleZero || (n >= 0 && n <= MaxNumber)
+}
+
+// IsZero reports whether the number is one of the green zero pockets
+func (n RouletteNumber) IsZero() bool {
+	return n == 0 || n == DoubleZero || n == TripleZero
+}
+
+// String returns the pocket label as shown on the table
+func (n RouletteNumber) String() string {
+	switch n {
+	case DoubleZero:
+		return "00"
+	case TripleZero:
+		return "000"
+	}
+	return strconv.Itoa(int(n))
+}
+
+// MarshalJSON encodes numbered pockets as JSON numbers and "00"/"000" as strings
+func (n RouletteNumber) MarshalJSON() ([]byte, error) {
+	if !n.IsValid() {
+		return nil, newNumberError(strconv.Itoa(int(n)), "not a pocket of the wheel")
+	}
+	if n.IsZero() && n != 0 {
+		return []byte(`"` + n.String() + `"`), nil
+	}
+	return []byte(strconv.Itoa(int(n))), nil
+}
+
+// UnmarshalJSON accepts both JSON numbers (5) and strings ("00", "000", "5")
+func (n *RouletteNumber) UnmarshalJSON(data []byte) error {
+	raw := strings.TrimSpace(string(data))
+	if raw == "null" {
+		return newNumberError(raw, "number is required")
+	}
+
+	if strings.HasPrefix(raw, `"`) {
+		var label string
+		if err := json.Unmarshal(data, &label); err != nil {
+			return newNumberError(raw, "malformed string")
+		}
+		parsed, err := ParseRouletteNumber(label)
+		if err != nil {
+			return err
+		}
+		*n = parsed
+		return nil
+	}
+
+	var value float64
+	if err := json.Unmarshal(data, &value); err != nil {
+		return newNumberError(raw, "expected a number or a string")
+	}
+	if value != float64(int(value)) {
+		return newNumberError(raw, "not an integer")
+	}
+
+	number := RouletteNumber(int(value))
+	if value < 0 || !number.IsValid() {
+		return newNumberError(raw, "not a pocket of the wheel")
+	}
+	*n = number
+	return nil
+}
+
+// Value stores the number in the JSON text format used by roulette_numbers.number
+func (n RouletteNumber) Value() (driver.Value, error) {
+	data, err := n.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan reads a number stored as JSON text ("5" or "\"00\"") or as an integer
+func (n *RouletteNumber) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case int64:
+		number := RouletteNumber(v)
+		if v < 0 || !number.IsValid() {
+			return newNumberError(fmt.Sprintf("%d", v), "not a pocket of the wheel")
+		}
+		*n = number
+		return nil
+	case []byte:
+		return n.scanText(string(v))
+	case string:
+		return n.scanText(v)
+	default:
+		return fmt.Errorf("cannot scan %T into RouletteNumber", src)
+	}
+}
+
+func (n *RouletteNumber) scanText(text string) error {
+	parsed, err := ParseRouletteNumber(strings.Trim(strings.TrimSpace(text), `"`))
+	if err != nil {
+		return err
+	}
+	*n = parsed
+	return nil
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRouletteNumberJSON(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    RouletteNumber
+		wantErr bool
+	}{
+		{input: `5`, want: 5},
+		{input: `0`, want: 0},
+		{input: `36`, want: 36},
+		{input: `"00"`, want: DoubleZero},
+		{input: `"17"`, want: 17},
+		{input: `37`, wantErr: true},
+		{input: `-1`, wantErr: true},
+		{input: `2.5`, wantErr: true},
+		{input: `"abc"`, wantErr: true},
+		{input: `null`, wantErr: true},
+		{input: `true`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		var got RouletteNumber
+		err := json.Unmarshal([]byte(tt.input), &got)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Unmarshal(%s) expected error, got %v", tt.input, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unmarshal(%s) returned error: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Unmarshal(%s) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+
+	data, err := json.Marshal([]RouletteNumber{5, DoubleZero, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `[5,"00",0]`; string(data) != expected {
+		t.Errorf("Marshal returned %s, want %s", data, expected)
+	}
+}
+
+func TestRouletteNumberSQL(t *testing.T) {
+	for _, number := range []RouletteNumber{0, 17, DoubleZero} {
+		value, err := number.Value()
+		if err != nil {
+			t.Fatalf("Value(%v) returned error: %v", number, err)
+		}
+
+		var scanned RouletteNumber
+		if err := scanned.Scan(value); err != nil {
+			t.Fatalf("Scan(%v) returned error: %v", value, err)
+		}
+		if scanned != number {
+			t.Errorf("round trip of %v returned %v", number, scanned)
+		}
+	}
+
+	var legacy RouletteNumber
+	if err := legacy.Scan([]byte(`"00"`)); err != nil || legacy != DoubleZero {
+		t.Errorf("Scan of legacy \"00\" returned %v, %v", legacy, err)
+	}
+
+	if _, err := RouletteNumber(99).Value(); err == nil {
+		t.Error("Value of an invalid number should fail")
+	}
+}
@@ -1,15 +1,18 @@
 package models
 
-import "time"
-
-// RouletteNumber represents a roulette number (0-36 or "00")
-type RouletteNumber interface{}
+import (
+	"encoding/json"
+	"time"
+)
 
 // RouletteSession represents a roulette game session
 type RouletteSession struct {
 	ID        int              `json:"id"`
 	Key       string           `json:"key"`
-	Password  string           `json:"password,omitempty"` // Пароль для входа в комнату
+	Password  string           `json:"-"` // bcrypt-хеш пароля для входа в комнату
+	WheelType WheelType        `json:"wheel_type"`
+	Practice  bool             `json:"practice"` // Тренировочная комната: доступен автоспиннер
+	Revision  int              `json:"revision"` // Растёт при каждом удалении или замене чисел истории
 	History   []RouletteNumber `json:"history"`
 	CreatedAt time.Time        `json:"created_at"`
 	UpdatedAt time.Time        `json:"updated_at"`
@@ -17,23 +20,26 @@ type RouletteSession struct {
 
 // RouletteNumberRecord represents a number record in database
 type RouletteNumberRecord struct {
-	ID        int              `json:"id" db:"id"`
-	SessionID int              `json:"session_id" db:"session_id"`
-	Number    RouletteNumber   `json:"number" db:"number"`
-	Position  int              `json:"position" db:"position"`
-	CreatedAt time.Time        `json:"created_at" db:"created_at"`
+	ID        int            `json:"id" db:"id"`
+	SessionID int            `json:"session_id" db:"session_id"`
+	Number    RouletteNumber `json:"number" db:"number"`
+	Position  int            `json:"position" db:"position"`
+	CreatedAt time.Time      `json:"created_at" db:"created_at"`
 }
 
 // CreateSessionRequest represents request to create session
 type CreateSessionRequest struct {
-	Key      string `json:"key"`
-	Password string `json:"password,omitempty"` // Опциональный пароль
+	Key       string    `json:"key"`
+	Password  string    `json:"password,omitempty"`   // Опциональный пароль
+	Invite    string    `json:"invite,omitempty"`     // Код приглашения вместо пароля
+	WheelType WheelType `json:"wheel_type,omitempty"` // По умолчанию европейское колесо
+	Practice  bool      `json:"practice,omitempty"`   // Задаётся только при создании комнаты
 }
 
 // SaveNumberRequest represents the request to save a number
 type SaveNumberRequest struct {
-	Key    string         `json:"key"`
-	Number RouletteNumber `json:"number"`
+	Key    string          `json:"key"`
+	Number *RouletteNumber `json:"number"`
 }
 
 // UpdateHistoryRequest represents the request to update history
@@ -44,22 +50,49 @@ type UpdateHistoryRequest struct {
 
 // APIResponse represents a generic API response
 type APIResponse struct {
-	Success bool        `json:"success"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
+	Success bool         `json:"success"`
+	Data    interface{}  `json:"data,omitempty"`
+	Error   string       `json:"error,omitempty"`
+	Details *NumberError `json:"details,omitempty"` // Set when a number was rejected by validation
 }
 
 // WebSocket Message types
 
 // WSMessage represents a WebSocket message
 type WSMessage struct {
-	Type    string           `json:"type"`
-	Key     string           `json:"key,omitempty"`
-	Token   string           `json:"token,omitempty"`
-	History []RouletteNumber `json:"history,omitempty"`
-	Number  *RouletteNumber  `json:"number,omitempty"`
-	Error   string           `json:"error,omitempty"`
-	Version int              `json:"version,omitempty"` // Client's history version
-	Full    bool             `json:"full,omitempty"`    // Indicates if the history is a full sync
-	Index   int              `json:"index,omitempty"`   // Index for remove operations
-} 
\ No newline at end of file
+	Type     string           `json:"type"`
+	Key      string           `json:"key,omitempty"`
+	Token    string           `json:"token,omitempty"`
+	History  []RouletteNumber `json:"history,omitempty"`
+	Number   *RouletteNumber  `json:"number,omitempty"`
+	Error    string           `json:"error,omitempty"`
+	Version  int              `json:"version,omitempty"`  // Client's history version
+	Full     bool             `json:"full,omitempty"`     // Indicates if the history is a full sync
+	Index    int              `json:"index,omitempty"`    // Index for remove operations
+	Revision int              `json:"revision,omitempty"` // Bumped whenever existing history is edited
+	Details  *NumberError     `json:"details,omitempty"`  // Set on error frames for rejected numbers
+	Data     interface{}      `json:"data,omitempty"`     // Payload of server-pushed messages (forecast, ...)
+	Slip     json.RawMessage  `json:"slip,omitempty"`     // Bet slip of 'bet' messages, decoded by the betting package
+	PlayerID string           `json:"playerId,omitempty"` // Player ID of the room token, echoed in the first sync; a join may repeat it but not pick another
+	Amount   int64            `json:"amount,omitempty"`   // Starting balance of 'bankroll' messages
+	Spinner  *SpinnerControl  `json:"spinner,omitempty"`  // Payload of 'spinner' control messages
+}
+
+// Virtual spinner actions
+const (
+	SpinnerStart  = "start"
+	SpinnerPause  = "pause"
+	SpinnerResume = "resume"
+	SpinnerStop   = "stop"
+	SpinnerStatus = "status"
+)
+
+// SpinnerControl starts, reconfigures or stops the server-side spinner of a practice room
+type SpinnerControl struct {
+	Action     string    `json:"action"`
+	Interval   int       `json:"interval,omitempty"`   // Milliseconds between spins
+	WheelType  WheelType `json:"wheelType,omitempty"`  // Accepted while the room has no history
+	Seed       *int64    `json:"seed,omitempty"`       // Reproducible spins; crypto/rand when absent
+	Fair       bool      `json:"fair,omitempty"`       // Derive spins from the room's committed seed
+	ClientSeed string    `json:"clientSeed,omitempty"` // Client seed of a new commitment when the room has none
+}
@@ -0,0 +1,52 @@
+package models
+
+import "fmt"
+
+// NumberError describes a value rejected by number validation. It is
+// returned to clients as-is, both in HTTP 400 responses and WS error frames.
+type NumberError struct {
+	Value  string `json:"value"`
+	Index  *int   `json:"index,omitempty"` // Position in history, when validating a whole history
+	Reason string `json:"reason"`
+}
+
+func newNumberError(value, reason string) *NumberError {
+	return &NumberError{Value: value, Reason: reason}
+}
+
+// Error implements the error interface
+func (e *NumberError) Error() string {
+	if e.Index != nil {
+		return fmt.Sprintf("%s at position %d: %s (%s)", ErrInvalidNumber, *e.Index, e.Value, e.Reason)
+	}
+	return fmt.Sprintf("%s: %s (%s)", ErrInvalidNumber, e.Value, e.Reason)
+}
+
+// Unwrap allows errors.Is(err, ErrInvalidNumber)
+func (e *NumberError) Unwrap() error {
+	return ErrInvalidNumber
+}
+
+// ValidateNumber checks that a number is a pocket of the session's wheel
+func ValidateNumber(wheel WheelType, number RouletteNumber) error {
+	if !number.IsValid() {
+		return newNumberError(fmt.Sprintf("%d", int(number)), "not a pocket of the wheel")
+	}
+	if !wheel.Contains(number) {
+		return newNumberError(number.String(), fmt.Sprintf("not a pocket of the %s wheel", wheel.OrDefault()))
+	}
+	return nil
+}
+
+// ValidateHistory checks every number of a history and reports the first invalid position
+func ValidateHistory(wheel WheelType, history []RouletteNumber) error {
+	for i, number := range history {
+		if err := ValidateNumber(wheel, number); err != nil {
+			index := i
+			numberErr := err.(*NumberError)
+			numberErr.Index = &index
+			return numberErr
+		}
+	}
+	return nil
+}
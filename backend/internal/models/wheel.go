@@ -0,0 +1,87 @@
+package models
+
+import "fmt"
+
+// WheelType identifies the roulette wheel variant used by a session
+type WheelType string
+
+const (
+	// WheelEuropean is the single-zero wheel: 0-36
+	WheelEuropean WheelType = "european"
+	// WheelAmerican is the double-zero wheel: 0, 00, 1-36
+	WheelAmerican WheelType = "american"
+	// WheelTripleZero is the triple-zero wheel: 0, 00, 000, 1-36
+	WheelTripleZero WheelType = "triple_zero"
+)
+
+// DefaultWheel is used for sessions created without an explicit wheel type
+const DefaultWheel = WheelEuropean
+
+// ParseWheelType validates a wheel type, falling back to DefaultWheel when empty
+func ParseWheelType(s string) (WheelType, error) {
+	if s == "" {
+		return DefaultWheel, nil
+	}
+	wheel := WheelType(s)
+	if !wheel.IsValid() {
+		return "", fmt.Errorf("unknown wheel type %q", s)
+	}
+	return wheel, nil
+}
+
+// IsValid reports whether the wheel type is supported
+func (w WheelType) IsValid() bool {
+	switch w {
+	case WheelEuropean, WheelAmerican, WheelTripleZero:
+		return true
+	default:
+		return false
+	}
+}
+
+// OrDefault returns the wheel type, or DefaultWheel for unset values
+func (w WheelType) OrDefault() WheelType {
+	if w == "" {
+		return DefaultWheel
+	}
+	return w
+}
+
+// Zeros returns the green pockets of the wheel
+func (w WheelType) Zeros() []RouletteNumber {
+	switch w.OrDefault() {
+	case WheelAmerican:
+		return []RouletteNumber{0, DoubleZero}
+	case WheelTripleZero:
+		return []RouletteNumber{0, DoubleZero, TripleZero}
+	default:
+		return []RouletteNumber{0}
+	}
+}
+
+// Pockets returns every pocket of the wheel, zeros first
+func (w WheelType) Pockets() []RouletteNumber {
+	pockets := w.Zeros()
+	for n := RouletteNumber(1); n <= MaxNumber; n++ {
+		pockets = append(pockets, n)
+	}
+	return pockets
+}
+
+// PocketCount returns the number of pockets, the denominator of every probability on this wheel
+func (w WheelType) PocketCount() int {
+	return int(MaxNumber) + len(w.Zeros())
+}
+
+// Contains reports whether the number is a pocket of this wheel
+func (w WheelType) Contains(n RouletteNumber) bool {
+	if n >= 0 && n <= MaxNumber {
+		return true
+	}
+	for _, zero := range w.Zeros() {
+		if zero == n {
+			return true
+		}
+	}
+	return false
+}
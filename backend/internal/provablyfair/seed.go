@@ -0,0 +1,151 @@
+package provablyfair
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"casino-backend/internal/models"
+	"casino-backend/internal/wheel"
+)
+
+// ErrInvalidSeed is returned for client seeds that cannot be stored
+var ErrInvalidSeed = errors.New("invalid seed")
+
+// MaxClientSeedLength matches the client_seed column of the fair_seeds table
+const MaxClientSeedLength = 64
+
+// Seed is a server seed committed to by its hash before any spin derived
+// from it. The server seed stays secret until the seed is rotated out.
+type Seed struct {
+	ID             int64      `json:"id"`
+	SessionKey     string     `json:"sessionKey"`
+	ServerSeed     string     `json:"serverSeed,omitempty"` // Only set once revealed
+	ServerSeedHash string     `json:"serverSeedHash"`       // Hex SHA-256 of the server seed
+	ClientSeed     string     `json:"clientSeed"`
+	Nonce          int        `json:"nonce"` // Nonce of the next spin
+	CreatedAt      time.Time  `json:"createdAt"`
+	RevealedAt     *time.Time `json:"revealedAt,omitempty"`
+}
+
+// Record ties a spin of the history to the seed and nonce it was derived from
+type Record struct {
+	SeedID     int64                 `json:"seedId"`
+	SessionKey string                `json:"sessionKey"`
+	Nonce      int                   `json:"nonce"`
+	SpinIndex  int                   `json:"spinIndex"` // Position in the history when the spin was added
+	Number     models.RouletteNumber `json:"number"`
+	CreatedAt  time.Time             `json:"createdAt"`
+}
+
+// NewSeed draws a server seed for a session. An empty client seed is
+// replaced by a random one.
+func NewSeed(sessionKey, clientSeed string) (*Seed, error) {
+	if len(clientSeed) > MaxClientSeedLength {
+		return nil, fmt.Errorf("%w: client seed is longer than %d characters", ErrInvalidSeed, MaxClientSeedLength)
+	}
+	serverSeed, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+	if clientSeed == "" {
+		if clientSeed, err = randomHex(16); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Seed{
+		SessionKey:     sessionKey,
+		ServerSeed:     serverSeed,
+		ServerSeedHash: HashSeed(serverSeed),
+		ClientSeed:     clientSeed,
+		CreatedAt:      time.Now(),
+	}, nil
+}
+
+// Revealed reports whether the server seed has been published
+func (s *Seed) Revealed() bool {
+	return s.RevealedAt != nil
+}
+
+// Public returns a copy that hides the server seed until it is revealed
+func (s *Seed) Public() *Seed {
+	public := *s
+	if !s.Revealed() {
+		public.ServerSeed = ""
+	}
+	return &public
+}
+
+// HashSeed returns the commitment published for a server seed
+func HashSeed(serverSeed string) string {
+	sum := sha256.Sum256([]byte(serverSeed))
+	return hex.EncodeToString(sum[:])
+}
+
+// Spin derives a pocket from HMAC-SHA256(serverSeed, "clientSeed:nonce:round").
+// Each 4-byte word of the digest is an unsigned big-endian index candidate;
+// words that would bias the result are skipped, and a new round is hashed if
+// the whole digest is rejected. The index selects from wheel.Pockets order.
+func Spin(wheelType models.WheelType, serverSeed, clientSeed string, nonce int) (models.RouletteNumber, error) {
+	return wheel.Spin(wheelType, &source{serverSeed: serverSeed, clientSeed: clientSeed, nonce: nonce})
+}
+
+// source is a wheel.Source for a single spin
+type source struct {
+	serverSeed string
+	clientSeed string
+	nonce      int
+}
+
+func (s *source) Pick(n int) (int, error) {
+	// Largest multiple of n that fits in 32 bits
+	limit := uint64(1<<32) - uint64(1<<32)%uint64(n)
+	for round := 0; ; round++ {
+		mac := hmac.New(sha256.New, []byte(s.serverSeed))
+		fmt.Fprintf(mac, "%s:%d:%d", s.clientSeed, s.nonce, round)
+		digest := mac.Sum(nil)
+		for i := 0; i+4 <= len(digest); i += 4 {
+			if value := uint64(binary.BigEndian.Uint32(digest[i:])); value < limit {
+				return int(value % uint64(n)), nil
+			}
+		}
+	}
+}
+
+// Verification is the result of checking a revealed seed
+type Verification struct {
+	HashMatches bool                  `json:"hashMatches"` // The server seed matches the published hash
+	Number      models.RouletteNumber `json:"number"`      // Pocket derived from the seeds and nonce
+}
+
+// Verify recomputes a spin from a revealed server seed and checks it against its commitment
+func Verify(wheelType models.WheelType, serverSeed, serverSeedHash, clientSeed string, nonce int) (*Verification, error) {
+	if !wheelType.OrDefault().IsValid() {
+		return nil, fmt.Errorf("unknown wheel type %q", wheelType)
+	}
+	if nonce < 0 {
+		return nil, fmt.Errorf("nonce must be non-negative, got %d", nonce)
+	}
+	number, err := Spin(wheelType, serverSeed, clientSeed, nonce)
+	if err != nil {
+		return nil, err
+	}
+	return &Verification{
+		HashMatches: hmac.Equal([]byte(HashSeed(serverSeed)), []byte(serverSeedHash)),
+		Number:      number,
+	}, nil
+}
+
+func randomHex(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate seed: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
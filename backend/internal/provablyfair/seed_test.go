@@ -0,0 +1,106 @@
+package provablyfair
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"casino-backend/internal/models"
+)
+
+func TestSpinIsDeterministicAndUniform(t *testing.T) {
+	const spins = 37000
+	counts := make(map[models.RouletteNumber]int)
+	for nonce := 0; nonce < spins; nonce++ {
+		number, err := Spin(models.WheelEuropean, "server", "client", nonce)
+		if err != nil {
+			t.Fatal(err)
+		}
+		again, _ := Spin(models.WheelEuropean, "server", "client", nonce)
+		if number != again {
+			t.Fatalf("nonce %d gave %s then %s", nonce, number, again)
+		}
+		counts[number]++
+	}
+
+	if len(counts) != 37 {
+		t.Fatalf("expected all 37 pockets, got %d", len(counts))
+	}
+	for number, count := range counts {
+		// 1000 expected per pocket; 5 standard deviations is about 160
+		if count < 840 || count > 1160 {
+			t.Errorf("pocket %s drawn %d times", number, count)
+		}
+	}
+
+	other, _ := Spin(models.WheelEuropean, "server", "other", 0)
+	same, _ := Spin(models.WheelEuropean, "server", "client", 0)
+	different := other != same
+	for nonce := 1; nonce < 10 && !different; nonce++ {
+		a, _ := Spin(models.WheelEuropean, "server", "other", nonce)
+		b, _ := Spin(models.WheelEuropean, "server", "client", nonce)
+		different = a != b
+	}
+	if !different {
+		t.Error("client seed does not change the spins")
+	}
+}
+
+func TestSeedCommitment(t *testing.T) {
+	seed, err := NewSeed("room", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(seed.ServerSeed) != 64 || seed.ClientSeed == "" || seed.ServerSeedHash != HashSeed(seed.ServerSeed) {
+		t.Fatalf("unexpected seed %+v", seed)
+	}
+	if public := seed.Public(); public.ServerSeed != "" || public.ServerSeedHash != seed.ServerSeedHash {
+		t.Errorf("active seed leaks its server seed: %+v", public)
+	}
+	revealedAt := seed.CreatedAt
+	seed.RevealedAt = &revealedAt
+	if seed.Public().ServerSeed != seed.ServerSeed {
+		t.Error("revealed seed hides its server seed")
+	}
+
+	if _, err := NewSeed("room", strings.Repeat("x", MaxClientSeedLength+1)); !errors.Is(err, ErrInvalidSeed) {
+		t.Errorf("expected ErrInvalidSeed, got %v", err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	serverSeed := "revealed"
+	want, _ := Spin(models.WheelAmerican, serverSeed, "client", 5)
+
+	tests := []struct {
+		name      string
+		hash      string
+		wheel     models.WheelType
+		nonce     int
+		wantMatch bool
+		wantErr   bool
+	}{
+		{name: "matching commitment", hash: HashSeed(serverSeed), wheel: models.WheelAmerican, nonce: 5, wantMatch: true},
+		{name: "wrong commitment", hash: HashSeed("other"), wheel: models.WheelAmerican, nonce: 5},
+		{name: "negative nonce", hash: HashSeed(serverSeed), wheel: models.WheelAmerican, nonce: -1, wantErr: true},
+		{name: "unknown wheel", hash: HashSeed(serverSeed), wheel: "french", nonce: 5, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verification, err := Verify(tt.wheel, serverSeed, tt.hash, "client", tt.nonce)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if verification.HashMatches != tt.wantMatch || verification.Number != want {
+				t.Errorf("got %+v, want match %v and number %s", verification, tt.wantMatch, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package simulation
+
+import (
+	"fmt"
+
+	"casino-backend/internal/betting"
+	"casino-backend/internal/models"
+)
+
+// Config holds the bankroll and table limits of a run
+type Config struct {
+	Wheel    models.WheelType `json:"wheelType"`
+	Bankroll int64            `json:"bankroll"`
+	MinBet   int64            `json:"minBet"` // 1 by default
+	MaxBet   int64            `json:"maxBet"` // 0 means no table maximum
+}
+
+// WithDefaults fills in the unset limits
+func (c Config) WithDefaults() Config {
+	c.Wheel = c.Wheel.OrDefault()
+	if c.MinBet == 0 {
+		c.MinBet = 1
+	}
+	return c
+}
+
+// Validate checks the bankroll and the limits
+func (c Config) Validate() error {
+	if !c.Wheel.IsValid() {
+		return fmt.Errorf("unknown wheel type %q", c.Wheel)
+	}
+	if c.MinBet <= 0 {
+		return fmt.Errorf("minBet must be positive, got %d", c.MinBet)
+	}
+	if c.MaxBet != 0 && c.MaxBet < c.MinBet {
+		return fmt.Errorf("maxBet %d is below minBet %d", c.MaxBet, c.MinBet)
+	}
+	if c.Bankroll < c.MinBet {
+		return fmt.Errorf("bankroll %d does not cover minBet %d", c.Bankroll, c.MinBet)
+	}
+	return nil
+}
+
+// stake fits the stake of a bet into the table limits and the balance,
+// keeping racetrack bets a whole number of their chips. The second value
+// reports whether the table maximum cut the stake; a zero stake means that
+// no stake within the limits and the balance is left for the bet.
+func (c Config) stake(bet betting.Bet, balance int64) (int64, bool, error) {
+	units := int64(1)
+	if bet.Type.IsRacetrack() {
+		var err error
+		if units, err = betting.Units(bet); err != nil {
+			return 0, false, err
+		}
+	}
+	roundDown := func(amount int64) int64 { return amount - amount%units }
+
+	amount, limited := bet.Amount, false
+	minimum := roundDown(c.MinBet + units - 1)
+	if amount < minimum {
+		amount = minimum
+	}
+	if c.MaxBet > 0 && amount > c.MaxBet {
+		amount, limited = c.MaxBet, true
+	}
+	if amount > balance {
+		amount = balance
+	}
+	amount = roundDown(amount)
+	if amount < minimum {
+		return 0, limited, nil
+	}
+	return amount, limited, nil
+}
+
+// Point is one step of the equity curve
+type Point struct {
+	Spin    int                   `json:"spin"`
+	Number  models.RouletteNumber `json:"number"`
+	Stake   int64                 `json:"stake"`
+	Net     int64                 `json:"net"`
+	Balance int64                 `json:"balance"`
+}
+
+// Summary aggregates a run
+type Summary struct {
+	Spins               int     `json:"spins"` // Spins played before the end of history or the bust
+	Bets                int     `json:"bets"`
+	Wins                int     `json:"wins"`
+	Losses              int     `json:"losses"`
+	StartingBalance     int64   `json:"startingBalance"`
+	FinalBalance        int64   `json:"finalBalance"`
+	Peak                int64   `json:"peak"`
+	MaxDrawdown         int64   `json:"maxDrawdown"` // Largest drop from a previous peak, in chips
+	Staked              int64   `json:"staked"`
+	Returned            int64   `json:"returned"`
+	Net                 int64   `json:"net"`
+	ROI                 float64 `json:"roi"` // Net result divided by the total stake
+	LargestStake        int64   `json:"largestStake"`
+	LongestLosingStreak int     `json:"longestLosingStreak"`
+	LimitHits           int     `json:"limitHits"` // Bets cut down to the table maximum
+}
+
+// Result is the outcome of one strategy over a sequence of spins
+type Result struct {
+	Strategy string  `json:"strategy"`
+	Config   Config  `json:"config"`
+	Busted   bool    `json:"busted"`
+	BustSpin int     `json:"bustSpin"` // Spin after which the balance no longer covered minBet, or whose bet it could not cover; -1 if never
+	Summary  Summary `json:"summary"`
+	Equity   []Point `json:"equity,omitempty"`
+}
+
+// Run plays a strategy through a sequence of spins until the spins run out
+// or the balance drops below the table minimum. The equity curve is only
+// kept when withEquity is set.
+func Run(strategy Strategy, config Config, spins []models.RouletteNumber, withEquity bool) (*Result, error) {
+	config = config.WithDefaults()
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return run(strategy, config, spins, withEquity, newPayoutTables(config.Wheel))
+}
+
+// run is Run on a validated config with payout tables that may be shared between runs
+func run(strategy Strategy, config Config, spins []models.RouletteNumber, withEquity bool, payouts *payoutTables) (*Result, error) {
+	result := &Result{
+		Strategy: strategy.Name(),
+		Config:   config,
+		BustSpin: -1,
+		Summary: Summary{
+			StartingBalance: config.Bankroll,
+			Peak:            config.Bankroll,
+		},
+	}
+	if withEquity {
+		result.Equity = make([]Point, 0, len(spins))
+	}
+
+	summary := &result.Summary
+	balance := config.Bankroll
+	losingStreak := 0
+
+	for i, number := range spins {
+		round := Round{Spin: i, Number: number}
+		table := Table{
+			Wheel:   config.Wheel,
+			History: spins[:i],
+			Balance: balance,
+			MinBet:  config.MinBet,
+			MaxBet:  config.MaxBet,
+		}
+
+		if bet, ok := strategy.NextBet(table); ok {
+			amount, limited, err := config.stake(bet, balance)
+			if err != nil {
+				return nil, fmt.Errorf("%s at spin %d: %w", strategy.Name(), i, err)
+			}
+			if amount == 0 {
+				// The balance or the table maximum no longer covers one
+				// chip on every pocket of the bet
+				result.Busted = true
+				result.BustSpin = i
+				break
+			}
+			bet.Amount = amount
+			returned, err := payouts.returned(bet, number)
+			if err != nil {
+				return nil, fmt.Errorf("%s at spin %d: %w", strategy.Name(), i, err)
+			}
+
+			round.Bet, round.Placed, round.Net = bet, true, returned-bet.Amount
+			summary.Bets++
+			summary.Staked += bet.Amount
+			summary.Returned += returned
+			if bet.Amount > summary.LargestStake {
+				summary.LargestStake = bet.Amount
+			}
+			if limited {
+				summary.LimitHits++
+			}
+		}
+
+		balance += round.Net
+		round.Balance = balance
+		summary.Spins++
+
+		switch {
+		case round.Won():
+			summary.Wins++
+			losingStreak = 0
+		case round.Lost():
+			summary.Losses++
+			losingStreak++
+			if losingStreak > summary.LongestLosingStreak {
+				summary.LongestLosingStreak = losingStreak
+			}
+		}
+		if balance > summary.Peak {
+			summary.Peak = balance
+		}
+		if drawdown := summary.Peak - balance; drawdown > summary.MaxDrawdown {
+			summary.MaxDrawdown = drawdown
+		}
+		if withEquity {
+			result.Equity = append(result.Equity, Point{
+				Spin:    i,
+				Number:  number,
+				Stake:   round.Bet.Amount,
+				Net:     round.Net,
+				Balance: balance,
+			})
+		}
+
+		strategy.Record(round)
+
+		if balance < config.MinBet {
+			result.Busted = true
+			result.BustSpin = i
+			break
+		}
+	}
+
+	summary.FinalBalance = balance
+	summary.Net = balance - config.Bankroll
+	if summary.Staked > 0 {
+		summary.ROI = float64(summary.Returned-summary.Staked) / float64(summary.Staked)
+	}
+	return result, nil
+}
+
+// Backtest replays a stored history through each strategy. Without specs
+// every registered strategy is played with default parameters.
+func Backtest(specs []Spec, config Config, history []models.RouletteNumber) ([]*Result, error) {
+	if len(specs) == 0 {
+		for _, name := range Names() {
+			specs = append(specs, Spec{Name: name})
+		}
+	}
+
+	results := make([]*Result, 0, len(specs))
+	for _, spec := range specs {
+		strategy, err := New(spec)
+		if err != nil {
+			return nil, err
+		}
+		result, err := Run(strategy, config, history, true)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
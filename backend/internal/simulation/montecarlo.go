@@ -0,0 +1,202 @@
+package simulation
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"casino-backend/internal/models"
+)
+
+// Limits of a Monte Carlo run requested over the API
+const (
+	MaxIterations = 10000
+	MaxSpins      = 5000
+	MaxTotalSpins = 2000000 // Iterations times spins
+)
+
+// HistogramBuckets is the number of buckets of the final bankroll histogram
+const HistogramBuckets = 20
+
+// MonteCarloConfig describes a batch of simulated sessions on a fair wheel
+type MonteCarloConfig struct {
+	Config
+	Iterations int   `json:"iterations"` // Simulated sessions
+	Spins      int   `json:"spins"`      // Spins per session
+	Seed       int64 `json:"seed"`       // 0 picks a seed from the clock
+}
+
+// Validate checks the session count and length on top of the table config
+func (c MonteCarloConfig) Validate() error {
+	if c.Iterations <= 0 {
+		return fmt.Errorf("iterations must be positive, got %d", c.Iterations)
+	}
+	if c.Spins <= 0 {
+		return fmt.Errorf("spins must be positive, got %d", c.Spins)
+	}
+	return c.Config.WithDefaults().Validate()
+}
+
+// Bucket counts the sessions that ended in [From, To)
+type Bucket struct {
+	From  int64 `json:"from"`
+	To    int64 `json:"to"`
+	Count int   `json:"count"`
+}
+
+// Distribution summarises the final bankrolls of the simulated sessions
+type Distribution struct {
+	Min       int64    `json:"min"`
+	Max       int64    `json:"max"`
+	Mean      float64  `json:"mean"`
+	StdDev    float64  `json:"stdDev"`
+	P5        int64    `json:"p5"`
+	P25       int64    `json:"p25"`
+	Median    int64    `json:"median"`
+	P75       int64    `json:"p75"`
+	P95       int64    `json:"p95"`
+	Histogram []Bucket `json:"histogram"`
+}
+
+// MonteCarloResult is the outcome of a batch of simulated sessions
+type MonteCarloResult struct {
+	Strategy        string           `json:"strategy"`
+	Config          MonteCarloConfig `json:"config"` // With the seed actually used
+	FinalBankroll   Distribution     `json:"finalBankroll"`
+	Ruined          int              `json:"ruined"`          // Sessions that busted
+	RuinProbability float64          `json:"ruinProbability"` // Share of sessions that busted
+	ExpectedLoss    float64          `json:"expectedLoss"`    // Mean of the starting minus the final bankroll
+	MeanStaked      float64          `json:"meanStaked"`
+	MeanSpins       float64          `json:"meanSpins"`    // Spins played before the end or the bust
+	HouseEdge       float64          `json:"houseEdge"`    // Expected loss per chip of a single-number bet
+	ObservedEdge    float64          `json:"observedEdge"` // Simulated loss per chip staked
+
+	finals []int64
+}
+
+// HouseEdge returns the expected loss per chip staked on a single number
+func HouseEdge(wheelType models.WheelType) float64 {
+	return 1 - float64(models.MaxNumber)/float64(wheelType.OrDefault().PocketCount())
+}
+
+// MonteCarlo plays a strategy through independent sessions of uniformly
+// random spins. A fixed seed reproduces the same sessions.
+func MonteCarlo(spec Spec, config MonteCarloConfig) (*MonteCarloResult, error) {
+	config.Config = config.Config.WithDefaults()
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	if config.Seed == 0 {
+		config.Seed = time.Now().UnixNano()
+	}
+	if _, err := New(spec); err != nil {
+		return nil, err
+	}
+
+	rng := rand.New(rand.NewSource(config.Seed))
+	pockets := config.Wheel.Pockets()
+	payouts := newPayoutTables(config.Wheel)
+	spins := make([]models.RouletteNumber, config.Spins)
+
+	result := &MonteCarloResult{
+		Strategy:  spec.Name,
+		Config:    config,
+		HouseEdge: HouseEdge(config.Wheel),
+		finals:    make([]int64, 0, config.Iterations),
+	}
+
+	var staked, played, lost float64
+	for i := 0; i < config.Iterations; i++ {
+		for j := range spins {
+			spins[j] = pockets[rng.Intn(len(pockets))]
+		}
+
+		strategy, err := New(spec)
+		if err != nil {
+			return nil, err
+		}
+		session, err := run(strategy, config.Config, spins, false, payouts)
+		if err != nil {
+			return nil, err
+		}
+
+		if session.Busted {
+			result.Ruined++
+		}
+		staked += float64(session.Summary.Staked)
+		played += float64(session.Summary.Spins)
+		lost -= float64(session.Summary.Net)
+		result.finals = append(result.finals, session.Summary.FinalBalance)
+	}
+
+	iterations := float64(config.Iterations)
+	result.RuinProbability = float64(result.Ruined) / iterations
+	result.ExpectedLoss = lost / iterations
+	result.MeanStaked = staked / iterations
+	result.MeanSpins = played / iterations
+	if staked > 0 {
+		result.ObservedEdge = lost / staked
+	}
+
+	sort.Slice(result.finals, func(i, j int) bool { return result.finals[i] < result.finals[j] })
+	result.FinalBankroll = distribution(result.finals)
+	return result, nil
+}
+
+// PercentileOf returns the share of simulated sessions that ended at or
+// below the given bankroll
+func (r *MonteCarloResult) PercentileOf(balance int64) float64 {
+	if len(r.finals) == 0 {
+		return 0
+	}
+	below := sort.Search(len(r.finals), func(i int) bool { return r.finals[i] > balance })
+	return float64(below) / float64(len(r.finals))
+}
+
+// distribution summarises sorted final bankrolls
+func distribution(sorted []int64) Distribution {
+	n := len(sorted)
+	d := Distribution{
+		Min:    sorted[0],
+		Max:    sorted[n-1],
+		P5:     quantile(sorted, 0.05),
+		P25:    quantile(sorted, 0.25),
+		Median: quantile(sorted, 0.5),
+		P75:    quantile(sorted, 0.75),
+		P95:    quantile(sorted, 0.95),
+	}
+
+	var sum float64
+	for _, value := range sorted {
+		sum += float64(value)
+	}
+	d.Mean = sum / float64(n)
+	var squares float64
+	for _, value := range sorted {
+		squares += (float64(value) - d.Mean) * (float64(value) - d.Mean)
+	}
+	d.StdDev = math.Sqrt(squares / float64(n))
+
+	width := (d.Max - d.Min + HistogramBuckets) / HistogramBuckets
+	if width < 1 {
+		width = 1
+	}
+	for from := d.Min; from <= d.Max; from += width {
+		d.Histogram = append(d.Histogram, Bucket{From: from, To: from + width})
+	}
+	for _, value := range sorted {
+		d.Histogram[(value-d.Min)/width].Count++
+	}
+	return d
+}
+
+// quantile returns the nearest-rank quantile of sorted values
+func quantile(sorted []int64, q float64) int64 {
+	index := int(math.Ceil(q*float64(len(sorted)))) - 1
+	if index < 0 {
+		index = 0
+	}
+	return sorted[index]
+}
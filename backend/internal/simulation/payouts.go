@@ -0,0 +1,82 @@
+package simulation
+
+import (
+	"strconv"
+	"strings"
+
+	"casino-backend/internal/betting"
+	"casino-backend/internal/models"
+)
+
+// returnTable holds what the smallest stake of a bet returns on every pocket
+type returnTable struct {
+	chips   int64 // 1, or the chip count of a racetrack bet
+	returns map[models.RouletteNumber]int64
+}
+
+// payoutTables memoises settlement per bet shape, since a run settles the
+// same few bets thousands of times and only the stake changes
+type payoutTables struct {
+	wheel  models.WheelType
+	tables map[string]*returnTable
+}
+
+func newPayoutTables(wheelType models.WheelType) *payoutTables {
+	return &payoutTables{wheel: wheelType, tables: make(map[string]*returnTable)}
+}
+
+// returned settles a bet against the winning number, returning stake plus winnings
+func (p *payoutTables) returned(bet betting.Bet, number models.RouletteNumber) (int64, error) {
+	key := shapeKey(bet)
+	table, exists := p.tables[key]
+	if !exists {
+		var err error
+		if table, err = p.build(bet); err != nil {
+			return 0, err
+		}
+		p.tables[key] = table
+	}
+
+	if bet.Amount <= 0 || bet.Amount%table.chips != 0 {
+		// Let the betting package explain why the stake is invalid
+		result, err := betting.SettleBet(p.wheel, bet, number)
+		if err != nil {
+			return 0, err
+		}
+		return result.Returned, nil
+	}
+	return bet.Amount / table.chips * table.returns[number], nil
+}
+
+func (p *payoutTables) build(bet betting.Bet) (*returnTable, error) {
+	table := &returnTable{chips: 1, returns: make(map[models.RouletteNumber]int64)}
+	if bet.Type.IsRacetrack() {
+		var err error
+		if table.chips, err = betting.Units(bet); err != nil {
+			return nil, err
+		}
+	}
+
+	bet.Amount = table.chips
+	for _, pocket := range p.wheel.Pockets() {
+		result, err := betting.SettleBet(p.wheel, bet, pocket)
+		if err != nil {
+			return nil, err
+		}
+		table.returns[pocket] = result.Returned
+	}
+	return table, nil
+}
+
+// shapeKey identifies a bet regardless of its stake
+func shapeKey(bet betting.Bet) string {
+	var key strings.Builder
+	key.WriteString(string(bet.Type))
+	key.WriteByte('/')
+	key.WriteString(strconv.Itoa(bet.Target))
+	for _, n := range bet.Numbers {
+		key.WriteByte('/')
+		key.WriteString(strconv.Itoa(int(n)))
+	}
+	return key.String()
+}
@@ -0,0 +1,247 @@
+package simulation
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"casino-backend/internal/betting"
+	"casino-backend/internal/models"
+)
+
+// stakes runs a registered strategy and returns the stake of every spin
+func stakes(t *testing.T, name string, params Params, config Config, spins []models.RouletteNumber) (*Result, []int64) {
+	t.Helper()
+	strategy, err := New(Spec{Name: name, Params: params})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := Run(strategy, config, spins, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	amounts := make([]int64, len(result.Equity))
+	for i, point := range result.Equity {
+		amounts[i] = point.Stake
+	}
+	return result, amounts
+}
+
+func equalStakes(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestProgressions(t *testing.T) {
+	// Red bets lose on 2, 4 and 0 and win on 1 and 3
+	spins := []models.RouletteNumber{2, 4, 0, 1, 3, 2}
+	config := Config{Bankroll: 100}
+
+	tests := []struct {
+		name   string
+		params Params
+		want   []int64
+	}{
+		{StrategyFlat, Params{Unit: 5}, []int64{5, 5, 5, 5, 5, 5}},
+		{StrategyMartingale, Params{}, []int64{1, 2, 4, 8, 1, 1}},
+		{StrategyReverseMartingale, Params{Streak: 2}, []int64{1, 1, 1, 1, 2, 1}},
+		{StrategyFibonacci, Params{}, []int64{1, 1, 2, 3, 1, 1}},
+		{StrategyDAlembert, Params{}, []int64{1, 2, 3, 4, 3, 2}},
+		{StrategyLabouchere, Params{}, []int64{5, 6, 7, 8, 8, 8}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, got := stakes(t, tt.name, tt.params, config, spins); !equalStakes(got, tt.want) {
+				t.Errorf("stakes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunSummary(t *testing.T) {
+	spins := []models.RouletteNumber{2, 4, 1}
+	result, _ := stakes(t, StrategyMartingale, Params{}, Config{Bankroll: 10}, spins)
+
+	want := Summary{
+		Spins: 3, Bets: 3, Wins: 1, Losses: 2,
+		StartingBalance: 10, FinalBalance: 11, Peak: 11, MaxDrawdown: 3,
+		Staked: 7, Returned: 8, Net: 1, ROI: 1.0 / 7,
+		LargestStake: 4, LongestLosingStreak: 2,
+	}
+	if result.Summary != want {
+		t.Errorf("summary = %+v, want %+v", result.Summary, want)
+	}
+	if result.Busted || result.BustSpin != -1 {
+		t.Errorf("unexpected bust at %d", result.BustSpin)
+	}
+}
+
+func TestRunLimits(t *testing.T) {
+	spins := []models.RouletteNumber{2, 2, 2, 2, 2}
+
+	result, got := stakes(t, StrategyMartingale, Params{}, Config{Bankroll: 100, MaxBet: 2}, spins)
+	if want := []int64{1, 2, 2, 2, 2}; !equalStakes(got, want) {
+		t.Errorf("stakes = %v, want %v", got, want)
+	}
+	if result.Summary.LimitHits != 3 {
+		t.Errorf("limit hits = %d, want 3", result.Summary.LimitHits)
+	}
+
+	// The last stake is cut to the remaining balance and the run stops there
+	result, got = stakes(t, StrategyMartingale, Params{}, Config{Bankroll: 5}, spins)
+	if want := []int64{1, 2, 2}; !equalStakes(got, want) {
+		t.Errorf("stakes = %v, want %v", got, want)
+	}
+	if !result.Busted || result.BustSpin != 2 || result.Summary.FinalBalance != 0 {
+		t.Errorf("expected bust at spin 2, got %+v", result)
+	}
+}
+
+func TestColdestDozen(t *testing.T) {
+	spins := []models.RouletteNumber{1, 13, 14, 25, 0}
+	result, got := stakes(t, StrategyColdestDozen, Params{Window: 3}, Config{Bankroll: 10}, spins)
+	if want := []int64{0, 0, 0, 1, 1}; !equalStakes(got, want) {
+		t.Errorf("stakes = %v, want %v", got, want)
+	}
+	// Dozens over 1, 13, 14 leave the third coldest, which 25 hits
+	if result.Summary.Wins != 1 || result.Summary.FinalBalance != 11 {
+		t.Errorf("unexpected summary %+v", result.Summary)
+	}
+}
+
+type alwaysZero struct{}
+
+func (alwaysZero) Name() string { return "always_zero" }
+
+func (alwaysZero) NextBet(table Table) (betting.Bet, bool) {
+	return betting.Bet{Type: betting.BetStraight, Numbers: []models.RouletteNumber{0}, Amount: table.MinBet}, true
+}
+
+func (alwaysZero) Record(Round) {}
+
+// neighboursOfZero bets 12 chips on zero and two neighbours on each side, five chips a unit
+type neighboursOfZero struct{}
+
+func (neighboursOfZero) Name() string { return "neighbours_of_zero" }
+
+func (neighboursOfZero) NextBet(Table) (betting.Bet, bool) {
+	return betting.Bet{Type: betting.BetNeighbours, Numbers: []models.RouletteNumber{0}, Target: 2, Amount: 12}, true
+}
+
+func (neighboursOfZero) Record(Round) {}
+
+func TestRacetrackStakes(t *testing.T) {
+	spins := []models.RouletteNumber{5, 5}
+
+	// Stakes are rounded down to whole units
+	result, err := Run(neighboursOfZero{}, Config{Bankroll: 100}, spins, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Equity[0].Stake != 10 || result.Summary.FinalBalance != 80 {
+		t.Errorf("unexpected run %+v", result)
+	}
+
+	// A balance below one unit busts instead of failing the run
+	result, err = Run(neighboursOfZero{}, Config{Bankroll: 7}, spins, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Busted || result.BustSpin != 1 || result.Summary.Bets != 1 || result.Summary.FinalBalance != 2 {
+		t.Errorf("expected a bust before spin 1, got %+v", result)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	if _, err := New(Spec{Name: "missing"}); !errors.Is(err, ErrUnknownStrategy) {
+		t.Errorf("expected ErrUnknownStrategy, got %v", err)
+	}
+	if _, err := New(Spec{Name: StrategyFlat, Params: Params{Unit: -1}}); err == nil {
+		t.Error("expected an error for a negative unit")
+	}
+
+	Register("always_zero", func(Params) Strategy { return alwaysZero{} })
+	results, err := Backtest([]Spec{{Name: "always_zero"}}, Config{Bankroll: 10}, []models.RouletteNumber{0, 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := results[0].Summary.FinalBalance; got != 44 {
+		t.Errorf("final balance = %d, want 44", got)
+	}
+}
+
+func TestMonteCarlo(t *testing.T) {
+	config := MonteCarloConfig{
+		Config:     Config{Bankroll: 100},
+		Iterations: 2000,
+		Spins:      200,
+		Seed:       42,
+	}
+	first, err := MonteCarlo(Spec{Name: StrategyFlat}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := MonteCarlo(Spec{Name: StrategyFlat}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.FinalBankroll.Mean != second.FinalBankroll.Mean || first.Ruined != second.Ruined {
+		t.Error("the same seed produced different sessions")
+	}
+
+	// 400000 one-chip red bets land close to the 1/37 house edge
+	if math.Abs(first.ObservedEdge-first.HouseEdge) > 0.01 {
+		t.Errorf("observed edge %.4f, house edge %.4f", first.ObservedEdge, first.HouseEdge)
+	}
+	if first.MeanStaked != 200 || first.Ruined != 0 {
+		t.Errorf("flat betting 1 of 100 chips should never bust in 200 spins: %+v", first)
+	}
+
+	distribution := first.FinalBankroll
+	if distribution.Min > distribution.P5 || distribution.P5 > distribution.Median || distribution.Median > distribution.P95 || distribution.P95 > distribution.Max {
+		t.Errorf("percentiles out of order: %+v", distribution)
+	}
+	total := 0
+	for _, bucket := range distribution.Histogram {
+		total += bucket.Count
+	}
+	if total != config.Iterations || len(distribution.Histogram) > HistogramBuckets {
+		t.Errorf("histogram of %d buckets counts %d sessions", len(distribution.Histogram), total)
+	}
+	if first.PercentileOf(distribution.Max) != 1 || first.PercentileOf(distribution.Min-1) != 0 {
+		t.Error("unexpected percentiles at the extremes")
+	}
+
+	// A Martingale with a ten chip bankroll almost always busts within 200 spins
+	config.Bankroll = 10
+	result, err := MonteCarlo(Spec{Name: StrategyMartingale}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.RuinProbability < 0.9 {
+		t.Errorf("ruin probability %.3f", result.RuinProbability)
+	}
+}
+
+func TestHouseEdge(t *testing.T) {
+	tests := []struct {
+		wheel models.WheelType
+		want  float64
+	}{
+		{models.WheelEuropean, 1.0 / 37},
+		{models.WheelAmerican, 2.0 / 38},
+		{models.WheelTripleZero, 3.0 / 39},
+	}
+	for _, tt := range tests {
+		if got := HouseEdge(tt.wheel); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("HouseEdge(%s) = %v, want %v", tt.wheel, got, tt.want)
+		}
+	}
+}
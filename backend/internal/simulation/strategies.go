@@ -0,0 +1,215 @@
+package simulation
+
+import (
+	"casino-backend/internal/analytics"
+	"casino-backend/internal/betting"
+	"casino-backend/internal/wheel"
+)
+
+// Built-in strategy names
+const (
+	StrategyFlat              = "flat"
+	StrategyMartingale        = "martingale"
+	StrategyReverseMartingale = "reverse_martingale"
+	StrategyFibonacci         = "fibonacci"
+	StrategyDAlembert         = "dalembert"
+	StrategyLabouchere        = "labouchere"
+	StrategyColdestDozen      = "coldest_dozen"
+)
+
+func init() {
+	Register(StrategyFlat, func(p Params) Strategy { return &flat{params: p} })
+	Register(StrategyMartingale, func(p Params) Strategy { return &martingale{params: p} })
+	Register(StrategyReverseMartingale, func(p Params) Strategy { return &reverseMartingale{params: p} })
+	Register(StrategyFibonacci, func(p Params) Strategy { return &fibonacci{params: p} })
+	Register(StrategyDAlembert, func(p Params) Strategy { return &dAlembert{params: p, level: 1} })
+	Register(StrategyLabouchere, func(p Params) Strategy { return &labouchere{params: p} })
+	Register(StrategyColdestDozen, func(p Params) Strategy { return &coldestDozen{params: p} })
+}
+
+// flat stakes one unit every spin
+type flat struct {
+	params Params
+}
+
+func (s *flat) Name() string { return StrategyFlat }
+
+func (s *flat) NextBet(Table) (betting.Bet, bool) {
+	return s.params.bet(s.params.Unit), true
+}
+
+func (s *flat) Record(Round) {}
+
+// martingale doubles the stake after every loss and starts over after a win
+type martingale struct {
+	params Params
+	stake  int64
+}
+
+func (s *martingale) Name() string { return StrategyMartingale }
+
+func (s *martingale) NextBet(Table) (betting.Bet, bool) {
+	if s.stake == 0 {
+		s.stake = s.params.Unit
+	}
+	return s.params.bet(s.stake), true
+}
+
+func (s *martingale) Record(round Round) {
+	switch {
+	case round.Lost():
+		s.stake = round.Bet.Amount * 2
+	case round.Won():
+		s.stake = s.params.Unit
+	}
+}
+
+// reverseMartingale doubles the stake after every win and starts over after
+// a loss or a run of Streak wins
+type reverseMartingale struct {
+	params Params
+	stake  int64
+	wins   int
+}
+
+func (s *reverseMartingale) Name() string { return StrategyReverseMartingale }
+
+func (s *reverseMartingale) NextBet(Table) (betting.Bet, bool) {
+	if s.stake == 0 {
+		s.stake = s.params.Unit
+	}
+	return s.params.bet(s.stake), true
+}
+
+func (s *reverseMartingale) Record(round Round) {
+	switch {
+	case round.Won():
+		s.wins++
+		s.stake = round.Bet.Amount * 2
+		if s.wins >= s.params.Streak {
+			s.wins, s.stake = 0, s.params.Unit
+		}
+	case round.Lost():
+		s.wins, s.stake = 0, s.params.Unit
+	}
+}
+
+// fibonacci moves one step up the Fibonacci sequence after a loss and two
+// steps back after a win
+type fibonacci struct {
+	params Params
+	step   int
+}
+
+func (s *fibonacci) Name() string { return StrategyFibonacci }
+
+func (s *fibonacci) NextBet(Table) (betting.Bet, bool) {
+	return s.params.bet(s.params.Unit * fibonacciNumber(s.step)), true
+}
+
+func (s *fibonacci) Record(round Round) {
+	switch {
+	case round.Lost():
+		s.step++
+	case round.Won():
+		s.step -= 2
+		if s.step < 0 {
+			s.step = 0
+		}
+	}
+}
+
+// fibonacciNumber returns the n-th element of 1, 1, 2, 3, 5, ...
+func fibonacciNumber(n int) int64 {
+	a, b := int64(1), int64(1)
+	for i := 0; i < n; i++ {
+		a, b = b, a+b
+	}
+	return a
+}
+
+// dAlembert adds a unit after a loss and removes one after a win
+type dAlembert struct {
+	params Params
+	level  int64
+}
+
+func (s *dAlembert) Name() string { return StrategyDAlembert }
+
+func (s *dAlembert) NextBet(Table) (betting.Bet, bool) {
+	return s.params.bet(s.params.Unit * s.level), true
+}
+
+func (s *dAlembert) Record(round Round) {
+	switch {
+	case round.Lost():
+		s.level++
+	case round.Won():
+		if s.level > 1 {
+			s.level--
+		}
+	}
+}
+
+// labouchere stakes the sum of the first and last units of its line,
+// crossing them out after a win and appending the stake after a loss. A
+// finished line starts over.
+type labouchere struct {
+	params Params
+	line   []int64
+	units  int64
+}
+
+func (s *labouchere) Name() string { return StrategyLabouchere }
+
+func (s *labouchere) NextBet(Table) (betting.Bet, bool) {
+	if len(s.line) == 0 {
+		s.line = append([]int64(nil), s.params.Sequence...)
+	}
+	s.units = s.line[0]
+	if len(s.line) > 1 {
+		s.units += s.line[len(s.line)-1]
+	}
+	return s.params.bet(s.params.Unit * s.units), true
+}
+
+func (s *labouchere) Record(round Round) {
+	switch {
+	case round.Lost():
+		s.line = append(s.line, s.units)
+	case round.Won():
+		if len(s.line) <= 2 {
+			s.line = nil
+		} else {
+			s.line = s.line[1 : len(s.line)-1]
+		}
+	}
+}
+
+// coldestDozen stakes one unit on the dozen that hit least over the last
+// Window spins, sitting out until that many spins were seen
+type coldestDozen struct {
+	params Params
+}
+
+func (s *coldestDozen) Name() string { return StrategyColdestDozen }
+
+func (s *coldestDozen) NextBet(table Table) (betting.Bet, bool) {
+	if len(table.History) < s.params.Window {
+		return betting.Bet{}, false
+	}
+
+	var counts [4]int
+	for _, number := range analytics.LastN(table.History, s.params.Window) {
+		counts[wheel.DozenOf(number)]++
+	}
+	coldest := 1
+	for dozen := 2; dozen <= 3; dozen++ {
+		if counts[dozen] < counts[coldest] {
+			coldest = dozen
+		}
+	}
+	return betting.Bet{Type: betting.BetDozen, Target: coldest, Amount: s.params.Unit}, true
+}
+
+func (s *coldestDozen) Record(Round) {}
@@ -0,0 +1,167 @@
+package simulation
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"casino-backend/internal/betting"
+	"casino-backend/internal/models"
+)
+
+// ErrUnknownStrategy is returned for strategy names that were never registered
+var ErrUnknownStrategy = errors.New("unknown strategy")
+
+// Table is what a strategy sees before a spin
+type Table struct {
+	Wheel   models.WheelType        `json:"wheelType"`
+	History []models.RouletteNumber `json:"history"` // Spins played so far, oldest first
+	Balance int64                   `json:"balance"`
+	MinBet  int64                   `json:"minBet"`
+	MaxBet  int64                   `json:"maxBet"` // 0 when the table has no maximum
+}
+
+// Round is the outcome of one spin as reported back to the strategy
+type Round struct {
+	Spin    int                   `json:"spin"`
+	Number  models.RouletteNumber `json:"number"`
+	Bet     betting.Bet           `json:"bet"`    // Bet actually placed, after table limits
+	Placed  bool                  `json:"placed"` // False when the strategy sat the spin out
+	Net     int64                 `json:"net"`
+	Balance int64                 `json:"balance"` // Balance after the spin
+}
+
+// Won reports whether the round made money
+func (r Round) Won() bool {
+	return r.Placed && r.Net > 0
+}
+
+// Lost reports whether the round lost money
+func (r Round) Lost() bool {
+	return r.Placed && r.Net < 0
+}
+
+// Strategy decides the bet of every spin. Implementations keep their own
+// progression state, so a new instance is needed for every run.
+type Strategy interface {
+	Name() string
+	// NextBet returns the bet for the next spin, or false to sit it out
+	NextBet(table Table) (betting.Bet, bool)
+	// Record reports the outcome of the spin that followed NextBet
+	Record(round Round)
+}
+
+// Params configures the built-in strategies
+type Params struct {
+	Unit     int64       `json:"unit,omitempty"`     // Base stake in chips, 1 by default
+	Bet      betting.Bet `json:"bet"`                // Bet the progressions play, red by default; its amount is ignored
+	Sequence []int64     `json:"sequence,omitempty"` // Starting line of Labouchère, in units
+	Window   int         `json:"window,omitempty"`   // Spins the coldest dozen strategy looks back over
+	Streak   int         `json:"streak,omitempty"`   // Wins after which reverse Martingale starts over
+}
+
+// Default strategy parameters
+const (
+	DefaultWindow = 36
+	DefaultStreak = 3
+)
+
+// DefaultSequence is the starting line of Labouchère
+var DefaultSequence = []int64{1, 2, 3, 4}
+
+// WithDefaults fills in the unset parameters
+func (p Params) WithDefaults() Params {
+	if p.Unit == 0 {
+		p.Unit = 1
+	}
+	if p.Bet.Type == "" {
+		p.Bet.Type = betting.BetRed
+	}
+	if len(p.Sequence) == 0 {
+		p.Sequence = DefaultSequence
+	}
+	if p.Window == 0 {
+		p.Window = DefaultWindow
+	}
+	if p.Streak == 0 {
+		p.Streak = DefaultStreak
+	}
+	return p
+}
+
+// Validate checks the parameter ranges
+func (p Params) Validate() error {
+	if p.Unit <= 0 {
+		return fmt.Errorf("unit must be positive, got %d", p.Unit)
+	}
+	for _, units := range p.Sequence {
+		if units <= 0 {
+			return fmt.Errorf("sequence must contain positive units, got %v", p.Sequence)
+		}
+	}
+	if p.Window <= 0 {
+		return fmt.Errorf("window must be positive, got %d", p.Window)
+	}
+	if p.Streak <= 0 {
+		return fmt.Errorf("streak must be positive, got %d", p.Streak)
+	}
+	return nil
+}
+
+// bet returns the configured bet with the given stake
+func (p Params) bet(amount int64) betting.Bet {
+	bet := p.Bet
+	bet.Amount = amount
+	return bet
+}
+
+// Factory creates a fresh strategy instance
+type Factory func(params Params) Strategy
+
+// Spec names a strategy and its parameters
+type Spec struct {
+	Name   string `json:"name"`
+	Params Params `json:"params"`
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Factory{}
+)
+
+// Register makes a strategy available by name, replacing any previous one
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = factory
+}
+
+// Names returns the registered strategy names in alphabetical order
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New creates a registered strategy with defaults applied to its parameters
+func New(spec Spec) (Strategy, error) {
+	registryMu.RLock()
+	factory, exists := registry[spec.Name]
+	registryMu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownStrategy, spec.Name)
+	}
+
+	params := spec.Params.WithDefaults()
+	if err := params.Validate(); err != nil {
+		return nil, fmt.Errorf("strategy %s: %w", spec.Name, err)
+	}
+	return factory(params), nil
+}
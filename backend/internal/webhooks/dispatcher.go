@@ -0,0 +1,381 @@
+package webhooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"casino-backend/internal/alerts"
+	"casino-backend/internal/models"
+)
+
+// Store persists webhooks and their delivery log
+type Store interface {
+	CreateWebhook(webhook *Webhook) (*Webhook, error)
+	GetWebhooks() ([]*Webhook, error)
+	DeleteWebhook(id int64) (bool, error)
+	SaveWebhookDelivery(delivery *Delivery) error
+	GetWebhookDeliveries(webhookID int64, limit int) ([]*Delivery, error)
+}
+
+// Config tunes delivery
+type Config struct {
+	Workers        int           // Concurrent deliveries
+	QueueSize      int           // Deliveries waiting for a worker; more are dropped
+	MaxAttempts    int           // Attempts per delivery, the first one included
+	InitialBackoff time.Duration // Delay before the first retry, doubled on every retry
+	MaxBackoff     time.Duration
+	Timeout        time.Duration // Per attempt
+}
+
+// WithDefaults fills in zero fields
+func (c Config) WithDefaults() Config {
+	if c.Workers <= 0 {
+		c.Workers = 4
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = 1000
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = 6
+	}
+	if c.InitialBackoff <= 0 {
+		c.InitialBackoff = time.Second
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = 5 * time.Minute
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 10 * time.Second
+	}
+	return c
+}
+
+// Backoff returns the delay before an attempt; attempt 2 is the first retry
+func (c Config) Backoff(attempt int) time.Duration {
+	delay := c.InitialBackoff
+	for i := 2; i < attempt && delay < c.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > c.MaxBackoff {
+		delay = c.MaxBackoff
+	}
+	return delay
+}
+
+// job is an event on its way to one webhook
+type job struct {
+	webhook *Webhook
+	event   *Event
+	body    []byte
+	attempt int
+}
+
+// Dispatcher turns room events into signed POST requests. It observes the
+// repository like the analytics do, so every write path emits the same events.
+type Dispatcher struct {
+	store  Store
+	config Config
+	client *http.Client
+	jobs   chan *job
+
+	mu       sync.Mutex
+	webhooks []*Webhook // Cached subscriptions, nil until loaded
+	stopped  bool
+	done     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewDispatcher creates a dispatcher; call Start to begin delivering
+func NewDispatcher(store Store, config Config) *Dispatcher {
+	config = config.WithDefaults()
+	return &Dispatcher{
+		store:  store,
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+		jobs:   make(chan *job, config.QueueSize),
+		done:   make(chan struct{}),
+	}
+}
+
+// Start launches the delivery workers
+func (d *Dispatcher) Start() {
+	for i := 0; i < d.config.Workers; i++ {
+		d.wg.Add(1)
+		go d.work()
+	}
+}
+
+// Stop waits for the deliveries in progress; queued deliveries and pending retries are dropped
+func (d *Dispatcher) Stop() {
+	d.mu.Lock()
+	if d.stopped {
+		d.mu.Unlock()
+		return
+	}
+	d.stopped = true
+	close(d.done)
+	d.mu.Unlock()
+	d.wg.Wait()
+}
+
+// CreateWebhook validates and stores a subscription
+func (d *Dispatcher) CreateWebhook(webhook *Webhook) (*Webhook, error) {
+	if err := webhook.Validate(); err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	created, err := d.store.CreateWebhook(webhook)
+	if err != nil {
+		return nil, err
+	}
+	d.webhooks = nil
+	return created, nil
+}
+
+// Webhooks returns every subscription without secrets
+func (d *Dispatcher) Webhooks() ([]*Webhook, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	webhooks, err := d.subscriptions()
+	if err != nil {
+		return nil, err
+	}
+	public := make([]*Webhook, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		public = append(public, webhook.Public())
+	}
+	return public, nil
+}
+
+// DeleteWebhook removes a subscription, reporting whether it existed
+func (d *Dispatcher) DeleteWebhook(id int64) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	deleted, err := d.store.DeleteWebhook(id)
+	if err != nil {
+		return false, err
+	}
+	d.webhooks = nil
+	return deleted, nil
+}
+
+// Deliveries returns the latest delivery attempts of a webhook, newest first
+func (d *Dispatcher) Deliveries(webhookID int64, limit int) ([]*Delivery, error) {
+	return d.store.GetWebhookDeliveries(webhookID, limit)
+}
+
+// Publish sends an event to every matching webhook
+func (d *Dispatcher) Publish(eventType, sessionKey string, data interface{}) {
+	id, err := randomHex(16)
+	if err != nil {
+		log.Printf("[WEBHOOK] Failed to create %s event: %v", eventType, err)
+		return
+	}
+	event := &Event{
+		ID:         id,
+		Type:       eventType,
+		SessionKey: sessionKey,
+		CreatedAt:  time.Now().UTC(),
+		Data:       data,
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("[WEBHOOK] Failed to encode %s event: %v", eventType, err)
+		return
+	}
+
+	d.mu.Lock()
+	webhooks, err := d.subscriptions()
+	d.mu.Unlock()
+	if err != nil {
+		log.Printf("[WEBHOOK] Failed to load webhooks: %v", err)
+		return
+	}
+
+	for _, webhook := range webhooks {
+		if webhook.Matches(event) {
+			d.enqueue(&job{webhook: webhook, event: event, body: body, attempt: 1})
+		}
+	}
+}
+
+// NumberAddedData is the payload of number.added
+type NumberAddedData struct {
+	Number  models.RouletteNumber `json:"number"`
+	Index   int                   `json:"index"`
+	Version int                   `json:"version"` // History length after the change
+}
+
+// NumberRemovedData is the payload of number.removed
+type NumberRemovedData struct {
+	Index   int `json:"index"`
+	Version int `json:"version"`
+}
+
+// HistoryReplacedData is the payload of history.replaced
+type HistoryReplacedData struct {
+	WheelType models.WheelType        `json:"wheelType"`
+	History   []models.RouletteNumber `json:"history"`
+}
+
+// SessionCreatedData is the payload of session.created
+type SessionCreatedData struct {
+	Key       string           `json:"key"`
+	WheelType models.WheelType `json:"wheelType"`
+	Protected bool             `json:"protected"` // The room has a password
+	CreatedAt time.Time        `json:"createdAt"`
+}
+
+// NumberAdded implements database.HistoryObserver
+func (d *Dispatcher) NumberAdded(session *models.RouletteSession, number models.RouletteNumber) {
+	d.Publish(EventNumberAdded, session.Key, NumberAddedData{
+		Number:  number,
+		Index:   len(session.History) - 1,
+		Version: len(session.History),
+	})
+}
+
+// NumberRemoved implements database.HistoryObserver
+func (d *Dispatcher) NumberRemoved(session *models.RouletteSession, index int) {
+	d.Publish(EventNumberRemoved, session.Key, NumberRemovedData{Index: index, Version: len(session.History)})
+}
+
+// HistoryReplaced implements database.HistoryObserver
+func (d *Dispatcher) HistoryReplaced(session *models.RouletteSession) {
+	d.Publish(EventHistoryReplaced, session.Key, HistoryReplacedData{
+		WheelType: session.WheelType.OrDefault(),
+		History:   session.History,
+	})
+}
+
+// SessionCreated implements database.SessionObserver
+func (d *Dispatcher) SessionCreated(session *models.RouletteSession) {
+	d.Publish(EventSessionCreated, session.Key, SessionCreatedData{
+		Key:       session.Key,
+		WheelType: session.WheelType.OrDefault(),
+		Protected: session.Password != "",
+		CreatedAt: session.CreatedAt,
+	})
+}
+
+// AlertFired implements analytics.AlertListener
+func (d *Dispatcher) AlertFired(alert *alerts.Alert) {
+	d.Publish(EventAlertFired, alert.SessionKey, alert)
+}
+
+// subscriptions returns the cached webhooks; the caller must hold d.mu
+func (d *Dispatcher) subscriptions() ([]*Webhook, error) {
+	if d.webhooks != nil {
+		return d.webhooks, nil
+	}
+	webhooks, err := d.store.GetWebhooks()
+	if err != nil {
+		return nil, err
+	}
+	if webhooks == nil {
+		webhooks = []*Webhook{}
+	}
+	d.webhooks = webhooks
+	return webhooks, nil
+}
+
+func (d *Dispatcher) enqueue(j *job) {
+	select {
+	case <-d.done:
+		return
+	default:
+	}
+
+	select {
+	case d.jobs <- j:
+	default:
+		log.Printf("[WEBHOOK] Queue is full, dropping %s event %s for webhook %d", j.event.Type, j.event.ID, j.webhook.ID)
+		d.record(j, 0, 0, fmt.Errorf("delivery queue is full"))
+	}
+}
+
+func (d *Dispatcher) work() {
+	defer d.wg.Done()
+	for {
+		select {
+		case <-d.done:
+			return
+		case j := <-d.jobs:
+			d.deliver(j)
+		}
+	}
+}
+
+// deliver makes one attempt and schedules the next one on failure
+func (d *Dispatcher) deliver(j *job) {
+	start := time.Now()
+	statusCode, err := d.post(j)
+	d.record(j, statusCode, time.Since(start), err)
+	if err == nil {
+		return
+	}
+
+	if j.attempt >= d.config.MaxAttempts {
+		log.Printf("[WEBHOOK] Giving up on %s event %s for webhook %d after %d attempts: %v",
+			j.event.Type, j.event.ID, j.webhook.ID, j.attempt, err)
+		return
+	}
+	retry := *j
+	retry.attempt++
+	time.AfterFunc(d.config.Backoff(retry.attempt), func() { d.enqueue(&retry) })
+}
+
+func (d *Dispatcher) post(j *job) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, j.webhook.URL, bytes.NewReader(j.body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, j.event.Type)
+	req.Header.Set(HeaderDelivery, j.event.ID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(j.webhook.Secret, timestamp, j.body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func (d *Dispatcher) record(j *job, statusCode int, duration time.Duration, err error) {
+	delivery := &Delivery{
+		WebhookID:  j.webhook.ID,
+		EventID:    j.event.ID,
+		EventType:  j.event.Type,
+		Attempt:    j.attempt,
+		StatusCode: statusCode,
+		Duration:   duration.Milliseconds(),
+		Success:    err == nil,
+		CreatedAt:  time.Now(),
+	}
+	if err != nil {
+		delivery.Error = err.Error()
+	}
+	if err := d.store.SaveWebhookDelivery(delivery); err != nil {
+		log.Printf("[WEBHOOK] Failed to log delivery of event %s to webhook %d: %v", j.event.ID, j.webhook.ID, err)
+	}
+}
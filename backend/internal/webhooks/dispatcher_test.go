@@ -0,0 +1,186 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"casino-backend/internal/models"
+)
+
+// memoryStore is a minimal Store for the tests
+type memoryStore struct {
+	mu         sync.Mutex
+	webhooks   []*Webhook
+	deliveries []*Delivery
+}
+
+func (s *memoryStore) CreateWebhook(webhook *Webhook) (*Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *webhook
+	stored.ID = int64(len(s.webhooks) + 1)
+	s.webhooks = append(s.webhooks, &stored)
+	return &stored, nil
+}
+
+func (s *memoryStore) GetWebhooks() ([]*Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Webhook(nil), s.webhooks...), nil
+}
+
+func (s *memoryStore) DeleteWebhook(id int64) (bool, error) {
+	return false, nil
+}
+
+func (s *memoryStore) SaveWebhookDelivery(delivery *Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deliveries = append(s.deliveries, delivery)
+	return nil
+}
+
+func (s *memoryStore) GetWebhookDeliveries(webhookID int64, limit int) ([]*Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var deliveries []*Delivery
+	for _, delivery := range s.deliveries {
+		if delivery.WebhookID == webhookID {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	return deliveries, nil
+}
+
+// receivedEvent is a request seen by the test receiver
+type receivedEvent struct {
+	event     Event
+	signature bool
+}
+
+func TestDispatcherSignsAndRetries(t *testing.T) {
+	const secret = "shared-secret"
+	var (
+		mu       sync.Mutex
+		calls    int
+		received = make(chan receivedEvent, 10)
+	)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		call := calls
+		mu.Unlock()
+		// The first two attempts fail and must be retried
+		if call <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+		var event Event
+		if err := json.Unmarshal(body, &event); err != nil {
+			t.Errorf("invalid body %s", body)
+		}
+		if r.Header.Get(HeaderEvent) != event.Type || r.Header.Get(HeaderDelivery) != event.ID {
+			t.Errorf("headers do not match event %+v", event)
+		}
+		received <- receivedEvent{event: event, signature: Verify(secret, timestamp, body, r.Header.Get(HeaderSignature))}
+	}))
+	defer receiver.Close()
+
+	store := &memoryStore{}
+	dispatcher := NewDispatcher(store, Config{InitialBackoff: 10 * time.Millisecond, MaxAttempts: 3})
+	dispatcher.Start()
+	defer dispatcher.Stop()
+
+	webhook, err := dispatcher.CreateWebhook(&Webhook{SessionKey: "room", URL: receiver.URL, Secret: secret, Events: []string{EventNumberAdded}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Neither another room nor another event type reaches the webhook
+	dispatcher.NumberAdded(&models.RouletteSession{Key: "other", History: []models.RouletteNumber{1}}, 1)
+	dispatcher.NumberRemoved(&models.RouletteSession{Key: "room"}, 0)
+	dispatcher.NumberAdded(&models.RouletteSession{Key: "room", History: []models.RouletteNumber{5, 17}}, 17)
+
+	select {
+	case got := <-received:
+		if !got.signature {
+			t.Error("signature does not verify")
+		}
+		if got.event.Type != EventNumberAdded || got.event.SessionKey != "room" {
+			t.Errorf("unexpected event %+v", got.event)
+		}
+		data := got.event.Data.(map[string]interface{})
+		if data["number"] != float64(17) || data["index"] != float64(1) {
+			t.Errorf("unexpected payload %v", data)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("event was not delivered")
+	}
+
+	select {
+	case extra := <-received:
+		t.Errorf("unexpected delivery %+v", extra.event)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// The last attempt is logged once the receiver has answered
+	deliveries, _ := dispatcher.Deliveries(webhook.ID, 0)
+	for deadline := time.Now().Add(5 * time.Second); len(deliveries) < 3 && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+		deliveries, _ = dispatcher.Deliveries(webhook.ID, 0)
+	}
+	if len(deliveries) != 3 {
+		t.Fatalf("expected 3 logged attempts, got %d", len(deliveries))
+	}
+	for i, delivery := range deliveries {
+		success := i == 2
+		if delivery.Attempt != i+1 || delivery.Success != success || (!success && delivery.StatusCode != http.StatusServiceUnavailable) {
+			t.Errorf("unexpected delivery %+v", delivery)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	config := Config{InitialBackoff: time.Second, MaxBackoff: 10 * time.Second}.WithDefaults()
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+	for i, delay := range want {
+		if got := config.Backoff(i + 2); got != delay {
+			t.Errorf("attempt %d: got %v, want %v", i+2, got, delay)
+		}
+	}
+}
+
+func TestWebhookValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		webhook Webhook
+		wantErr bool
+	}{
+		{name: "https", webhook: Webhook{URL: "https://example.com/hook"}},
+		{name: "events", webhook: Webhook{URL: "http://example.com", Events: []string{EventAlertFired, EventSessionCreated}}},
+		{name: "relative url", webhook: Webhook{URL: "/hook"}, wantErr: true},
+		{name: "other scheme", webhook: Webhook{URL: "ftp://example.com"}, wantErr: true},
+		{name: "unknown event", webhook: Webhook{URL: "https://example.com", Events: []string{"number.changed"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.webhook.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && len(tt.webhook.Secret) != 64 {
+				t.Errorf("expected a generated secret, got %q", tt.webhook.Secret)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ErrInvalidWebhook is returned for subscriptions that cannot be delivered to
+var ErrInvalidWebhook = errors.New("invalid webhook")
+
+// Event types
+const (
+	EventNumberAdded     = "number.added"
+	EventNumberRemoved   = "number.removed"
+	EventHistoryReplaced = "history.replaced"
+	EventSessionCreated  = "session.created"
+	EventAlertFired      = "alert.fired"
+)
+
+// EventTypes lists every event a webhook can subscribe to
+var EventTypes = []string{EventNumberAdded, EventNumberRemoved, EventHistoryReplaced, EventSessionCreated, EventAlertFired}
+
+// Headers of a delivery
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery" // Event ID, the same on every retry
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature" // "sha256=" + hex HMAC of "timestamp.body"
+)
+
+// Webhook subscribes a URL to the events of a room, or of every room when SessionKey is empty
+type Webhook struct {
+	ID         int64     `json:"id"`
+	SessionKey string    `json:"sessionKey,omitempty"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"secret,omitempty"` // Only returned when the webhook is created
+	Events     []string  `json:"events"`           // Every event type when empty
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// Event is the JSON body posted to the subscribers
+type Event struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	SessionKey string      `json:"sessionKey,omitempty"`
+	CreatedAt  time.Time   `json:"createdAt"`
+	Data       interface{} `json:"data"`
+}
+
+// Delivery is one attempt to post an event to a webhook
+type Delivery struct {
+	ID         int64     `json:"id"`
+	WebhookID  int64     `json:"webhookId"`
+	EventID    string    `json:"eventId"`
+	EventType  string    `json:"eventType"`
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Duration   int64     `json:"duration"` // Milliseconds
+	Success    bool      `json:"success"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// Validate checks the URL and event types and draws a secret when none is set
+func (w *Webhook) Validate() error {
+	target, err := url.Parse(w.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidWebhook)
+	}
+	for _, event := range w.Events {
+		if !isEventType(event) {
+			return fmt.Errorf("%w: unknown event %q", ErrInvalidWebhook, event)
+		}
+	}
+	if w.Secret == "" {
+		secret, err := randomHex(32)
+		if err != nil {
+			return err
+		}
+		w.Secret = secret
+	}
+	return nil
+}
+
+// Matches reports whether the webhook receives an event
+func (w *Webhook) Matches(event *Event) bool {
+	if w.SessionKey != "" && w.SessionKey != event.SessionKey {
+		return false
+	}
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, eventType := range w.Events {
+		if eventType == event.Type {
+			return true
+		}
+	}
+	return false
+}
+
+// Public returns a copy without the secret
+func (w *Webhook) Public() *Webhook {
+	public := *w
+	public.Secret = ""
+	return &public
+}
+
+// Sign returns the signature header value of a body sent at a Unix timestamp
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a signature header in constant time
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+func isEventType(eventType string) bool {
+	for _, known := range EventTypes {
+		if known == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func randomHex(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,143 @@
+package wheel
+
+import (
+	"fmt"
+
+	"casino-backend/internal/models"
+)
+
+const (
+	dz = models.DoubleZero
+	tz = models.TripleZero
+)
+
+// Pocket order clockwise starting from 0
+var (
+	europeanOrder = []models.RouletteNumber{
+		0, 32, 15, 19, 4, 21, 2, 25, 17, 34, 6, 27, 13, 36, 11, 30, 8, 23, 10,
+		5, 24, 16, 33, 1, 20, 14, 31, 9, 22, 18, 29, 7, 28, 12, 35, 3, 26,
+	}
+	americanOrder = []models.RouletteNumber{
+		0, 28, 9, 26, 30, 11, 7, 20, 32, 17, 5, 22, 34, 15, 3, 24, 36, 13, 1,
+		dz, 27, 10, 25, 29, 12, 8, 19, 31, 18, 6, 21, 33, 16, 4, 23, 35, 14, 2,
+	}
+	// Triple-zero wheels have no single standard; we follow the American
+	// sequence with 000 placed between 1 and 00.
+	tripleZeroOrder = []models.RouletteNumber{
+		0, 28, 9, 26, 30, 11, 7, 20, 32, 17, 5, 22, 34, 15, 3, 24, 36, 13, 1,
+		tz, dz, 27, 10, 25, 29, 12, 8, 19, 31, 18, 6, 21, 33, 16, 4, 23, 35, 14, 2,
+	}
+)
+
+var layouts = map[models.WheelType]*Layout{
+	models.WheelEuropean:   newLayout(models.WheelEuropean, europeanOrder),
+	models.WheelAmerican:   newLayout(models.WheelAmerican, americanOrder),
+	models.WheelTripleZero: newLayout(models.WheelTripleZero, tripleZeroOrder),
+}
+
+// Layout describes the physical order of pockets on a wheel
+type Layout struct {
+	Type    models.WheelType
+	Pockets []models.RouletteNumber // Clockwise, starting from 0
+
+	positions map[models.RouletteNumber]int
+}
+
+func newLayout(wheelType models.WheelType, pockets []models.RouletteNumber) *Layout {
+	positions := make(map[models.RouletteNumber]int, len(pockets))
+	for i, pocket := range pockets {
+		positions[pocket] = i
+	}
+	return &Layout{
+		Type:      wheelType,
+		Pockets:   pockets,
+		positions: positions,
+	}
+}
+
+// ForType returns the layout of a wheel type, or the default wheel's layout when unset
+func ForType(wheelType models.WheelType) (*Layout, error) {
+	layout, ok := layouts[wheelType.OrDefault()]
+	if !ok {
+		return nil, fmt.Errorf("unknown wheel type %q", wheelType)
+	}
+	return layout, nil
+}
+
+// Size returns the number of pockets on the wheel
+func (l *Layout) Size() int {
+	return len(l.Pockets)
+}
+
+// Position returns the index of a pocket in wheel order
+func (l *Layout) Position(n models.RouletteNumber) (int, error) {
+	position, ok := l.positions[n]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s is not on the %s wheel", models.ErrInvalidNumber, n, l.Type)
+	}
+	return position, nil
+}
+
+// At returns the pocket at a position, wrapping around the wheel in both directions
+func (l *Layout) At(position int) models.RouletteNumber {
+	size := l.Size()
+	return l.Pockets[((position%size)+size)%size]
+}
+
+// Distance returns the number of pockets travelled clockwise from one pocket to another,
+// in the range [0, Size)
+func (l *Layout) Distance(from, to models.RouletteNumber) (int, error) {
+	fromPos, err := l.Position(from)
+	if err != nil {
+		return 0, err
+	}
+	toPos, err := l.Position(to)
+	if err != nil {
+		return 0, err
+	}
+	size := l.Size()
+	return ((toPos-fromPos)%size + size) % size, nil
+}
+
+// SignedDistance returns the shortest offset between two pockets: positive
+// clockwise, negative counter-clockwise, in the range (-Size/2, Size/2]
+func (l *Layout) SignedDistance(from, to models.RouletteNumber) (int, error) {
+	distance, err := l.Distance(from, to)
+	if err != nil {
+		return 0, err
+	}
+	if distance > l.Size()/2 {
+		distance -= l.Size()
+	}
+	return distance, nil
+}
+
+// ShortestDistance returns the number of pockets between two results going
+// the shorter way around the wheel
+func (l *Layout) ShortestDistance(a, b models.RouletteNumber) (int, error) {
+	distance, err := l.SignedDistance(a, b)
+	if err != nil {
+		return 0, err
+	}
+	if distance < 0 {
+		distance = -distance
+	}
+	return distance, nil
+}
+
+// Neighbours returns a pocket together with count pockets on each side, in wheel order
+func (l *Layout) Neighbours(n models.RouletteNumber, count int) ([]models.RouletteNumber, error) {
+	position, err := l.Position(n)
+	if err != nil {
+		return nil, err
+	}
+	if count < 0 || 2*count+1 > l.Size() {
+		return nil, fmt.Errorf("invalid neighbour count %d", count)
+	}
+
+	neighbours := make([]models.RouletteNumber, 0, 2*count+1)
+	for offset := -count; offset <= count; offset++ {
+		neighbours = append(neighbours, l.At(position+offset))
+	}
+	return neighbours, nil
+}
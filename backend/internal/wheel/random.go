@@ -0,0 +1,53 @@
+package wheel
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	mathrand "math/rand"
+
+	"casino-backend/internal/models"
+)
+
+// Source picks uniformly distributed indexes in [0, n)
+type Source interface {
+	Pick(n int) (int, error)
+}
+
+type cryptoSource struct{}
+
+// CryptoSource returns a source backed by crypto/rand
+func CryptoSource() Source {
+	return cryptoSource{}
+}
+
+func (cryptoSource) Pick(n int) (int, error) {
+	value, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read random number: %w", err)
+	}
+	return int(value.Int64()), nil
+}
+
+type seededSource struct {
+	rng *mathrand.Rand
+}
+
+// SeededSource returns a reproducible source; it is not safe for concurrent use
+func SeededSource(seed int64) Source {
+	return &seededSource{rng: mathrand.New(mathrand.NewSource(seed))}
+}
+
+func (s *seededSource) Pick(n int) (int, error) {
+	return s.rng.Intn(n), nil
+}
+
+// Spin draws a pocket of the wheel
+func Spin(wheelType models.WheelType, source Source) (models.RouletteNumber, error) {
+	pockets := wheelType.OrDefault().Pockets()
+	index, err := source.Pick(len(pockets))
+	if err != nil {
+		return 0, err
+	}
+	return pockets[index], nil
+}
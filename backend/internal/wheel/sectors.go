@@ -0,0 +1,82 @@
+package wheel
+
+import (
+	"fmt"
+
+	"casino-backend/internal/models"
+)
+
+// Sector is a named group of pockets that can be bet on as a whole
+type Sector struct {
+	Name    string                  `json:"name"`
+	Numbers []models.RouletteNumber `json:"numbers"`
+}
+
+// Classic racetrack sectors. They are defined on the single-zero wheel, where
+// each of them is a contiguous arc, but the numbers can be bet on any table.
+var (
+	VoisinsDuZero = Sector{
+		Name:    "Voisins du Zéro",
+		Numbers: []models.RouletteNumber{22, 18, 29, 7, 28, 12, 35, 3, 26, 0, 32, 15, 19, 4, 21, 2, 25},
+	}
+	TiersDuCylindre = Sector{
+		Name:    "Tiers du Cylindre",
+		Numbers: []models.RouletteNumber{27, 13, 36, 11, 30, 8, 23, 10, 5, 24, 16, 33},
+	}
+	Orphelins = Sector{
+		Name:    "Orphelins",
+		Numbers: []models.RouletteNumber{17, 34, 6, 1, 20, 14, 31, 9},
+	}
+	JeuZero = Sector{
+		Name:    "Jeu Zéro",
+		Numbers: []models.RouletteNumber{12, 35, 3, 26, 0, 32, 15},
+	}
+)
+
+// RacetrackSectors returns Voisins, Tiers and Orphelins, which together cover
+// every pocket of the single-zero wheel exactly once
+func RacetrackSectors() []Sector {
+	return []Sector{VoisinsDuZero, TiersDuCylindre, Orphelins}
+}
+
+// Contains reports whether a number belongs to the sector
+func (s Sector) Contains(n models.RouletteNumber) bool {
+	for _, number := range s.Numbers {
+		if number == n {
+			return true
+		}
+	}
+	return false
+}
+
+// NeighboursSector returns the "n and its neighbours" racetrack bet for this wheel
+func (l *Layout) NeighboursSector(n models.RouletteNumber, count int) (Sector, error) {
+	numbers, err := l.Neighbours(n, count)
+	if err != nil {
+		return Sector{}, err
+	}
+	return Sector{
+		Name:    fmt.Sprintf("%s and %d neighbours", n, count),
+		Numbers: numbers,
+	}, nil
+}
+
+// Arcs splits the wheel into count contiguous sectors of nearly equal size,
+// starting from 0. Useful for sector-level statistics on any wheel type.
+func (l *Layout) Arcs(count int) ([]Sector, error) {
+	if count < 1 || count > l.Size() {
+		return nil, fmt.Errorf("invalid sector count %d", count)
+	}
+
+	sectors := make([]Sector, count)
+	start := 0
+	for i := 0; i < count; i++ {
+		end := (i + 1) * l.Size() / count
+		sectors[i] = Sector{
+			Name:    fmt.Sprintf("Arc %d", i+1),
+			Numbers: append([]models.RouletteNumber(nil), l.Pockets[start:end]...),
+		}
+		start = end
+	}
+	return sectors, nil
+}
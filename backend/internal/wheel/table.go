@@ -0,0 +1,121 @@
+package wheel
+
+import (
+	"fmt"
+
+	"casino-backend/internal/models"
+)
+
+// Color of a pocket
+type Color string
+
+const (
+	Red   Color = "red"
+	Black Color = "black"
+	Green Color = "green"
+)
+
+// Group categories of the outside table bets
+const (
+	CategoryDozen  = "dozen"
+	CategoryColumn = "column"
+	CategoryColor  = "color"
+	CategoryParity = "parity"
+	CategoryHalf   = "half"
+)
+
+var redNumbers = map[models.RouletteNumber]bool{
+	1: true, 3: true, 5: true, 7: true, 9: true, 12: true, 14: true, 16: true, 18: true,
+	19: true, 21: true, 23: true, 25: true, 27: true, 30: true, 32: true, 34: true, 36: true,
+}
+
+// ColorOf returns the color of a pocket; zeros are green
+func ColorOf(n models.RouletteNumber) Color {
+	if n.IsZero() || !n.IsValid() {
+		return Green
+	}
+	if redNumbers[n] {
+		return Red
+	}
+	return Black
+}
+
+// DozenOf returns 1, 2 or 3 for numbered pockets and 0 for zeros
+func DozenOf(n models.RouletteNumber) int {
+	if n.IsZero() || !n.IsValid() {
+		return 0
+	}
+	return (int(n)-1)/12 + 1
+}
+
+// ColumnOf returns 1, 2 or 3 for numbered pockets and 0 for zeros
+func ColumnOf(n models.RouletteNumber) int {
+	if n.IsZero() || !n.IsValid() {
+		return 0
+	}
+	return (int(n)-1)%3 + 1
+}
+
+// Group is a named set of table numbers covered by an outside bet
+type Group struct {
+	Category string                  `json:"category"`
+	Name     string                  `json:"name"`
+	Numbers  []models.RouletteNumber `json:"numbers"`
+}
+
+// Contains reports whether a number belongs to the group
+func (g Group) Contains(n models.RouletteNumber) bool {
+	for _, number := range g.Numbers {
+		if number == n {
+			return true
+		}
+	}
+	return false
+}
+
+var tableGroups = buildTableGroups()
+
+func buildTableGroups() []Group {
+	var groups []Group
+	add := func(category, name string, match func(models.RouletteNumber) bool) {
+		group := Group{Category: category, Name: name}
+		for n := models.RouletteNumber(1); n <= models.MaxNumber; n++ {
+			if match(n) {
+				group.Numbers = append(group.Numbers, n)
+			}
+		}
+		groups = append(groups, group)
+	}
+
+	for dozen := 1; dozen <= 3; dozen++ {
+		d := dozen
+		add(CategoryDozen, fmt.Sprintf("%d-%d", (d-1)*12+1, d*12), func(n models.RouletteNumber) bool { return DozenOf(n) == d })
+	}
+	for column := 1; column <= 3; column++ {
+		c := column
+		add(CategoryColumn, fmt.Sprintf("column %d", c), func(n models.RouletteNumber) bool { return ColumnOf(n) == c })
+	}
+	add(CategoryColor, string(Red), func(n models.RouletteNumber) bool { return ColorOf(n) == Red })
+	add(CategoryColor, string(Black), func(n models.RouletteNumber) bool { return ColorOf(n) == Black })
+	add(CategoryParity, "even", func(n models.RouletteNumber) bool { return n%2 == 0 })
+	add(CategoryParity, "odd", func(n models.RouletteNumber) bool { return n%2 == 1 })
+	add(CategoryHalf, "1-18", func(n models.RouletteNumber) bool { return n <= 18 })
+	add(CategoryHalf, "19-36", func(n models.RouletteNumber) bool { return n >= 19 })
+	return groups
+}
+
+// TableGroups returns the dozens, columns, colors, even/odd and low/high groups
+func TableGroups() []Group {
+	return tableGroups
+}
+
+// GroupsByCategory returns the table groups of one category
+func GroupsByCategory(category string) []Group {
+	var groups []Group
+	for _, group := range tableGroups {
+		if group.Category == category {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
@@ -0,0 +1,123 @@
+package wheel
+
+import (
+	"testing"
+
+	"casino-backend/internal/models"
+)
+
+func TestLayoutsContainEveryPocketOnce(t *testing.T) {
+	for _, wheelType := range []models.WheelType{models.WheelEuropean, models.WheelAmerican, models.WheelTripleZero} {
+		layout, err := ForType(wheelType)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if layout.Size() != wheelType.PocketCount() {
+			t.Errorf("%s: layout has %d pockets, want %d", wheelType, layout.Size(), wheelType.PocketCount())
+		}
+
+		for _, pocket := range wheelType.Pockets() {
+			position, err := layout.Position(pocket)
+			if err != nil {
+				t.Errorf("%s: pocket %s missing from layout", wheelType, pocket)
+				continue
+			}
+			if layout.At(position) != pocket {
+				t.Errorf("%s: At(%d) = %s, want %s", wheelType, position, layout.At(position), pocket)
+			}
+		}
+	}
+}
+
+func TestDistances(t *testing.T) {
+	layout, _ := ForType(models.WheelEuropean)
+
+	tests := []struct {
+		from, to         models.RouletteNumber
+		clockwise, short int
+	}{
+		{from: 0, to: 32, clockwise: 1, short: 1},
+		{from: 32, to: 0, clockwise: 36, short: 1},
+		{from: 0, to: 26, clockwise: 36, short: 1},
+		{from: 0, to: 10, clockwise: 18, short: 18},
+		{from: 17, to: 17, clockwise: 0, short: 0},
+	}
+
+	for _, tt := range tests {
+		clockwise, err := layout.Distance(tt.from, tt.to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if clockwise != tt.clockwise {
+			t.Errorf("Distance(%s, %s) = %d, want %d", tt.from, tt.to, clockwise, tt.clockwise)
+		}
+
+		short, err := layout.ShortestDistance(tt.from, tt.to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if short != tt.short {
+			t.Errorf("ShortestDistance(%s, %s) = %d, want %d", tt.from, tt.to, short, tt.short)
+		}
+	}
+
+	if _, err := layout.Distance(0, models.DoubleZero); err == nil {
+		t.Error("expected error for 00 on the European wheel")
+	}
+}
+
+func TestRacetrackSectorsCoverEuropeanWheel(t *testing.T) {
+	seen := make(map[models.RouletteNumber]string)
+	for _, sector := range RacetrackSectors() {
+		for _, n := range sector.Numbers {
+			if other, ok := seen[n]; ok {
+				t.Errorf("%s appears in both %s and %s", n, other, sector.Name)
+			}
+			seen[n] = sector.Name
+		}
+	}
+
+	if len(seen) != models.WheelEuropean.PocketCount() {
+		t.Errorf("racetrack sectors cover %d pockets, want %d", len(seen), models.WheelEuropean.PocketCount())
+	}
+
+	layout, _ := ForType(models.WheelEuropean)
+	sector, err := layout.NeighboursSector(0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []models.RouletteNumber{3, 26, 0, 32, 15}
+	for i, n := range want {
+		if sector.Numbers[i] != n {
+			t.Fatalf("neighbours of 0 = %v, want %v", sector.Numbers, want)
+		}
+	}
+}
+
+func TestSpinSources(t *testing.T) {
+	first, second := SeededSource(7), SeededSource(7)
+	for i := 0; i < 100; i++ {
+		a, err := Spin(models.WheelTripleZero, first)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := Spin(models.WheelTripleZero, second)
+		if a != b {
+			t.Fatalf("spin %d differs between sources with the same seed: %s and %s", i, a, b)
+		}
+		if !models.WheelTripleZero.Contains(a) {
+			t.Fatalf("%s is not a pocket of the triple-zero wheel", a)
+		}
+	}
+
+	for i := 0; i < 100; i++ {
+		n, err := Spin(models.WheelEuropean, CryptoSource())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !models.WheelEuropean.Contains(n) {
+			t.Fatalf("%s is not a pocket of the European wheel", n)
+		}
+	}
+}
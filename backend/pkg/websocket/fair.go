@@ -0,0 +1,97 @@
+package websocket
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"casino-backend/internal/models"
+	"casino-backend/internal/provablyfair"
+)
+
+// FairSeedChange is broadcast to the room whenever its seed is rotated
+type FairSeedChange struct {
+	Revealed *provablyfair.Seed `json:"revealed,omitempty"` // The previous seed, server seed included
+	Active   *provablyfair.Seed `json:"active"`             // The new commitment, server seed hidden
+}
+
+// RotateFairSeed reveals the active seed of a session and commits to a new one
+func (h *Hub) RotateFairSeed(key, clientSeed string) (*FairSeedChange, error) {
+	h.fairMu.Lock()
+	defer h.fairMu.Unlock()
+	return h.rotateFairSeed(key, clientSeed)
+}
+
+// rotateFairSeed does the rotation; the caller must hold fairMu
+func (h *Hub) rotateFairSeed(key, clientSeed string) (*FairSeedChange, error) {
+	next, err := provablyfair.NewSeed(key, clientSeed)
+	if err != nil {
+		return nil, err
+	}
+	revealed, active, err := h.repo.RotateFairSeed(next)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate seed: %w", err)
+	}
+
+	change := &FairSeedChange{Revealed: revealed, Active: active.Public()}
+	h.BroadcastToSession(key, &models.WSMessage{
+		Type: "fairSeed",
+		Key:  key,
+		Data: change,
+	})
+	log.Printf("[FAIR] Session %s committed to seed %d (%s)", key, active.ID, active.ServerSeedHash)
+	return change, nil
+}
+
+// fairCommitment returns the public active seed of a session, committing to a
+// new one with the given client seed when there is none
+func (h *Hub) fairCommitment(key, clientSeed string) (*provablyfair.Seed, error) {
+	h.fairMu.Lock()
+	defer h.fairMu.Unlock()
+
+	seed, err := h.repo.GetActiveFairSeed(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get seed: %w", err)
+	}
+	if seed != nil {
+		return seed.Public(), nil
+	}
+	change, err := h.rotateFairSeed(key, clientSeed)
+	if err != nil {
+		return nil, err
+	}
+	return change.Active, nil
+}
+
+// fairSpin derives the next spin of a session from its active seed, adds it
+// to the history and records the seed and nonce it came from
+func (h *Hub) fairSpin(key string, wheelType models.WheelType) (*models.RouletteSession, models.RouletteNumber, error) {
+	h.fairMu.Lock()
+	defer h.fairMu.Unlock()
+
+	seed, err := h.repo.GetActiveFairSeed(key)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get seed: %w", err)
+	}
+	if seed == nil {
+		return nil, 0, fmt.Errorf("session %s has no active seed", key)
+	}
+
+	number, err := provablyfair.Spin(wheelType, seed.ServerSeed, seed.ClientSeed, seed.Nonce)
+	if err != nil {
+		return nil, 0, err
+	}
+	// The number only enters the history together with its nonce, so a failed
+	// write neither loses the record nor lets the next spin reuse the nonce
+	session, err := h.repo.AddFairSpin(&provablyfair.Record{
+		SeedID:     seed.ID,
+		SessionKey: key,
+		Nonce:      seed.Nonce,
+		Number:     number,
+		CreatedAt:  time.Now(),
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to add fair spin: %w", err)
+	}
+	return session, number, nil
+}
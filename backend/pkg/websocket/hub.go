@@ -3,6 +3,7 @@ package websocket
 import (
 	"crypto/rand"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net"
@@ -10,12 +11,22 @@ import (
 	"sync"
 	"time"
 
+	"casino-backend/internal/alerts"
+	"casino-backend/internal/analytics"
+	"casino-backend/internal/auth"
+	"casino-backend/internal/betting"
 	"casino-backend/internal/database"
 	"casino-backend/internal/models"
 
 	"github.com/gorilla/websocket"
 )
 
+// errAuthRequired is returned when a client joins a protected session without a valid token.
+var errAuthRequired = errors.New("authorization required")
+
+// errReadOnly is returned when a viewer tries to change the room.
+var errReadOnly = errors.New("viewers cannot change the room")
+
 var upgrader = websocket.Upgrader{
 	CheckOrigin: func(r *http.Request) bool {
 		// Allow connections from any origin
@@ -43,24 +54,45 @@ type Hub struct {
 	// Session tracking for admin panel.
 	adminSessions map[string]*SessionData
 	mu            sync.RWMutex
-	jwtSecret     []byte
+
+	// Room tokens presented on join, and the revocations that disconnect their holders.
+	tokens *auth.RoomTokens
+	revoke chan *auth.Revocation
+
+	// Wheel type per joined session, used to validate added numbers and bets.
+	wheels map[string]models.WheelType
+
+	// Virtual spinners of practice rooms.
+	spinners map[string]*spinner
+
+	// Serialises provably fair spins and seed rotations so that every nonce
+	// of a seed is used exactly once and in order.
+	fairMu sync.Mutex
+
+	// Serialises bet settlements, since numbers added concurrently may be
+	// reported out of order.
+	settleMu sync.Mutex
 }
 
 // WSMessageWithClient wraps a WSMessage with the client that sent it.
+// Server-originated messages have no client and carry SessionKey instead.
 type WSMessageWithClient struct {
-	Message *models.WSMessage
-	Client  *Client
+	Message    *models.WSMessage
+	Client     *Client
+	SessionKey string
 }
 
 // ClientInfo contains metadata about the client for the admin panel.
 type ClientInfo struct {
-	ID           string    `json:"id"`
-	ConnectedAt  time.Time `json:"connectedAt"`
-	LastActivity time.Time `json:"lastActivity"`
-	Status       string    `json:"status"`
-	IPAddress    string    `json:"ipAddress"`
-	UserAgent    string    `json:"userAgent"`
-	SessionKey   string    `json:"sessionKey"`
+	ID           string        `json:"id"`
+	ConnectedAt  time.Time     `json:"connectedAt"`
+	LastActivity time.Time     `json:"lastActivity"`
+	Status       string        `json:"status"`
+	IPAddress    string        `json:"ipAddress"`
+	UserAgent    string        `json:"userAgent"`
+	SessionKey   string        `json:"sessionKey"`
+	PlayerID     string        `json:"playerId,omitempty"` // Issued with the room token, so stable across reconnects unlike ID
+	Role         auth.RoomRole `json:"role,omitempty"`
 }
 
 // SessionData contains session data for the admin panel.
@@ -82,18 +114,27 @@ type Client struct {
 
 	// Client metadata
 	info *ClientInfo
+
+	// Claims of the token the client joined with, nil for open rooms joined without one
+	claims *auth.RoomClaims
+
+	// Last frame before the hub drops the client, e.g. when its token is revoked
+	kick chan []byte
 }
 
 // NewHub creates a new WebSocket hub
-func NewHub(repo database.RouletteRepositoryInterface, jwtSecret []byte) *Hub {
+func NewHub(repo database.RouletteRepositoryInterface, tokens *auth.RoomTokens) *Hub {
 	return &Hub{
 		sessions:      make(map[string]map[*Client]bool),
 		broadcast:     make(chan *WSMessageWithClient),
 		register:      make(chan *Client),
 		unregister:    make(chan *Client),
 		repo:          repo,
-		jwtSecret:     jwtSecret,
+		tokens:        tokens,
+		revoke:        make(chan *auth.Revocation),
 		adminSessions: make(map[string]*SessionData),
+		wheels:        make(map[string]models.WheelType),
+		spinners:      make(map[string]*spinner),
 	}
 }
 
@@ -117,6 +158,8 @@ func (h *Hub) Run() {
 						close(client.send)
 						if len(sessionClients) == 0 {
 							delete(h.sessions, client.info.SessionKey)
+							// Nobody is left to watch the spins of a practice room
+							h.StopSpinner(client.info.SessionKey)
 						}
 						log.Printf("Client %s unregistered from session %s", client.info.ID, client.info.SessionKey)
 					}
@@ -131,8 +174,13 @@ func (h *Hub) Run() {
 				}
 				h.mu.Unlock()
 			}
+		case revocation := <-h.revoke:
+			h.kickRevoked(revocation)
 		case messageWithClient := <-h.broadcast:
-			sessionKey := messageWithClient.Client.info.SessionKey
+			sessionKey := messageWithClient.SessionKey
+			if messageWithClient.Client != nil {
+				sessionKey = messageWithClient.Client.info.SessionKey
+			}
 			if sessionClients, ok := h.sessions[sessionKey]; ok {
 				messageBytes, err := json.Marshal(messageWithClient.Message)
 				if err != nil {
@@ -177,6 +225,7 @@ func (h *Hub) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 		conn: conn,
 		send: make(chan []byte, 256),
 		info: clientInfo,
+		kick: make(chan []byte, 1),
 	}
 
 	// Client registration is handled in the readPump after the 'join' message
@@ -206,6 +255,9 @@ func (c *Client) readPump() {
 		var message models.WSMessage
 		if err := json.Unmarshal(messageBytes, &message); err != nil {
 			log.Printf("Error parsing WebSocket message: %v", err)
+			if errors.Is(err, models.ErrInvalidNumber) {
+				c.sendError(err)
+			}
 			continue
 		}
 
@@ -219,21 +271,20 @@ func (c *Client) readPump() {
 		}
 		c.hub.mu.Unlock()
 
-
 		response, err := c.handleMessage(message)
 		if err != nil {
 			log.Printf("Error handling WebSocket message: %v", err)
-			errorResponse := models.WSMessage{Type: "error", Error: err.Error()}
-			if responseBytes, err := json.Marshal(errorResponse); err == nil {
-				c.send <- responseBytes
-			}
+			c.sendError(err)
 			continue
 		}
 
 		if response != nil {
 			// Broadcast to all clients in the same session for state-changing events
-			if message.Type == "add" || message.Type == "remove" {
+			if message.Type == "add" || message.Type == "remove" || changesSpinner(message) {
 				c.hub.broadcast <- &WSMessageWithClient{Message: response, Client: c}
+				if message.Type == "add" {
+					c.hub.afterNumberAdded(response)
+				}
 			} else {
 				// Send other messages (like history sync on join) only to the requesting client
 				responseBytes, err := json.Marshal(response)
@@ -247,6 +298,23 @@ func (c *Client) readPump() {
 	}
 }
 
+// sendError sends an error frame to the client, with validation details when available
+func (c *Client) sendError(err error) {
+	errorResponse := models.WSMessage{Type: "error", Error: err.Error()}
+	if errors.Is(err, errAuthRequired) {
+		errorResponse.Type = "authRequired"
+	}
+
+	var numberErr *models.NumberError
+	if errors.As(err, &numberErr) {
+		errorResponse.Details = numberErr
+	}
+
+	if responseBytes, err := json.Marshal(errorResponse); err == nil {
+		c.send <- responseBytes
+	}
+}
+
 // writePump pumps messages from the hub to the websocket connection
 func (c *Client) writePump() {
 	defer c.conn.Close()
@@ -264,12 +332,20 @@ func (c *Client) writePump() {
 				log.Printf("WebSocket write error: %v", err)
 				return
 			}
+		case message := <-c.kick:
+			c.conn.WriteMessage(websocket.TextMessage, message)
+			c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "token revoked"))
+			return
 		}
 	}
 }
 
 // handleMessage processes incoming WebSocket messages
 func (c *Client) handleMessage(message models.WSMessage) (*models.WSMessage, error) {
+	if changesRoom(message) && !c.info.Role.CanEdit() {
+		return nil, errReadOnly
+	}
+
 	switch message.Type {
 	case "join":
 		// Handle registration here since we now have the session key
@@ -277,12 +353,25 @@ func (c *Client) handleMessage(message models.WSMessage) (*models.WSMessage, err
 		if err != nil {
 			return nil, err
 		}
-		// Return history to the joining client
+		// Return history to the joining client, with the player ID to reuse on reconnect
+		response, err := c.handleGetHistory(message)
+		if err != nil {
+			return nil, err
+		}
+		response.PlayerID = c.info.PlayerID
+		return response, nil
+	case "resync":
 		return c.handleGetHistory(message)
 	case "add":
 		return c.handleAddNumber(message)
 	case "remove":
 		return c.handleRemoveNumber(message)
+	case "bet":
+		return c.handlePlaceBet(message)
+	case "bankroll":
+		return c.handleOpenBankroll(message)
+	case "spinner":
+		return c.handleSpinner(message)
 	default:
 		return nil, fmt.Errorf("unknown message type: %s", message.Type)
 	}
@@ -293,6 +382,9 @@ func (c *Client) handleAddNumber(message models.WSMessage) (*models.WSMessage, e
 	if message.Number == nil {
 		return nil, fmt.Errorf("number is missing in 'add' message")
 	}
+	if err := models.ValidateNumber(c.hub.sessionWheel(c.info.SessionKey), *message.Number); err != nil {
+		return nil, err
+	}
 	if c.info.SessionKey == "" {
 		return nil, fmt.Errorf("client has no session key")
 	}
@@ -303,14 +395,105 @@ func (c *Client) handleAddNumber(message models.WSMessage) (*models.WSMessage, e
 	}
 
 	// The response will be broadcast to all clients in the session.
+	return c.hub.addMessage(session, *message.Number), nil
+}
+
+// handlePlaceBet records a bet slip to be settled on the next added number.
+// The confirmation is sent only to the player who placed it.
+func (c *Client) handlePlaceBet(message models.WSMessage) (*models.WSMessage, error) {
+	if c.info.SessionKey == "" {
+		return nil, fmt.Errorf("client has no session key")
+	}
+	if len(message.Slip) == 0 {
+		return nil, fmt.Errorf("slip is missing in 'bet' message")
+	}
+
+	var slip betting.Slip
+	if err := json.Unmarshal(message.Slip, &slip); err != nil {
+		return nil, fmt.Errorf("invalid bet slip: %w", err)
+	}
+	if err := slip.Validate(c.hub.sessionWheel(c.info.SessionKey)); err != nil {
+		return nil, err
+	}
+	if err := c.checkBalance(slip); err != nil {
+		return nil, err
+	}
+
+	wager, err := c.hub.repo.PlaceWager(betting.NewWager(c.info.SessionKey, c.info.PlayerID, slip))
+	if err != nil {
+		return nil, fmt.Errorf("failed to place bet: %w", err)
+	}
+
 	return &models.WSMessage{
-		Type:    "add",
-		Key:     c.info.SessionKey,
-		Number:  message.Number,
-		Version: len(session.History),
+		Type: "betPlaced",
+		Key:  c.info.SessionKey,
+		Data: wager,
 	}, nil
 }
 
+// checkBalance refuses a slip larger than what the player's open bankroll has
+// left once the pending bets are paid. Players without a bankroll bet freely.
+func (c *Client) checkBalance(slip betting.Slip) error {
+	bankroll, err := c.hub.repo.GetBankroll(c.info.SessionKey, c.info.PlayerID)
+	if err != nil {
+		return fmt.Errorf("failed to get bankroll: %w", err)
+	}
+	if bankroll == nil {
+		return nil
+	}
+	pending, err := c.hub.repo.GetPendingWagers(c.info.SessionKey)
+	if err != nil {
+		return fmt.Errorf("failed to get pending bets: %w", err)
+	}
+
+	available := bankroll.Balance
+	for _, wager := range pending {
+		if wager.PlayerID == c.info.PlayerID {
+			available -= wager.Staked
+		}
+	}
+	if slip.Total() > available {
+		return fmt.Errorf("slip total %d exceeds the available balance %d", slip.Total(), available)
+	}
+	return nil
+}
+
+// handleSpinner applies a control message to the virtual spinner of the room.
+// Every action except 'status' is broadcast to the whole room.
+func (c *Client) handleSpinner(message models.WSMessage) (*models.WSMessage, error) {
+	if c.info.SessionKey == "" {
+		return nil, fmt.Errorf("client has no session key")
+	}
+	if message.Spinner == nil {
+		return nil, fmt.Errorf("spinner is missing in 'spinner' message")
+	}
+
+	state, err := c.hub.controlSpinner(c.info.SessionKey, *message.Spinner)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.WSMessage{
+		Type: "spinner",
+		Key:  c.info.SessionKey,
+		Data: state,
+	}, nil
+}
+
+// changesRoom reports whether a message writes to the room, which viewers may not do
+func changesRoom(message models.WSMessage) bool {
+	switch message.Type {
+	case "add", "remove", "bet", "bankroll":
+		return true
+	}
+	return changesSpinner(message)
+}
+
+// changesSpinner reports whether a message changed the spinner of the room
+func changesSpinner(message models.WSMessage) bool {
+	return message.Type == "spinner" && message.Spinner != nil && message.Spinner.Action != models.SpinnerStatus
+}
+
 // handleRemoveNumber handles removing a number and prepares it for broadcast.
 func (c *Client) handleRemoveNumber(message models.WSMessage) (*models.WSMessage, error) {
 	if c.info.SessionKey == "" {
@@ -324,29 +507,79 @@ func (c *Client) handleRemoveNumber(message models.WSMessage) (*models.WSMessage
 		return nil, fmt.Errorf("failed to remove number: %w", err)
 	}
 
-	// The response will be broadcast to all clients in the session.
+	// The response will be broadcast to all clients in the session. The
+	// repository has moved the history to a new revision, since everything
+	// after the removed index has shifted.
 	return &models.WSMessage{
-		Type:    "remove",
-		Key:     c.info.SessionKey,
-		Index:   message.Index,
-		Version: len(session.History),
+		Type:     "remove",
+		Key:      c.info.SessionKey,
+		Index:    message.Index,
+		Version:  len(session.History),
+		Revision: session.Revision,
+	}, nil
+}
+
+// handleOpenBankroll opens the bankroll of the player with a starting balance.
+// Players who bet without opening one get a bankroll starting at zero.
+func (c *Client) handleOpenBankroll(message models.WSMessage) (*models.WSMessage, error) {
+	if c.info.SessionKey == "" {
+		return nil, fmt.Errorf("client has no session key")
+	}
+	if message.Amount <= 0 {
+		return nil, fmt.Errorf("starting balance must be positive")
+	}
+
+	bankroll, event := betting.NewBankroll(c.info.SessionKey, c.info.PlayerID, message.Amount)
+	opened, err := c.hub.repo.OpenBankroll(bankroll, event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bankroll: %w", err)
+	}
+	if !opened {
+		return nil, fmt.Errorf("bankroll is already open for player %s", c.info.PlayerID)
+	}
+
+	return &models.WSMessage{
+		Type: "bankroll",
+		Key:  c.info.SessionKey,
+		Data: []*betting.Bankroll{bankroll},
 	}, nil
 }
 
-// handleGetHistory fetches history for a session.
+// handleGetHistory fetches history for a session. When the client reports a
+// version of the current revision, only the numbers added since that version
+// are returned; otherwise the full history is sent.
 func (c *Client) handleGetHistory(message models.WSMessage) (*models.WSMessage, error) {
 	if c.info.SessionKey == "" {
 		return nil, fmt.Errorf("client has no session key")
 	}
+
 	session, err := c.hub.repo.GetSession(c.info.SessionKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get session: %w", err)
 	}
+	if session == nil {
+		return nil, fmt.Errorf("session %s not found", c.info.SessionKey)
+	}
+
+	// The revision and the history come from the same read, so a delta is
+	// never cut from a history that was edited in between
+	if message.Version > 0 && message.Revision == session.Revision && message.Version <= len(session.History) {
+		return &models.WSMessage{
+			Type:     "sync",
+			Key:      c.info.SessionKey,
+			History:  session.History[message.Version:],
+			Version:  len(session.History),
+			Revision: session.Revision,
+		}, nil
+	}
+
 	return &models.WSMessage{
-		Type:    "sync",
-		Key:     c.info.SessionKey,
-		History: session.History,
-		Full:    true,
+		Type:     "sync",
+		Key:      c.info.SessionKey,
+		History:  session.History,
+		Full:     true,
+		Version:  len(session.History),
+		Revision: session.Revision,
 	}, nil
 }
 
@@ -369,13 +602,203 @@ func (c *Client) handleJoinAndRegister(message models.WSMessage) error {
 		}
 	}
 
-	// Here you would typically validate the token `message.Token`
+	// Password-protected rooms require the token issued by /api/rooms/auth.
+	// Clients of open rooms without a token may edit, as before roles existed.
+	role := auth.RoomEditor
+	var claims *auth.RoomClaims
+	if session.Password != "" || message.Token != "" {
+		claims, err = c.hub.tokens.Parse(message.Token, message.Key)
+		if err != nil {
+			log.Printf("[WS] Client %s rejected from session %s: %v", c.info.ID, message.Key, err)
+			return fmt.Errorf("%w: %v", errAuthRequired, err)
+		}
+		role = claims.Role
+	}
+
+	// The player ID comes with the token, so nobody can bet as another player.
+	// Clients without one get an ID for this connection only.
+	playerID := generateClientID()
+	if claims != nil && claims.PlayerID != "" {
+		playerID = claims.PlayerID
+	}
+	if message.PlayerID != "" && message.PlayerID != playerID {
+		return fmt.Errorf("player ID %s does not belong to this token", message.PlayerID)
+	}
+
 	c.info.SessionKey = message.Key
+	c.info.PlayerID = playerID
+	c.info.Role = role
+	c.claims = claims
+	c.hub.setSessionWheel(message.Key, session.WheelType)
 	c.hub.register <- c
 	c.hub.updateClientSession(c, message.Key)
 	return nil
 }
 
+// sessionWheel returns the wheel type of a joined session
+func (h *Hub) sessionWheel(sessionKey string) models.WheelType {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.wheels[sessionKey]
+}
+
+// setSessionWheel records the wheel type of a session for its clients
+func (h *Hub) setSessionWheel(sessionKey string, wheelType models.WheelType) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.wheels[sessionKey] = wheelType
+}
+
+// BroadcastToSession sends a server-originated message to every client in a session.
+func (h *Hub) BroadcastToSession(sessionKey string, message *models.WSMessage) {
+	h.broadcast <- &WSMessageWithClient{Message: message, SessionKey: sessionKey}
+}
+
+// NumberAdded notifies connected clients about a number added outside of the
+// WebSocket flow (e.g. via REST) and pushes the updated forecast.
+func (h *Hub) NumberAdded(session *models.RouletteSession, number models.RouletteNumber) {
+	message := h.addMessage(session, number)
+	h.BroadcastToSession(session.Key, message)
+	h.afterNumberAdded(message)
+}
+
+// afterNumberAdded follows an 'add' broadcast with the bankrolls of the
+// players whose bets were settled and with the updated forecast.
+func (h *Hub) afterNumberAdded(message *models.WSMessage) {
+	if settled, ok := message.Data.([]*betting.Wager); ok {
+		h.publishBankrolls(message.Key, settled)
+	}
+	h.PublishForecast(message.Key)
+}
+
+// publishBankrolls broadcasts the bankrolls of the players of the settled wagers
+func (h *Hub) publishBankrolls(sessionKey string, settled []*betting.Wager) {
+	seen := make(map[string]bool)
+	var bankrolls []*betting.Bankroll
+	for _, wager := range settled {
+		if seen[wager.PlayerID] {
+			continue
+		}
+		seen[wager.PlayerID] = true
+
+		bankroll, err := h.repo.GetBankroll(sessionKey, wager.PlayerID)
+		if err != nil || bankroll == nil {
+			log.Printf("[HUB] Failed to load bankroll of player %s: %v", wager.PlayerID, err)
+			continue
+		}
+		bankrolls = append(bankrolls, bankroll)
+	}
+	if len(bankrolls) == 0 {
+		return
+	}
+
+	h.BroadcastToSession(sessionKey, &models.WSMessage{
+		Type: "bankroll",
+		Key:  sessionKey,
+		Data: bankrolls,
+	})
+}
+
+// addMessage settles the pending bets of a session once a number was just
+// added and builds the 'add' broadcast, carrying the settled bets in Data.
+func (h *Hub) addMessage(session *models.RouletteSession, number models.RouletteNumber) *models.WSMessage {
+	message := &models.WSMessage{
+		Type:     "add",
+		Key:      session.Key,
+		Number:   &number,
+		Version:  len(session.History),
+		Revision: session.Revision,
+	}
+	if settled := h.settleWagers(session); len(settled) > 0 {
+		message.Data = settled
+	}
+	return message
+}
+
+// settleWagers settles the pending wagers of a session. Each wager is settled
+// against the first number added after it was placed, whichever add gets here
+// first, so numbers added concurrently never swap their wagers. A wager placed
+// after the last number of the snapshot waits for the next spin.
+func (h *Hub) settleWagers(session *models.RouletteSession) []*betting.Wager {
+	h.settleMu.Lock()
+	defer h.settleMu.Unlock()
+
+	pending, err := h.repo.GetPendingWagers(session.Key)
+	if err != nil {
+		log.Printf("[HUB] Failed to load pending bets of session %s: %v", session.Key, err)
+		return nil
+	}
+
+	settled := make([]*betting.Wager, 0, len(pending))
+	for _, wager := range pending {
+		if wager.FirstSpin >= len(session.History) {
+			continue
+		}
+		result, err := betting.Settle(session.WheelType, wager.Slip, session.History[wager.FirstSpin])
+		if err != nil {
+			log.Printf("[HUB] Failed to settle bet %d of session %s: %v", wager.ID, session.Key, err)
+			continue
+		}
+		stored, err := h.repo.SettleWager(wager.ID, wager.FirstSpin, result)
+		if err != nil {
+			log.Printf("[HUB] Failed to store result of bet %d: %v", wager.ID, err)
+			continue
+		}
+		settled = append(settled, stored)
+		h.applyToBankroll(stored)
+	}
+	return settled
+}
+
+// applyToBankroll records a settled wager in the bankroll of its player
+func (h *Hub) applyToBankroll(wager *betting.Wager) {
+	if _, err := h.repo.SettleBankroll(wager); err != nil {
+		log.Printf("[HUB] Failed to update bankroll of player %s: %v", wager.PlayerID, err)
+	}
+}
+
+// PublishForecast recomputes the combined forecast of a session with the
+// default configuration and pushes it to every client in the session.
+func (h *Hub) PublishForecast(sessionKey string) {
+	session, err := h.repo.GetSession(sessionKey)
+	if err != nil || session == nil {
+		log.Printf("[HUB] Failed to load session %s for forecast: %v", sessionKey, err)
+		return
+	}
+
+	forecast := analytics.BuildCombinedForecast(session.WheelType, session.History, analytics.DefaultForecastConfig)
+	h.BroadcastToSession(sessionKey, &models.WSMessage{
+		Type:    "forecast",
+		Key:     sessionKey,
+		Version: len(session.History),
+		Data:    forecast,
+	})
+}
+
+// HistoryReplaced notifies connected clients that the whole history of a
+// session was replaced outside of the WebSocket flow (e.g. via REST). The
+// wheel type may have changed with it.
+func (h *Hub) HistoryReplaced(session *models.RouletteSession) {
+	h.setSessionWheel(session.Key, session.WheelType)
+	h.BroadcastToSession(session.Key, &models.WSMessage{
+		Type:     "sync",
+		Key:      session.Key,
+		History:  session.History,
+		Full:     true,
+		Version:  len(session.History),
+		Revision: session.Revision,
+	})
+}
+
+// AlertFired implements analytics.AlertListener by pushing the alert to the room
+func (h *Hub) AlertFired(alert *alerts.Alert) {
+	h.BroadcastToSession(alert.SessionKey, &models.WSMessage{
+		Type: "alert",
+		Key:  alert.SessionKey,
+		Data: alert,
+	})
+}
+
 func generateClientID() string {
 	b := make([]byte, 16)
 	_, err := rand.Read(b)
@@ -399,7 +822,7 @@ func getClientIP(r *http.Request) string {
 func (h *Hub) GetSessionsData() map[string]*SessionData {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
-	
+
 	// Deep copy to avoid race conditions on the returned map
 	result := make(map[string]*SessionData, len(h.adminSessions))
 	for key, session := range h.adminSessions {
@@ -457,6 +880,35 @@ func (h *Hub) DisconnectClient(clientID string) error {
 	return fmt.Errorf("client with ID %s not found", clientID)
 }
 
+// TokensRevoked implements auth.RevocationListener: the clients holding
+// revoked tokens are told to authenticate again and disconnected.
+func (h *Hub) TokensRevoked(revocation *auth.Revocation) {
+	h.revoke <- revocation
+}
+
+// kickRevoked drops the clients of a room whose tokens a revocation matches.
+// Revoking a whole room also drops the clients that joined it without a token
+// while it was open. Called from Run, which owns h.sessions.
+func (h *Hub) kickRevoked(revocation *auth.Revocation) {
+	message, err := json.Marshal(models.WSMessage{Type: "authRequired", Error: "token was revoked"})
+	if err != nil {
+		log.Printf("Error marshalling revocation message: %v", err)
+		return
+	}
+
+	for c := range h.sessions[revocation.SessionKey] {
+		revoked := revocation.Matches(c.claims) || c.claims == nil && revocation.All
+		if !revoked {
+			continue
+		}
+		log.Printf("[WS] Disconnecting client %s from session %s: token revoked", c.info.ID, revocation.SessionKey)
+		select {
+		case c.kick <- message:
+		default:
+		}
+	}
+}
+
 func (h *Hub) updateClientSession(client *Client, sessionKey string) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
@@ -474,6 +926,6 @@ func (h *Hub) updateClientSession(client *Client, sessionKey string) {
 	client.info.LastActivity = time.Now()
 	h.adminSessions[sessionKey].Connections[client.info.ID] = client.info
 	h.adminSessions[sessionKey].LastActivity = time.Now()
-	
+
 	log.Printf("[HUB] Admin session %s now has %d connections", sessionKey, len(h.adminSessions[sessionKey].Connections))
-} 
\ No newline at end of file
+}
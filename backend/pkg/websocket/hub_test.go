@@ -0,0 +1,451 @@
+package websocket
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"casino-backend/internal/auth"
+	"casino-backend/internal/betting"
+	"casino-backend/internal/database"
+	"casino-backend/internal/models"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestJoinChecksTheRoomToken(t *testing.T) {
+	secret := []byte("test-secret")
+	repo := database.NewMemoryRepository()
+	hub := NewHub(repo, auth.NewRoomTokens(repo, secret))
+	go hub.Run()
+
+	if _, err := repo.CreateSessionWithPassword("room", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	sign := func(method jwt.SigningMethod, key interface{}, sessionKey string, expiresAt time.Time) string {
+		claims := jwt.MapClaims{
+			"key": sessionKey,
+			"exp": expiresAt.Unix(),
+		}
+		token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	later := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"missing token", ""},
+		{"other room", sign(jwt.SigningMethodHS256, secret, "other", later)},
+		{"expired", sign(jwt.SigningMethodHS256, secret, "room", time.Now().Add(-time.Minute))},
+		{"HS512", sign(jwt.SigningMethodHS512, secret, "room", later)},
+		{"unsigned", sign(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "room", later)},
+		{"wrong secret", sign(jwt.SigningMethodHS256, []byte("other-secret"), "room", later)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &Client{hub: hub, send: make(chan []byte, 16), info: &ClientInfo{ID: tt.name}}
+			err := client.handleJoinAndRegister(models.WSMessage{Type: "join", Key: "room", Token: tt.token})
+			if !errors.Is(err, errAuthRequired) {
+				t.Errorf("got %v, want errAuthRequired", err)
+			}
+			if client.info.SessionKey != "" {
+				t.Error("rejected client joined the room")
+			}
+		})
+	}
+
+	client := &Client{hub: hub, send: make(chan []byte, 16), info: &ClientInfo{ID: "valid"}}
+	token := sign(jwt.SigningMethodHS256, secret, "room", later)
+	if err := client.handleJoinAndRegister(models.WSMessage{Type: "join", Key: "room", Token: token}); err != nil {
+		t.Fatalf("valid token: %v", err)
+	}
+	if client.info.SessionKey != "room" {
+		t.Errorf("unexpected client %+v", client.info)
+	}
+}
+
+func TestHistoryRevisionOutlivesTheHub(t *testing.T) {
+	repo := database.NewMemoryRepository()
+	if _, err := repo.UpdateSessionHistory("room", []models.RouletteNumber{1, 2, 3}); err != nil {
+		t.Fatal(err)
+	}
+	session, err := repo.RemoveNumberFromSession("room", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.Revision != 2 {
+		t.Fatalf("revision = %d, want 2", session.Revision)
+	}
+	if _, err := repo.AddNumberToSession("room", 4); err != nil {
+		t.Fatal(err)
+	}
+
+	// A fresh hub, as after a restart, still knows the revision
+	hub := NewHub(repo, auth.NewRoomTokens(repo, []byte("test-secret")))
+	client := &Client{hub: hub, info: &ClientInfo{SessionKey: "room"}}
+
+	sync, err := client.handleGetHistory(models.WSMessage{Type: "resync", Version: 2, Revision: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sync.Full || len(sync.History) != 1 || sync.History[0] != 4 || sync.Version != 3 {
+		t.Errorf("expected a delta of one number, got %+v", sync)
+	}
+
+	sync, err = client.handleGetHistory(models.WSMessage{Type: "resync", Version: 2, Revision: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sync.Full || len(sync.History) != 3 || sync.Revision != 2 {
+		t.Errorf("expected the full history for a stale revision, got %+v", sync)
+	}
+}
+
+func TestAddMessageSettlesPendingBets(t *testing.T) {
+	repo := database.NewMemoryRepository()
+	hub := NewHub(repo, auth.NewRoomTokens(repo, []byte("test-secret")))
+
+	if _, err := repo.CreateSession("room"); err != nil {
+		t.Fatal(err)
+	}
+	slip := betting.Slip{Bets: []betting.Bet{
+		{Type: betting.BetStraight, Numbers: []models.RouletteNumber{7}, Amount: 10},
+		{Type: betting.BetBlack, Amount: 5},
+	}}
+	if _, err := repo.PlaceWager(betting.NewWager("room", "player", slip)); err != nil {
+		t.Fatal(err)
+	}
+
+	session, err := repo.AddNumberToSession("room", 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := hub.addMessage(session, 7)
+
+	settled, ok := message.Data.([]*betting.Wager)
+	if !ok || len(settled) != 1 {
+		t.Fatalf("expected one settled bet in the add message, got %#v", message.Data)
+	}
+	if settled[0].SpinIndex != 0 || settled[0].Result.Returned != 360 || settled[0].Result.Net != 345 {
+		t.Errorf("unexpected settlement %+v", settled[0].Result)
+	}
+
+	bankroll, err := repo.GetBankroll("room", "player")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bankroll == nil || bankroll.StartingBalance != 0 || bankroll.Balance != 345 || bankroll.Spins != 1 {
+		t.Errorf("unexpected bankroll %+v", bankroll)
+	}
+
+	pending, err := repo.GetPendingWagers("room")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("%d bets still pending", len(pending))
+	}
+
+	// A bet placed once the number is stored waits for the next spin
+	session, err = repo.AddNumberToSession("room", 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	late, err := repo.PlaceWager(betting.NewWager("room", "player", slip))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message := hub.addMessage(session, 8); message.Data != nil {
+		t.Errorf("unexpected data %#v", message.Data)
+	}
+
+	session, err = repo.AddNumberToSession("room", 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	settled, _ = hub.addMessage(session, 9).Data.([]*betting.Wager)
+	if len(settled) != 1 || settled[0].ID != late.ID || settled[0].SpinIndex != 2 {
+		t.Errorf("expected the late bet to settle on spin 2, got %#v", settled)
+	}
+}
+
+func TestConcurrentSpinsSettleTheirOwnBets(t *testing.T) {
+	repo := database.NewMemoryRepository()
+	hub := NewHub(repo, auth.NewRoomTokens(repo, []byte("test-secret")))
+	slip := betting.Slip{Bets: []betting.Bet{{Type: betting.BetStraight, Numbers: []models.RouletteNumber{7}, Amount: 10}}}
+
+	for i := 0; i < 20; i++ {
+		key := fmt.Sprintf("room-%d", i)
+		if _, err := repo.CreateSession(key); err != nil {
+			t.Fatal(err)
+		}
+		wager, err := repo.PlaceWager(betting.NewWager(key, "player", slip))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var wg sync.WaitGroup
+		for _, n := range []models.RouletteNumber{7, 8} {
+			wg.Add(1)
+			go func(n models.RouletteNumber) {
+				defer wg.Done()
+				session, err := repo.AddNumberToSession(key, n)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				hub.addMessage(session, n)
+			}(n)
+		}
+		wg.Wait()
+
+		session, err := repo.GetSession(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wagers, err := repo.GetSessionWagers(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(wagers) != 1 || wagers[0].ID != wager.ID || wagers[0].SpinIndex != 0 || wagers[0].Result == nil ||
+			wagers[0].Result.Number != session.History[0] {
+			t.Fatalf("%s: expected the bet settled once against spin 0 of %v, got %+v", key, session.History, wagers)
+		}
+	}
+
+	// The later spin reported first leaves the earlier one's number to the bet
+	if _, err := repo.CreateSession("room"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.PlaceWager(betting.NewWager("room", "player", slip)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.AddNumberToSession("room", 7); err != nil {
+		t.Fatal(err)
+	}
+	session, err := repo.AddNumberToSession("room", 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	settled, _ := hub.addMessage(session, 8).Data.([]*betting.Wager)
+	if len(settled) != 1 || settled[0].SpinIndex != 0 || settled[0].Result.Number != 7 || settled[0].Result.Net != 350 {
+		t.Errorf("expected the bet to settle against 7 on spin 0, got %#v", settled)
+	}
+}
+
+func TestBankrollUpdatesAreNotLost(t *testing.T) {
+	repo := database.NewMemoryRepository()
+	hub := NewHub(repo, auth.NewRoomTokens(repo, []byte("test-secret")))
+	client := &Client{hub: hub, send: make(chan []byte, 16), info: &ClientInfo{ID: "client", SessionKey: "room", PlayerID: "player"}}
+
+	if _, err := repo.CreateSession("room"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.handleOpenBankroll(models.WSMessage{Type: "bankroll", Amount: 100}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.handleOpenBankroll(models.WSMessage{Type: "bankroll", Amount: 500}); err == nil {
+		t.Error("opened a bankroll twice")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(id int64) {
+			defer wg.Done()
+			wager := &betting.Wager{ID: id, SessionKey: "room", PlayerID: "player", Result: &betting.SlipResult{Staked: 10, Returned: 20, Net: 10}}
+			hub.applyToBankroll(wager)
+		}(int64(i + 1))
+	}
+	wg.Wait()
+
+	bankroll, err := repo.GetBankroll("room", "player")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bankroll.StartingBalance != 100 || bankroll.Balance != 300 || bankroll.Spins != 20 {
+		t.Fatalf("unexpected bankroll after concurrent settlements %+v", bankroll)
+	}
+
+	bet := func(amount int64) error {
+		slip := fmt.Sprintf(`{"bets":[{"type":"red","amount":%d}]}`, amount)
+		_, err := client.handlePlaceBet(models.WSMessage{Type: "bet", Slip: []byte(slip)})
+		return err
+	}
+	if err := bet(200); err != nil {
+		t.Fatalf("slip within the balance: %v", err)
+	}
+	if err := bet(150); err == nil {
+		t.Error("accepted a slip larger than the balance left after the pending bets")
+	}
+	if err := bet(100); err != nil {
+		t.Errorf("slip using the rest of the balance: %v", err)
+	}
+}
+
+func TestPlayerIDComesFromTheToken(t *testing.T) {
+	repo := database.NewMemoryRepository()
+	tokens := auth.NewRoomTokens(repo, []byte("test-secret"))
+	hub := NewHub(repo, tokens)
+	go hub.Run()
+
+	if _, err := repo.CreateSessionWithPassword("room", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	alice, err := tokens.Issue("room", auth.RoomEditor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := tokens.Issue("room", auth.RoomEditor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if alice.PlayerID == "" || alice.PlayerID == bob.PlayerID {
+		t.Fatalf("player IDs %q and %q", alice.PlayerID, bob.PlayerID)
+	}
+
+	join := func(token, playerID string) (*Client, error) {
+		client := &Client{hub: hub, send: make(chan []byte, 16), info: &ClientInfo{ID: "client"}}
+		return client, client.handleJoinAndRegister(models.WSMessage{Type: "join", Key: "room", Token: token, PlayerID: playerID})
+	}
+
+	client, err := join(alice.Token, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.info.PlayerID != alice.PlayerID {
+		t.Errorf("joined as %q, want %q", client.info.PlayerID, alice.PlayerID)
+	}
+	if _, err := join(alice.Token, alice.PlayerID); err != nil {
+		t.Errorf("join repeating the player ID: %v", err)
+	}
+	if _, err := join(bob.Token, alice.PlayerID); err == nil {
+		t.Error("joined with another player's ID")
+	}
+}
+
+func TestViewersCannotChangeTheRoom(t *testing.T) {
+	repo := database.NewMemoryRepository()
+	tokens := auth.NewRoomTokens(repo, []byte("test-secret"))
+	hub := NewHub(repo, tokens)
+	go hub.Run()
+
+	if _, err := repo.CreateSessionWithPassword("room", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	join := func(role auth.RoomRole) *Client {
+		pair, err := tokens.Issue("room", role)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client := &Client{hub: hub, send: make(chan []byte, 16), info: &ClientInfo{ID: string(role)}}
+		if err := client.handleJoinAndRegister(models.WSMessage{Type: "join", Key: "room", Token: pair.Token}); err != nil {
+			t.Fatalf("%s join: %v", role, err)
+		}
+		return client
+	}
+	number := models.RouletteNumber(7)
+
+	viewer := join(auth.RoomViewer)
+	messages := []models.WSMessage{
+		{Type: "add", Number: &number},
+		{Type: "remove", Index: 0},
+		{Type: "bankroll", Amount: 100},
+		{Type: "spinner", Spinner: &models.SpinnerControl{Action: models.SpinnerStart}},
+	}
+	for _, message := range messages {
+		if _, err := viewer.handleMessage(message); !errors.Is(err, errReadOnly) {
+			t.Errorf("viewer %s: got %v, want errReadOnly", message.Type, err)
+		}
+	}
+	if _, err := viewer.handleMessage(models.WSMessage{Type: "resync"}); err != nil {
+		t.Errorf("viewer resync: %v", err)
+	}
+
+	editor := join(auth.RoomEditor)
+	if _, err := editor.handleMessage(models.WSMessage{Type: "add", Number: &number}); err != nil {
+		t.Fatalf("editor add: %v", err)
+	}
+	session, err := repo.GetSession("room")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(session.History) != 1 {
+		t.Errorf("history has %d numbers, want 1", len(session.History))
+	}
+}
+
+func TestRevokedClientsAreDisconnected(t *testing.T) {
+	repo := database.NewMemoryRepository()
+	tokens := auth.NewRoomTokens(repo, []byte("test-secret"))
+	hub := NewHub(repo, tokens)
+	go hub.Run()
+	tokens.AddListener(hub)
+
+	if _, err := repo.CreateSessionWithPassword("room", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	join := func(id, token string) (*Client, error) {
+		client := &Client{hub: hub, send: make(chan []byte, 16), info: &ClientInfo{ID: id}, kick: make(chan []byte, 1)}
+		return client, client.handleJoinAndRegister(models.WSMessage{Type: "join", Key: "room", Token: token})
+	}
+	issue := func() string {
+		pair, err := tokens.Issue("room", auth.RoomEditor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pair.Token
+	}
+	// Run has handled every earlier revocation once it takes this one
+	flush := func() { hub.TokensRevoked(&auth.Revocation{SessionKey: "other"}) }
+	kicked := func(c *Client) bool {
+		select {
+		case <-c.kick:
+			return true
+		default:
+			return false
+		}
+	}
+
+	firstToken := issue()
+	first, err := join("first", firstToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := join("second", issue())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := tokens.RevokeGrant("room", first.claims.GrantID); err != nil {
+		t.Fatal(err)
+	}
+	flush()
+	if !kicked(first) {
+		t.Error("client with a revoked token was not disconnected")
+	}
+	if kicked(second) {
+		t.Error("client with another token was disconnected")
+	}
+	if _, err := join("first", firstToken); !errors.Is(err, errAuthRequired) {
+		t.Errorf("join with a revoked token: got %v, want errAuthRequired", err)
+	}
+
+	if err := tokens.RevokeRoom("room"); err != nil {
+		t.Fatal(err)
+	}
+	flush()
+	if !kicked(second) {
+		t.Error("client was not disconnected when the room was revoked")
+	}
+	if _, err := join("third", issue()); err != nil {
+		t.Errorf("join with a token issued after the revocation: %v", err)
+	}
+}
@@ -0,0 +1,298 @@
+package websocket
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"casino-backend/internal/models"
+	"casino-backend/internal/provablyfair"
+	"casino-backend/internal/wheel"
+)
+
+// Bounds of the time between two spins of the virtual spinner
+const (
+	DefaultSpinInterval = 30 * time.Second
+	MinSpinInterval     = time.Second
+	MaxSpinInterval     = time.Hour
+)
+
+// SpinnerState is sent to the room whenever its spinner changes
+type SpinnerState struct {
+	Running   bool             `json:"running"`
+	Paused    bool             `json:"paused"`
+	Interval  int              `json:"interval,omitempty"` // Milliseconds between spins
+	WheelType models.WheelType `json:"wheelType,omitempty"`
+	Seed      *int64           `json:"seed,omitempty"`
+	Fair      bool             `json:"fair,omitempty"`
+	Spins     int              `json:"spins"` // Spins produced since the spinner started
+	NextSpin  *time.Time       `json:"nextSpin,omitempty"`
+
+	// Seed the spins are derived from, published when a fair spinner starts
+	Commitment *provablyfair.Seed `json:"commitment,omitempty"`
+}
+
+// spinner produces the spins of a practice room on a timer. Every spin goes
+// through the repository and the hub like a number added by a dealer.
+type spinner struct {
+	hub *Hub
+	key string
+
+	mu        sync.Mutex
+	interval  time.Duration
+	wheelType models.WheelType
+	seed      *int64
+	fair      bool
+	source    wheel.Source
+	paused    bool
+	stopped   bool
+	spins     int
+	next      time.Time
+	timer     *time.Timer
+
+	// generation is bumped whenever the timer is armed or disarmed, so a
+	// tick that fired for an older timer never schedules another one
+	generation int
+}
+
+func newSpinner(hub *Hub, key string, wheelType models.WheelType, interval time.Duration, seed *int64, fair bool) *spinner {
+	source := wheel.CryptoSource()
+	if seed != nil {
+		source = wheel.SeededSource(*seed)
+	}
+	return &spinner{
+		hub:       hub,
+		key:       key,
+		interval:  interval,
+		wheelType: wheelType,
+		seed:      seed,
+		fair:      fair,
+		source:    source,
+	}
+}
+
+// start schedules the first spin
+func (s *spinner) start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.schedule()
+}
+
+// schedule arms the timer for the next spin; the caller must hold the lock
+func (s *spinner) schedule() {
+	s.generation++
+	generation := s.generation
+	s.next = time.Now().Add(s.interval)
+	s.timer = time.AfterFunc(s.interval, func() { s.tick(generation) })
+}
+
+// disarm stops the timer and invalidates a tick already in flight; the
+// caller must hold the lock
+func (s *spinner) disarm() {
+	s.generation++
+	s.timer.Stop()
+}
+
+func (s *spinner) tick(generation int) {
+	if err := s.spin(); err != nil {
+		log.Printf("[SPINNER] Spin of session %s failed: %v", s.key, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// A pause and resume while spinning has already armed a newer timer
+	if generation == s.generation && !s.paused && !s.stopped {
+		s.schedule()
+	}
+}
+
+// spin draws a number and adds it to the room unless the spinner is paused or stopped
+func (s *spinner) spin() error {
+	s.mu.Lock()
+	if s.paused || s.stopped {
+		s.mu.Unlock()
+		return nil
+	}
+	var (
+		number models.RouletteNumber
+		err    error
+	)
+	if !s.fair {
+		number, err = wheel.Spin(s.wheelType, s.source)
+	}
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	var session *models.RouletteSession
+	if s.fair {
+		session, number, err = s.hub.fairSpin(s.key, s.wheelType)
+	} else if session, err = s.hub.repo.AddNumberToSession(s.key, number); err != nil {
+		err = fmt.Errorf("failed to add number: %w", err)
+	}
+	if err != nil {
+		return err
+	}
+	s.hub.NumberAdded(session, number)
+
+	s.mu.Lock()
+	s.spins++
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *spinner) pause() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.paused {
+		return
+	}
+	s.paused = true
+	s.disarm()
+}
+
+func (s *spinner) resume() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.paused {
+		return
+	}
+	s.paused = false
+	s.schedule()
+}
+
+func (s *spinner) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopped = true
+	s.disarm()
+}
+
+func (s *spinner) state() *SpinnerState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := &SpinnerState{
+		Running:   !s.stopped,
+		Paused:    s.paused,
+		Interval:  int(s.interval / time.Millisecond),
+		WheelType: s.wheelType,
+		Seed:      s.seed,
+		Fair:      s.fair,
+		Spins:     s.spins,
+	}
+	if !s.paused && !s.stopped {
+		next := s.next
+		state.NextSpin = &next
+	}
+	return state
+}
+
+// controlSpinner applies a control message to the spinner of a session.
+// Starting a running spinner restarts it with the new settings, keeping the
+// interval when none is given.
+func (h *Hub) controlSpinner(key string, control models.SpinnerControl) (*SpinnerState, error) {
+	interval := time.Duration(control.Interval) * time.Millisecond
+	if control.Interval != 0 && (interval < MinSpinInterval || interval > MaxSpinInterval) {
+		return nil, fmt.Errorf("interval must be between %d and %d ms", MinSpinInterval.Milliseconds(), MaxSpinInterval.Milliseconds())
+	}
+
+	h.mu.Lock()
+	current := h.spinners[key]
+	h.mu.Unlock()
+
+	switch control.Action {
+	case models.SpinnerStart:
+		return h.startSpinner(key, control, interval, current)
+	case models.SpinnerPause, models.SpinnerResume:
+		if current == nil {
+			return nil, fmt.Errorf("spinner is not running")
+		}
+		if control.Action == models.SpinnerPause {
+			current.pause()
+		} else {
+			current.resume()
+		}
+		return current.state(), nil
+	case models.SpinnerStop:
+		h.StopSpinner(key)
+		return &SpinnerState{}, nil
+	case models.SpinnerStatus:
+		if current == nil {
+			return &SpinnerState{}, nil
+		}
+		return current.state(), nil
+	default:
+		return nil, fmt.Errorf("unknown spinner action: %s", control.Action)
+	}
+}
+
+func (h *Hub) startSpinner(key string, control models.SpinnerControl, interval time.Duration, current *spinner) (*SpinnerState, error) {
+	if control.Fair && control.Seed != nil {
+		return nil, fmt.Errorf("fair spins cannot use a seed")
+	}
+	if interval == 0 {
+		interval = DefaultSpinInterval
+		if current != nil {
+			interval = current.interval
+		}
+	}
+
+	session, err := h.repo.GetSession(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	if session == nil {
+		return nil, fmt.Errorf("session %s not found", key)
+	}
+	if !session.Practice {
+		return nil, fmt.Errorf("spinner is only available in practice rooms")
+	}
+
+	wheelType := session.WheelType.OrDefault()
+	if control.WheelType != "" && control.WheelType != wheelType {
+		if session, err = h.repo.SetSessionWheelType(key, control.WheelType); err != nil {
+			return nil, err
+		}
+		wheelType = session.WheelType
+		h.HistoryReplaced(session)
+	}
+
+	var commitment *provablyfair.Seed
+	if control.Fair {
+		// Publish the commitment before the first spin is drawn from it
+		if commitment, err = h.fairCommitment(key, control.ClientSeed); err != nil {
+			return nil, err
+		}
+	}
+
+	next := newSpinner(h, key, wheelType, interval, control.Seed, control.Fair)
+	next.start()
+	h.mu.Lock()
+	previous := h.spinners[key]
+	h.spinners[key] = next
+	h.mu.Unlock()
+	if previous != nil {
+		previous.stop()
+	}
+
+	log.Printf("[SPINNER] Session %s spins every %v on a %s wheel", key, interval, wheelType)
+	state := next.state()
+	state.Commitment = commitment
+	return state, nil
+}
+
+// StopSpinner stops the virtual spinner of a session, if any
+func (h *Hub) StopSpinner(key string) {
+	h.mu.Lock()
+	current := h.spinners[key]
+	delete(h.spinners, key)
+	h.mu.Unlock()
+
+	if current != nil {
+		current.stop()
+		log.Printf("[SPINNER] Session %s stopped", key)
+	}
+}
@@ -0,0 +1,185 @@
+package websocket
+
+import (
+	"errors"
+	"testing"
+
+	"casino-backend/internal/auth"
+	"casino-backend/internal/database"
+	"casino-backend/internal/models"
+	"casino-backend/internal/provablyfair"
+	"casino-backend/internal/wheel"
+)
+
+func TestSpinnerAddsSeededSpins(t *testing.T) {
+	repo := database.NewMemoryRepository()
+	hub := NewHub(repo, auth.NewRoomTokens(repo, []byte("test-secret")))
+	go hub.Run()
+
+	if _, _, err := repo.CreateSessionFromRequest(models.CreateSessionRequest{Key: "practice", Practice: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	seed := int64(7)
+	state, err := hub.controlSpinner("practice", models.SpinnerControl{
+		Action:    models.SpinnerStart,
+		Interval:  60000,
+		WheelType: models.WheelAmerican,
+		Seed:      &seed,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hub.StopSpinner("practice")
+	if !state.Running || state.WheelType != models.WheelAmerican || state.Interval != 60000 {
+		t.Errorf("unexpected state %+v", state)
+	}
+
+	// Spin by hand instead of waiting for the timer
+	current := hub.spinners["practice"]
+	for i := 0; i < 3; i++ {
+		if err := current.spin(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	session, err := repo.GetSession("practice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := wheel.SeededSource(seed)
+	for i, n := range session.History {
+		want, _ := wheel.Spin(models.WheelAmerican, source)
+		if n != want {
+			t.Errorf("spin %d = %s, want %s", i, n, want)
+		}
+	}
+	if len(session.History) != 3 || session.WheelType != models.WheelAmerican {
+		t.Fatalf("unexpected session %+v", session)
+	}
+
+	// A paused spinner does not spin
+	if _, err := hub.controlSpinner("practice", models.SpinnerControl{Action: models.SpinnerPause}); err != nil {
+		t.Fatal(err)
+	}
+	if err := current.spin(); err != nil {
+		t.Fatal(err)
+	}
+	if state := current.state(); !state.Paused || state.Spins != 3 {
+		t.Errorf("unexpected state after pause %+v", state)
+	}
+
+	// The wheel is fixed once the room has history
+	_, err = hub.controlSpinner("practice", models.SpinnerControl{Action: models.SpinnerStart, WheelType: models.WheelEuropean})
+	if !errors.Is(err, database.ErrWheelTypeLocked) {
+		t.Errorf("expected ErrWheelTypeLocked, got %v", err)
+	}
+
+	if _, err := hub.controlSpinner("practice", models.SpinnerControl{Action: models.SpinnerStart, Interval: 10}); err == nil {
+		t.Error("expected an error for an interval below the minimum")
+	}
+
+	// A tick of a timer armed before a pause and resume does not arm another one
+	stale := current.generation
+	if _, err := hub.controlSpinner("practice", models.SpinnerControl{Action: models.SpinnerResume}); err != nil {
+		t.Fatal(err)
+	}
+	armed := current.generation
+	current.tick(stale)
+	if current.generation != armed {
+		t.Error("a stale tick rescheduled the spinner")
+	}
+
+	// Only practice rooms have a spinner
+	if _, err := repo.CreateSession("regular"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := hub.controlSpinner("regular", models.SpinnerControl{Action: models.SpinnerStart}); err == nil {
+		t.Error("expected an error for a spinner in a regular room")
+	}
+}
+
+func TestFairSpinnerRecordsSpins(t *testing.T) {
+	repo := database.NewMemoryRepository()
+	hub := NewHub(repo, auth.NewRoomTokens(repo, []byte("test-secret")))
+	go hub.Run()
+
+	if _, _, err := repo.CreateSessionFromRequest(models.CreateSessionRequest{Key: "fair", Practice: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := hub.controlSpinner("fair", models.SpinnerControl{
+		Action:     models.SpinnerStart,
+		Interval:   60000,
+		Fair:       true,
+		ClientSeed: "player-seed",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hub.StopSpinner("fair")
+	commitment := state.Commitment
+	if !state.Fair || commitment == nil || commitment.ServerSeed != "" || commitment.ClientSeed != "player-seed" {
+		t.Fatalf("expected a hidden commitment, got %+v", state)
+	}
+
+	current := hub.spinners["fair"]
+	for i := 0; i < 3; i++ {
+		if err := current.spin(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	change, err := hub.RotateFairSeed("fair", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	revealed := change.Revealed
+	if revealed == nil || revealed.ID != commitment.ID || revealed.Nonce != 3 {
+		t.Fatalf("unexpected revealed seed %+v", revealed)
+	}
+	if provablyfair.HashSeed(revealed.ServerSeed) != commitment.ServerSeedHash {
+		t.Error("revealed server seed does not match the commitment")
+	}
+
+	session, err := repo.GetSession("fair")
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := repo.GetFairRecords("fair")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 || len(session.History) != 3 {
+		t.Fatalf("expected 3 recorded spins, got %d records and %d spins", len(records), len(session.History))
+	}
+	for i, record := range records {
+		want, err := provablyfair.Spin(session.WheelType, revealed.ServerSeed, revealed.ClientSeed, record.Nonce)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if record.Nonce != i || record.SpinIndex != i || record.Number != want || session.History[i] != want {
+			t.Errorf("record %d = %+v, want nonce %d and number %s", i, record, i, want)
+		}
+	}
+
+	// The next spin comes from the new seed
+	if err := current.spin(); err != nil {
+		t.Fatal(err)
+	}
+	if records, _ = repo.GetFairRecords("fair"); records[3].SeedID != change.Active.ID || records[3].Nonce != 0 {
+		t.Errorf("unexpected record after rotation %+v", records[3])
+	}
+
+	// A spin that cannot claim its nonce never reaches the history
+	if _, err := repo.AddFairSpin(&provablyfair.Record{SeedID: change.Active.ID, SessionKey: "fair", Nonce: 0, Number: 5}); err == nil {
+		t.Error("expected an error for a used nonce")
+	}
+	if session, _ = repo.GetSession("fair"); len(session.History) != 4 {
+		t.Errorf("expected 4 spins, got %d", len(session.History))
+	}
+
+	if _, err := hub.controlSpinner("fair", models.SpinnerControl{Action: models.SpinnerStart, Fair: true, Seed: new(int64)}); err == nil {
+		t.Error("expected an error for a fair spinner with a seed")
+	}
+}
@@ -40,7 +40,7 @@ interface Connection {
 
 interface Session {
   key: string;
-  password?: string;
+  protected?: boolean;
   createdAt: string;
   lastActivity: string;
   historyLength: number;
@@ -58,7 +58,7 @@ interface AdminStats {
 }
 
 export default function AdminPage() {
-  const { isAuthenticated, isLoading: authLoading, error: authError, authenticate, logout } = useAdminAuth();
+  const { isAuthenticated, isLoading: authLoading, error: authError, token, authenticate, logout } = useAdminAuth();
 
   const [sessions, setSessions] = useState<Session[]>([]);
   const [stats, setStats] = useState<AdminStats>({
@@ -74,12 +74,19 @@ export default function AdminPage() {
   const [sessionHistory, setSessionHistory] = useState<number[]>([]);
   const [expandedSessions, setExpandedSessions] = useState<Set<string>>(new Set());
 
+  const authHeaders: HeadersInit = token ? { Authorization: `Bearer ${token}` } : {};
+
   const fetchSessions = async () => {
     try {
       setLoading(true);
 
       // Получаем данные с реального API
-              const response = await fetch(`${process.env.NEXT_PUBLIC_API_URL || '/api'}/admin/sessions`);
+              const response = await fetch(`${process.env.NEXT_PUBLIC_API_URL || '/api'}/admin/sessions`, { headers: authHeaders });
+      if (response.status === 401) {
+        // Токен истек - нужно войти заново
+        logout();
+        return;
+      }
       if (!response.ok) {
         throw new Error('Failed to fetch sessions');
       }
@@ -88,7 +95,7 @@ export default function AdminPage() {
       setSessions(sessions);
 
       // Получаем статистику
-              const statsResponse = await fetch(`${process.env.NEXT_PUBLIC_API_URL || '/api'}/admin/stats`);
+              const statsResponse = await fetch(`${process.env.NEXT_PUBLIC_API_URL || '/api'}/admin/stats`, { headers: authHeaders });
       if (statsResponse.ok) {
         const stats = await statsResponse.json();
         setStats(stats);
@@ -116,16 +123,19 @@ export default function AdminPage() {
   };
 
   useEffect(() => {
+    if (!token) {
+      return;
+    }
     fetchSessions();
     const interval = setInterval(fetchSessions, 30000);
     return () => clearInterval(interval);
-  }, []);
+  }, [token]);
 
   const handleViewHistory = async (session: Session) => {
     setSelectedSession(session);
 
     try {
-      const response = await fetch(`${process.env.NEXT_PUBLIC_API_URL || '/api'}/admin/sessions/${session.key}/history`);
+      const response = await fetch(`${process.env.NEXT_PUBLIC_API_URL || '/api'}/admin/sessions/${session.key}/history`, { headers: authHeaders });
       if (response.ok) {
         const history = await response.json();
         setSessionHistory(history);
@@ -337,8 +347,8 @@ export default function AdminPage() {
                         <Typography variant="body2" sx={{ fontFamily: 'monospace' }}>
                           {session.key}
                         </Typography>
-                        {session.password && (
-                          <Chip label={`Пароль: ${session.password}`} size="small" variant="outlined" color="warning" />
+                        {session.protected && (
+                          <Chip label="С паролем" size="small" variant="outlined" color="warning" />
                         )}
                       </Box>
                     </TableCell>
@@ -46,9 +46,11 @@ export default function Home() {
         throw new Error((await res.json()).error || 'Не удалось создать комнату');
       }
 
-      const { token } = await res.json();
+      const { data } = await res.json();
       if (typeof window !== 'undefined') {
-        window.sessionStorage.setItem(`token_${roomKey}`, token);
+        // Там же, где их ищет страница комнаты
+        window.localStorage.setItem(`token_${roomKey}`, data.token);
+        window.localStorage.setItem(`refresh_${roomKey}`, data.refreshToken);
       }
 
       setCreateRoomOpen(false);
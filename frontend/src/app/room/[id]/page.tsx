@@ -24,6 +24,15 @@ import { RouletteTrendsChart } from '@/components/casino/components/rouletteTren
 import { DraggableDashboard } from '@/components/casino/components/dashboard';
 import { DistributionCharts } from '@/components/casino/components/DistributionCharts';
 
+// storeRoomTokens сохраняет access- и refresh-токен комнаты
+const storeRoomTokens = (roomKey: string, token: string, refreshToken?: string) => {
+  if (typeof window === 'undefined') return;
+  window.localStorage.setItem(`token_${roomKey}`, token);
+  if (refreshToken) {
+    window.localStorage.setItem(`refresh_${roomKey}`, refreshToken);
+  }
+};
+
 const RoomPageContent: React.FC = () => {
   const params = useParams();
   const router = useRouter();
@@ -48,6 +57,7 @@ const RoomPageContent: React.FC = () => {
     isConnected,
     isReconnecting,
     reconnectAttempts,
+    needsAuth,
     forceReconnect,
   } = useRouletteWebSocket(key, sessionToken);
 
@@ -92,11 +102,9 @@ const RoomPageContent: React.FC = () => {
         throw new Error(errorData.error || 'Ошибка аутентификации');
       }
 
-      const { token } = await res.json();
-      if (typeof window !== 'undefined') {
-        window.localStorage.setItem(`token_${key}`, token);
-      }
-      setSessionToken(token);
+      const { data } = await res.json();
+      storeRoomTokens(key, data.token, data.refreshToken);
+      setSessionToken(data.token);
       setShowPasswordDialog(false);
       forceReconnect();
       return true;
@@ -114,6 +122,40 @@ const RoomPageContent: React.FC = () => {
     }
   }, [key, sessionToken, authenticate]);
 
+  // Access-токен живет 15 минут: при отказе сервера продлеваем его refresh-токеном,
+  // а если и он отозван, снова спрашиваем пароль
+  const refreshSession = useCallback(async () => {
+    if (!key) return;
+    const refreshToken = window.localStorage.getItem(`refresh_${key}`);
+    if (refreshToken) {
+      try {
+        const res = await fetch(`${process.env.NEXT_PUBLIC_API_URL || '/api'}/rooms/${key}/refresh`, {
+          method: 'POST',
+          headers: { 'Content-Type': 'application/json' },
+          body: JSON.stringify({ refreshToken }),
+        });
+        if (res.ok) {
+          const { data } = await res.json();
+          storeRoomTokens(key, data.token, data.refreshToken);
+          setSessionToken(data.token);
+          forceReconnect();
+          return;
+        }
+      } catch (error) {
+        console.error('❌ Не удалось продлить токен:', error);
+      }
+    }
+    window.localStorage.removeItem(`token_${key}`);
+    window.localStorage.removeItem(`refresh_${key}`);
+    setSessionToken(undefined);
+  }, [key, forceReconnect]);
+
+  useEffect(() => {
+    if (needsAuth) {
+      refreshSession();
+    }
+  }, [needsAuth, refreshSession]);
+
   useEffect(() => {
     const handleResize = () => {
       setWindowWidth(window.innerWidth);
@@ -187,10 +229,8 @@ const RoomPageContent: React.FC = () => {
         throw new Error((await res.json()).error || 'Не удалось создать комнату');
       }
 
-      const { token } = await res.json();
-      if (typeof window !== 'undefined') {
-        window.localStorage.setItem(`token_${roomKey}`, token);
-      }
+      const { data } = await res.json();
+      storeRoomTokens(roomKey, data.token, data.refreshToken);
 
       setShowCreateRoomDialog(false);
       router.push(`/room/${roomKey}`);
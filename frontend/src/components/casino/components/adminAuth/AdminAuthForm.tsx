@@ -3,19 +3,20 @@ import { Alert, Box, Button, Container, IconButton, InputAdornment, Paper, TextF
 import { Lock, Visibility, VisibilityOff } from '@mui/icons-material';
 
 interface AdminAuthFormProps {
-  onAuth: (password: string) => void;
+  onAuth: (username: string, password: string) => void;
   isLoading?: boolean;
   error?: string | null;
 }
 
 export const AdminAuthForm = ({ onAuth, isLoading = false, error }: AdminAuthFormProps) => {
+  const [username, setUsername] = useState('');
   const [password, setPassword] = useState('');
   const [showPassword, setShowPassword] = useState(false);
 
   function handleSubmit(e: React.FormEvent) {
     e.preventDefault();
-    if (password.trim()) {
-      onAuth(password.trim());
+    if (username.trim() && password) {
+      onAuth(username.trim(), password);
     }
   }
 
@@ -51,7 +52,7 @@ export const AdminAuthForm = ({ onAuth, isLoading = false, error }: AdminAuthFor
               Админ-панель
             </Typography>
             <Typography variant="body2" color="text.secondary">
-              Введите пароль для доступа к панели администратора
+              Войдите в учетную запись администратора
             </Typography>
           </Box>
 
@@ -62,6 +63,26 @@ export const AdminAuthForm = ({ onAuth, isLoading = false, error }: AdminAuthFor
           )}
 
           <form onSubmit={handleSubmit}>
+            <TextField
+              fullWidth
+              label="Имя пользователя"
+              value={username}
+              onChange={(e) => setUsername(e.target.value)}
+              disabled={isLoading}
+              autoComplete="username"
+              sx={{
+                mb: 2,
+                '& .MuiInputLabel-root': { color: '#999' },
+                '& .MuiOutlinedInput-root': {
+                  color: 'white',
+                  '& fieldset': { borderColor: '#333' },
+                  '&:hover fieldset': { borderColor: '#555' },
+                  '&.Mui-focused fieldset': { borderColor: 'primary.main' }
+                }
+              }}
+              autoFocus
+            />
+
             <TextField
               fullWidth
               type={showPassword ? 'text' : 'password'}
@@ -92,7 +113,7 @@ export const AdminAuthForm = ({ onAuth, isLoading = false, error }: AdminAuthFor
                   </InputAdornment>
                 )
               }}
-              autoFocus
+              autoComplete="current-password"
             />
 
             <Button
@@ -100,7 +121,7 @@ export const AdminAuthForm = ({ onAuth, isLoading = false, error }: AdminAuthFor
               fullWidth
               variant="contained"
               size="large"
-              disabled={isLoading || !password.trim()}
+              disabled={isLoading || !username.trim() || !password}
               sx={{
                 py: 1.5,
                 fontSize: '1.1rem',
@@ -1,41 +1,41 @@
 import { useEffect, useState } from 'react';
 
-const ADMIN_PASSWORD = 'admin123'; // В реальном приложении это должно быть в переменных окружения
 const AUTH_STORAGE_KEY = 'casino_admin_auth';
-const AUTH_EXPIRY_HOURS = 24;
+const API_URL = process.env.NEXT_PUBLIC_API_URL || '/api';
 
 interface AuthState {
   isAuthenticated: boolean;
   isLoading: boolean;
   error: string | null;
+  token: string | null;
 }
 
 export function useAdminAuth() {
   const [authState, setAuthState] = useState<AuthState>({
     isAuthenticated: false,
     isLoading: true,
-    error: null
+    error: null,
+    token: null
   });
 
-  // Проверяем сохраненную авторизацию при загрузке
+  // Проверяем сохраненный токен при загрузке
   useEffect(() => {
     const checkSavedAuth = () => {
       try {
         const savedAuth = localStorage.getItem(AUTH_STORAGE_KEY);
         if (savedAuth) {
-          const { timestamp, authenticated } = JSON.parse(savedAuth);
-          const now = Date.now();
-          const expiryTime = timestamp + (AUTH_EXPIRY_HOURS * 60 * 60 * 1000);
+          const { token, expiresAt } = JSON.parse(savedAuth);
 
-          if (authenticated && now < expiryTime) {
+          if (token && Date.now() < new Date(expiresAt).getTime()) {
             setAuthState({
               isAuthenticated: true,
               isLoading: false,
-              error: null
+              error: null,
+              token
             });
             return;
           } else {
-            // Авторизация истекла
+            // Токен истек
             localStorage.removeItem(AUTH_STORAGE_KEY);
           }
         }
@@ -47,41 +47,54 @@ export function useAdminAuth() {
       setAuthState({
         isAuthenticated: false,
         isLoading: false,
-        error: null
+        error: null,
+        token: null
       });
     };
 
     checkSavedAuth();
   }, []);
 
-  const authenticate = async (password: string) => {
+  const authenticate = async (username: string, password: string) => {
     setAuthState(prev => ({
       ...prev,
       isLoading: true,
       error: null
     }));
 
-    // Имитируем задержку для проверки пароля
-    await new Promise(resolve => setTimeout(resolve, 1000));
+    try {
+      const response = await fetch(`${API_URL}/admin/login`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ username, password })
+      });
 
-    if (password === ADMIN_PASSWORD) {
-      const authData = {
-        authenticated: true,
-        timestamp: Date.now()
-      };
+      if (!response.ok) {
+        setAuthState({
+          isAuthenticated: false,
+          isLoading: false,
+          error: response.status === 401 ? 'Неверное имя или пароль' : 'Ошибка сервера',
+          token: null
+        });
+        return;
+      }
 
-      localStorage.setItem(AUTH_STORAGE_KEY, JSON.stringify(authData));
+      const { data } = await response.json();
+      localStorage.setItem(AUTH_STORAGE_KEY, JSON.stringify({ token: data.token, expiresAt: data.expiresAt }));
 
       setAuthState({
         isAuthenticated: true,
         isLoading: false,
-        error: null
+        error: null,
+        token: data.token
       });
-    } else {
+    } catch (error) {
+      console.error('Admin login failed:', error);
       setAuthState({
         isAuthenticated: false,
         isLoading: false,
-        error: 'Неверный пароль'
+        error: 'Сервер недоступен',
+        token: null
       });
     }
   };
@@ -91,7 +104,8 @@ export function useAdminAuth() {
     setAuthState({
       isAuthenticated: false,
       isLoading: false,
-      error: null
+      error: null,
+      token: null
     });
   };
 
@@ -108,4 +122,4 @@ export function useAdminAuth() {
     logout,
     clearError
   };
-} 
\ No newline at end of file
+}
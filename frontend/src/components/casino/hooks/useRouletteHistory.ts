@@ -17,6 +17,9 @@ export function useRouletteWebSocket(key: string | undefined, token?: string) {
   const shouldReconnectRef = useRef(true);
   const tokenRef = useRef(token);
   const reconnectAttemptsRef = useRef(0);
+  // Версия и ревизия истории, подтверждённые сервером: по ним при переподключении приходит только дельта
+  const versionRef = useRef(0);
+  const revisionRef = useRef(0);
 
   const connectWebSocketFn = useRef<(() => void) | null>(null);
 
@@ -72,7 +75,18 @@ export function useRouletteWebSocket(key: string | undefined, token?: string) {
         type: 'join',
         key,
         token: tokenRef.current,
-        version: 0, // Убираем зависимость от history.length
+        version: versionRef.current,
+        revision: revisionRef.current,
+      }));
+    };
+
+    const resync = () => {
+      ws.send(JSON.stringify({
+        type: 'resync',
+        key,
+        token: tokenRef.current,
+        version: versionRef.current,
+        revision: revisionRef.current,
       }));
     };
 
@@ -81,12 +95,33 @@ export function useRouletteWebSocket(key: string | undefined, token?: string) {
         const data = JSON.parse(event.data);
         if (data.key && data.key !== key) return;
 
+        // Нулевые version, revision и index сервер не присылает
+        const version: number = data.version ?? 0;
+        const revision: number = data.revision ?? 0;
+
         if (data.type === 'sync' && Array.isArray(data.history)) {
-          setHistory(data.history);
+          const confirmed = versionRef.current;
+          versionRef.current = version;
+          revisionRef.current = revision;
+          if (data.full) {
+            setHistory(data.history);
+          } else {
+            setHistory(prev => [...prev.slice(0, confirmed), ...data.history]);
+          }
         } else if (data.type === 'add' && data.number !== undefined) {
-          setHistory(prev => (prev[prev.length - 1] === data.number ? prev : [...prev, data.number]));
+          // Пропущено сообщение или история изменилась — запрашиваем недостающее
+          if (revision !== revisionRef.current || version !== versionRef.current + 1) {
+            resync();
+            return;
+          }
+          versionRef.current = version;
+          // Своё число уже добавлено оптимистично
+          setHistory(prev => (prev.length >= version ? prev : [...prev, data.number]));
         } else if (data.type === 'remove') {
-          setHistory(prev => prev.filter((_, i) => i !== data.index));
+          versionRef.current = version;
+          revisionRef.current = revision;
+          const index: number = data.index ?? 0;
+          setHistory(prev => (prev.length === version ? prev : prev.filter((_, i) => i !== index)));
         } else if (data.type === 'authRequired') {
           setNeedsAuth(true);
           setAuthError(data.error || 'Требуется токен');
@@ -124,6 +159,8 @@ export function useRouletteWebSocket(key: string | undefined, token?: string) {
     if (!key) return;
 
     shouldReconnectRef.current = true;
+    versionRef.current = 0;
+    revisionRef.current = 0;
     
     // Создаем соединение только если его еще нет
     if (!wsRef.current || wsRef.current.readyState === WebSocket.CLOSED) {
@@ -20,11 +20,11 @@ async function request<T>(endpoint: string, options: RequestInit = {}): Promise<
   const url = `${API_BASE_URL}${endpoint}`;
 
   const config: RequestInit = {
+    ...options,
     headers: {
       'Content-Type': 'application/json',
       ...options.headers,
     },
-    ...options,
   };
 
   const response = await fetch(url, config);
@@ -36,15 +36,24 @@ async function request<T>(endpoint: string, options: RequestInit = {}): Promise<
   return await response.json();
 }
 
+// Токен комнаты нужен для чтения и изменения истории защищенных комнат
+function roomAuthHeaders(key: string): Record<string, string> {
+  const token = typeof window !== 'undefined' ? window.localStorage.getItem(`token_${key}`) : null;
+  return token ? { Authorization: `Bearer ${token}` } : {};
+}
+
 // API функции
 export async function getHistory(key: string): Promise<RouletteNumber[]> {
-  const response = await request<{ history: RouletteNumber[] }>(`/roulette/${key}`);
+  const response = await request<{ history: RouletteNumber[] }>(`/roulette/${key}`, {
+    headers: roomAuthHeaders(key),
+  });
   return response.history || [];
 }
 
 export async function saveNumber(key: string, number: RouletteNumber): Promise<RouletteSession> {
   const response = await request<ApiResponse<RouletteSession>>(`/roulette/save`, {
     method: 'POST',
+    headers: roomAuthHeaders(key),
     body: JSON.stringify({ key, number }),
   });
 
@@ -58,6 +67,7 @@ export async function saveNumber(key: string, number: RouletteNumber): Promise<R
 export async function updateHistory(key: string, history: RouletteNumber[]): Promise<RouletteSession> {
   const response = await request<ApiResponse<RouletteSession>>(`/roulette/${key}`, {
     method: 'PUT',
+    headers: roomAuthHeaders(key),
     body: JSON.stringify({ history }),
   });
 